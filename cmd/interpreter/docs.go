@@ -0,0 +1,39 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/codeis4fun/data-treatment-interpreter/internal/docs"
+	"github.com/codeis4fun/data-treatment-interpreter/internal/engine"
+)
+
+// docsCommand writes the transformer reference in Markdown or JSON
+func docsCommand(args []string) error {
+	flags := flag.NewFlagSet("docs", flag.ExitOnError)
+	format := flags.String("format", "markdown", "output format: markdown or json")
+	outputPath := flags.String("output", "", "file to write the reference to (defaults to stdout)")
+	flags.Parse(args)
+
+	var w io.Writer = os.Stdout
+	if *outputPath != "" {
+		f, err := os.Create(*outputPath)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+
+	signatures := engine.Reference()
+	switch *format {
+	case "markdown", "md":
+		return docs.Markdown(w, signatures)
+	case "json":
+		return docs.JSON(w, signatures)
+	default:
+		return fmt.Errorf("unknown format '%s' (expected markdown or json)", *format)
+	}
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/codeis4fun/data-treatment-interpreter/internal/engine"
+	"github.com/codeis4fun/data-treatment-interpreter/internal/lineage"
+)
+
+// lineageCommand exports the lineage of a script, statically or from an execution over an input
+func lineageCommand(args []string) error {
+	flags := flag.NewFlagSet("lineage", flag.ExitOnError)
+	scriptPath := flags.String("script", "", "path to the script (defaults to the built-in sample)")
+	inputPath := flags.String("input", "", "path to a JSON input; when given, the lineage is recorded while running the script")
+	format := flags.String("format", "json", "output format: json or dot")
+	field := flags.String("field", "", "print the derivation of a single field instead of the whole graph")
+	tempPrefix := flags.String("temp-prefix", "_", "prefix of the temporary variables deleted from the output (empty keeps every field)")
+	scriptParams := addParamFlags(flags)
+	flags.Parse(args)
+
+	programs, err := parseScript(*scriptPath)
+	if err != nil {
+		return err
+	}
+
+	var graph *lineage.Graph
+	if *inputPath == "" {
+		graph = lineage.FromPrograms(programs, *tempPrefix)
+	} else {
+		jsonData, err := os.ReadFile(*inputPath)
+		if err != nil {
+			return err
+		}
+		params, err := scriptParams.option()
+		if err != nil {
+			return err
+		}
+		graph = lineage.NewGraph(*tempPrefix)
+		if _, err := engine.NewEngine(engine.WithTracer(graph), engine.WithTempPrefix(*tempPrefix)).ExecuteAll(programs, jsonData, params); err != nil {
+			return err
+		}
+	}
+
+	if *field != "" {
+		fmt.Println(graph.Lineage(*field))
+		return nil
+	}
+
+	switch *format {
+	case "json":
+		return writeJSON(os.Stdout, graph)
+	case "dot":
+		return graph.DOT(os.Stdout)
+	default:
+		return fmt.Errorf("unknown format '%s' (expected json or dot)", *format)
+	}
+}
@@ -1,19 +1,25 @@
 package main
 
 import (
+	"context"
+	"encoding/json"
+	"flag"
 	"fmt"
-	"strings"
+	"io"
+	"os"
+	_ "time/tzdata" // Time zones for the date transformers, on systems without a zoneinfo database
 
+	"github.com/codeis4fun/data-treatment-interpreter/internal/diff"
 	"github.com/codeis4fun/data-treatment-interpreter/internal/engine"
-	"github.com/codeis4fun/data-treatment-interpreter/internal/lexer"
 	"github.com/codeis4fun/data-treatment-interpreter/internal/parser"
+	"github.com/codeis4fun/data-treatment-interpreter/internal/transformers"
 )
 
-func main() {
-	// Sample JSON data
-	// jsonData := []byte(`{"name":"vanessa","surname":"teixeira","height":1.72,"weight":60,"location":"São Paulo/sp","languages":["Portuguese","English"],"friends":[{"first":"Dale","last":"Murphy","age":44,"nets":["ig","fb","tw"]},{"first":"Roger","last":"Craig","age":68,"nets":["fb","tw"]},{"first":"Jane","last":"Murphy","age":47,"nets":["ig","tw"]}]}`)
-	jsonData := []byte(`{"firstName":"john","lastName":"doe","weight":75,"height":1.75,"favoriteFoods":["pizza","pasta","sushi"],"favoriteColors":["red","blue","green"],"place":"New York/USA","friends":[{"name":"Alice"},{"name":"Bob"}]}`)
-	input := `SET _tempName = concatenate(' ', firstName, lastName)
+// Sample JSON data and script used when no input or script is given
+const (
+	sampleJSON = `{"firstName":"john","lastName":"doe","weight":75,"height":1.75,"favoriteFoods":["pizza","pasta","sushi"],"favoriteColors":["red","blue","green"],"place":"New York/USA","friends":[{"name":"Alice"},{"name":"Bob"}]}`
+
+	sampleScript = `SET _tempName = concatenate(' ', firstName, lastName)
 SET fullName = uppercase(_tempName)
 SET bmi, isHealty = bmi(weight, height)
 SET favoriteFoods.0 = uppercase(favoriteFoods.0)
@@ -22,27 +28,214 @@ SET _city, _country = split(place, '/')
 SET address.city = uppercase(_city)
 SET address.country = uppercase(_country)
 SET friends.#.name = uppercase(friends.#.name)`
-	r := strings.NewReader(input)
-	// Initialize lexer and parser
-	l := lexer.NewLexer(r)
-	p := parser.NewParser(l, input)
+)
+
+func main() {
+	args := os.Args[1:]
+
+	// Dispatch to the requested command, running the script by default
+	command := "run"
+	if len(args) > 0 && len(args[0]) > 0 && args[0][0] != '-' {
+		command, args = args[0], args[1:]
+	}
+
+	var err error
+	switch command {
+	case "run":
+		err = run(args)
+	case "docs":
+		err = docsCommand(args)
+	case "repl":
+		err = replCommand(args)
+	case "lineage":
+		err = lineageCommand(args)
+	default:
+		err = fmt.Errorf("unknown command '%s' (expected run, docs, repl or lineage)", command)
+	}
+
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		os.Exit(1)
+	}
+}
+
+// run applies a script to a JSON document and prints the result
+func run(args []string) error {
+	flags := flag.NewFlagSet("run", flag.ExitOnError)
+	scriptPath := flags.String("script", "", "path to the script to run (defaults to the built-in sample)")
+	inputPath := flags.String("input", "", "path to the JSON input (defaults to the built-in sample)")
+	showDiff := flags.Bool("diff", false, "print a diff between the input and the output instead of the output")
+	dryRun := flags.Bool("dry-run", false, "print the changes made by each statement as JSON Patch instead of the output")
+	noColor := flags.Bool("no-color", false, "disable coloured output")
+	continueOnError := flags.Bool("continue-on-error", false, "skip failing statements and print the partially transformed document")
+	missing := flags.String("missing", "skip", "what statements do when an optional field (field?) is missing: skip or null")
+	timeout := flags.Duration("timeout", 0, "stop the script after this long, e.g. 500ms or 2s (0 means no limit)")
+	documentModel := flags.Bool("document-model", false, "parse the input once and update it in place (faster on large documents)")
+	tempPrefix := flags.String("temp-prefix", "_", "prefix of the temporary variables deleted from the output (empty keeps every field)")
+	growthReference := flags.String("growth-reference", "", "path to an LMS growth chart in the CDC CSV layout, which enables bmipercentile")
+	keyring := flags.String("keyring", "", "path to a JSON object of base64 AES keys by id, which enables encrypt and decrypt")
+	explain := flags.Bool("explain", false, "print a trace of every transformer application to stderr")
+	explainFormat := flags.String("explain-format", "text", "format of the trace: text or json")
+	scriptParams := addParamFlags(flags)
+	flags.Parse(args)
 
-	// Parse the input
-	programs, err := p.RunAll()
+	params, err := scriptParams.option()
 	if err != nil {
-		fmt.Println("Error:", err)
-		return
+		return err
+	}
+
+	jsonData, err := readOrDefault(*inputPath, sampleJSON)
+	if err != nil {
+		return err
+	}
+
+	// Parse the script
+	programs, err := parseScript(*scriptPath)
+	if err != nil {
+		return err
+	}
+
+	// Initialize engine, tracing every transformer application when asked to explain
+	var opts []engine.Option
+	if *continueOnError {
+		opts = append(opts, engine.WithErrorPolicy(engine.ContinueOnError))
+	}
+	if *documentModel {
+		opts = append(opts, engine.WithDocumentModel())
+	}
+	if *tempPrefix != "_" {
+		opts = append(opts, engine.WithTempPrefix(*tempPrefix))
+	}
+	switch *missing {
+	case "skip":
+	case "null":
+		opts = append(opts, engine.WithMissingPolicy(engine.NullMissing))
+	default:
+		return fmt.Errorf("unknown missing policy '%s' (expected skip or null)", *missing)
+	}
+	if *growthReference != "" {
+		reference, err := readGrowthReference(*growthReference)
+		if err != nil {
+			return err
+		}
+		opts = append(opts, engine.WithGrowthReference(reference))
+	}
+	if *keyring != "" {
+		keys, err := readKeyring(*keyring)
+		if err != nil {
+			return err
+		}
+		opts = append(opts, engine.WithKeyring(keys))
+	}
+	recorder := &engine.Recorder{}
+	if *explain {
+		switch *explainFormat {
+		case "text":
+			opts = append(opts, engine.WithTracer(engine.WriterTracer{W: os.Stderr}))
+		case "json":
+			opts = append(opts, engine.WithTracer(recorder))
+			defer func() { writeJSON(os.Stderr, recorder.Events()) }()
+		default:
+			return fmt.Errorf("unknown explain format '%s' (expected text or json)", *explainFormat)
+		}
 	}
+	e := engine.NewEngine(opts...)
 
-	// Initialize engine
-	e := engine.NewEngine()
+	ctx := context.Background()
+	if *timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, *timeout)
+		defer cancel()
+	}
+
+	if *dryRun {
+		// When continuing on errors the changes are still printed, with the skipped statements
+		changes, _, err := e.DryRunContext(ctx, programs, []byte(jsonData), params)
+		if err != nil && (!*continueOnError || ctx.Err() != nil) {
+			return err
+		}
+		if err := writeJSON(os.Stdout, changes); err != nil {
+			return err
+		}
+		return err
+	}
+
+	// Apply transformations to JSON. When continuing on errors the partial output is still printed,
+	// unless the script ran out of time
+	modifiedJSON, err := e.ExecuteAllContext(ctx, programs, []byte(jsonData), params)
+	if err != nil && (!*continueOnError || ctx.Err() != nil) {
+		return err
+	}
+
+	if *showDiff {
+		if err := diff.Compare([]byte(jsonData), modifiedJSON).Format(os.Stdout, !*noColor && isTerminal(os.Stdout)); err != nil {
+			return err
+		}
+	} else {
+		fmt.Println(string(modifiedJSON))
+	}
+
+	// Report the errors of skipped statements once the partial output is printed
+	return err
+}
+
+// writeJSON writes a value as indented JSON
+func writeJSON(w io.Writer, value any) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(value)
+}
+
+// isTerminal reports whether the file is attached to a terminal, where colours can be used
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// parseScript parses a script file, resolving its INCLUDE statements against its directory, or the built-in sample
+func parseScript(path string) ([]*parser.Program, error) {
+	if path == "" {
+		return parser.Parse(sampleScript)
+	}
+	return parser.ParseFile(path)
+}
+
+// readGrowthReference reads the growth chart used by bmipercentile
+func readGrowthReference(path string) (*transformers.GrowthReference, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	reference, err := transformers.ParseGrowthReference(file)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return reference, nil
+}
 
-	// Apply transformations to JSON
-	modifiedJSON, err := e.ExecuteAll(programs, jsonData)
+// readKeyring reads the keys used by encrypt and decrypt
+func readKeyring(path string) (*transformers.Keyring, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	keyring, err := transformers.ParseKeyring(file)
 	if err != nil {
-		fmt.Println("Error:", err)
-		return
+		return nil, fmt.Errorf("%s: %w", path, err)
 	}
+	return keyring, nil
+}
 
-	fmt.Println(string(modifiedJSON)) // Output: {"completeName":"johndoe","name":"john","surname":"doe"}
+// readOrDefault reads a file, or returns the fallback when no path is given
+func readOrDefault(path, fallback string) (string, error) {
+	if path == "" {
+		return fallback, nil
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return string(content), nil
 }
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/codeis4fun/data-treatment-interpreter/internal/engine"
+)
+
+// paramFlags collects repeated --param key=value flags
+type paramFlags []string
+
+func (p *paramFlags) String() string {
+	return strings.Join(*p, ",")
+}
+
+func (p *paramFlags) Set(value string) error {
+	if !strings.Contains(value, "=") {
+		return fmt.Errorf("expected key=value, got '%s'", value)
+	}
+	*p = append(*p, value)
+	return nil
+}
+
+// scriptParams holds the --param and --params flags shared by the commands that run scripts
+type scriptParams struct {
+	values paramFlags
+	file   *string
+}
+
+// addParamFlags registers --param and --params on a flag set
+func addParamFlags(flags *flag.FlagSet) *scriptParams {
+	p := &scriptParams{}
+	flags.Var(&p.values, "param", "script parameter as key=value, repeatable; values that are valid JSON (numbers, true, ...) keep their type")
+	p.file = flags.String("params", "", "path to a JSON object with the script parameters")
+	return p
+}
+
+// option returns the parameters as an execution option. --param values override those of the --params file
+func (p *scriptParams) option() (engine.ExecOption, error) {
+	params := map[string]any{}
+	if *p.file != "" {
+		content, err := os.ReadFile(*p.file)
+		if err != nil {
+			return nil, err
+		}
+		if err := decodeJSON(content, &params); err != nil {
+			return nil, fmt.Errorf("invalid params file %s: %w", *p.file, err)
+		}
+	}
+
+	for _, param := range p.values {
+		key, value, _ := strings.Cut(param, "=")
+		var decoded any
+		if err := decodeJSON([]byte(value), &decoded); err != nil {
+			decoded = value
+		}
+		params[key] = decoded
+	}
+	return engine.WithParams(params), nil
+}
+
+// decodeJSON decodes a single JSON value like json.Unmarshal, but keeps numbers as json.Number so
+// integers beyond 2^53, such as tenant ids, are passed to the script exactly as written
+func decodeJSON(data []byte, v any) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(v); err != nil {
+		return err
+	}
+	if _, err := decoder.Token(); !errors.Is(err, io.EOF) {
+		return fmt.Errorf("unexpected data after the JSON value")
+	}
+	return nil
+}
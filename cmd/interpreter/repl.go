@@ -0,0 +1,27 @@
+package main
+
+import (
+	"flag"
+	"os"
+
+	"github.com/codeis4fun/data-treatment-interpreter/internal/engine"
+	"github.com/codeis4fun/data-treatment-interpreter/internal/repl"
+)
+
+// replCommand starts an interactive session over a JSON document
+func replCommand(args []string) error {
+	flags := flag.NewFlagSet("repl", flag.ExitOnError)
+	inputPath := flags.String("input", "", "path to the JSON input (defaults to the built-in sample)")
+	flags.Parse(args)
+
+	jsonData, err := readOrDefault(*inputPath, sampleJSON)
+	if err != nil {
+		return err
+	}
+
+	r, err := repl.New(engine.NewEngine(), []byte(jsonData), os.Stdout)
+	if err != nil {
+		return err
+	}
+	return r.Run(os.Stdin)
+}
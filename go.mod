@@ -4,10 +4,8 @@ go 1.22.0
 
 require (
 	github.com/tidwall/gjson v1.18.0
+	github.com/tidwall/pretty v1.2.0
 	github.com/tidwall/sjson v1.2.5
 )
 
-require (
-	github.com/tidwall/match v1.1.1 // indirect
-	github.com/tidwall/pretty v1.2.0 // indirect
-)
+require github.com/tidwall/match v1.1.1 // indirect
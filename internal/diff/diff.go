@@ -0,0 +1,134 @@
+package diff
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/pretty"
+)
+
+// Operation kinds used by Compare (RFC 6902)
+const (
+	Add     = "add"
+	Remove  = "remove"
+	Replace = "replace"
+)
+
+// Operation is a single JSON Patch (RFC 6902) operation. OldValue is not part of the RFC:
+// it records the value that was replaced or removed, and is ignored by standard consumers
+type Operation struct {
+	Op       string          `json:"op"`
+	Path     string          `json:"path"` // JSON Pointer (RFC 6901) to the changed value
+	Value    json.RawMessage `json:"value,omitempty"`
+	OldValue json.RawMessage `json:"oldValue,omitempty"`
+}
+
+// Patch is the ordered list of operations that turns one document into another
+type Patch []Operation
+
+// Compare returns the operations that turn the before document into the after document
+func Compare(before, after []byte) Patch {
+	var patch Patch
+	compare(gjson.ParseBytes(before), gjson.ParseBytes(after), "", &patch)
+	return patch
+}
+
+// compare appends the operations that turn one value into another at the given pointer
+func compare(before, after gjson.Result, pointer string, patch *Patch) {
+	switch {
+	case before.IsObject() && after.IsObject():
+		compareObjects(before, after, pointer, patch)
+	case before.IsArray() && after.IsArray():
+		compareArrays(before.Array(), after.Array(), pointer, patch)
+	case string(compact(before.Raw)) != string(compact(after.Raw)):
+		*patch = append(*patch, Operation{Op: Replace, Path: pointer, Value: compact(after.Raw), OldValue: compact(before.Raw)})
+	}
+}
+
+// compareObjects compares two objects key by key, keeping the order in which keys appear
+func compareObjects(before, after gjson.Result, pointer string, patch *Patch) {
+	afterValues := after.Map()
+	before.ForEach(func(key, value gjson.Result) bool {
+		path := pointer + "/" + escape(key.String())
+		if next, ok := afterValues[key.String()]; ok {
+			compare(value, next, path, patch)
+		} else {
+			*patch = append(*patch, Operation{Op: Remove, Path: path, OldValue: compact(value.Raw)})
+		}
+		return true
+	})
+
+	beforeValues := before.Map()
+	after.ForEach(func(key, value gjson.Result) bool {
+		if _, ok := beforeValues[key.String()]; !ok {
+			*patch = append(*patch, Operation{Op: Add, Path: pointer + "/" + escape(key.String()), Value: compact(value.Raw)})
+		}
+		return true
+	})
+}
+
+// compareArrays compares two arrays index by index. Trailing elements are removed from the
+// end first so the patch can be applied in order
+func compareArrays(before, after []gjson.Result, pointer string, patch *Patch) {
+	common := min(len(before), len(after))
+	for i := 0; i < common; i++ {
+		compare(before[i], after[i], pointer+"/"+strconv.Itoa(i), patch)
+	}
+	for i := len(before) - 1; i >= common; i-- {
+		*patch = append(*patch, Operation{Op: Remove, Path: pointer + "/" + strconv.Itoa(i), OldValue: compact(before[i].Raw)})
+	}
+	for i := common; i < len(after); i++ {
+		*patch = append(*patch, Operation{Op: Add, Path: pointer + "/" + strconv.Itoa(i), Value: compact(after[i].Raw)})
+	}
+}
+
+// compact removes insignificant whitespace from raw JSON
+func compact(raw string) json.RawMessage {
+	if raw == "" {
+		return nil
+	}
+	return json.RawMessage(pretty.Ugly([]byte(raw)))
+}
+
+// escape encodes a key as a JSON Pointer reference token
+func escape(key string) string {
+	return strings.NewReplacer("~", "~0", "/", "~1").Replace(key)
+}
+
+// ANSI colours used by Format
+const (
+	red    = "\x1b[31m"
+	green  = "\x1b[32m"
+	yellow = "\x1b[33m"
+	reset  = "\x1b[0m"
+)
+
+// Format writes a human readable diff, one line per operation, optionally coloured for terminals
+func (p Patch) Format(w io.Writer, color bool) error {
+	paint := func(colour, line string) string {
+		if !color {
+			return line
+		}
+		return colour + line + reset
+	}
+
+	for _, op := range p {
+		var line string
+		switch op.Op {
+		case Add:
+			line = paint(green, fmt.Sprintf("+ %s: %s", op.Path, op.Value))
+		case Remove:
+			line = paint(red, fmt.Sprintf("- %s: %s", op.Path, op.OldValue))
+		default:
+			line = paint(yellow, fmt.Sprintf("~ %s: %s -> %s", op.Path, op.OldValue, op.Value))
+		}
+		if _, err := fmt.Fprintln(w, line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+package diff_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/codeis4fun/data-treatment-interpreter/internal/diff"
+)
+
+func TestCompare(t *testing.T) {
+	before := []byte(`{"name": "john", "age": 30, "tags": ["a", "b", "c"], "a/b": 1, "address": {"city": "NY"}}`)
+	after := []byte(`{"name":"JOHN","tags":["a","B"],"a/b":1,"address":{"city":"NY","zip":"10001"},"fullName":"John Doe"}`)
+
+	patch := diff.Compare(before, after)
+
+	actual, err := json.Marshal(patch)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := `[` +
+		`{"op":"replace","path":"/name","value":"JOHN","oldValue":"john"},` +
+		`{"op":"remove","path":"/age","oldValue":30},` +
+		`{"op":"replace","path":"/tags/1","value":"B","oldValue":"b"},` +
+		`{"op":"remove","path":"/tags/2","oldValue":"c"},` +
+		`{"op":"add","path":"/address/zip","value":"10001"},` +
+		`{"op":"add","path":"/fullName","value":"John Doe"}` +
+		`]`
+	if string(actual) != expected {
+		t.Errorf("Expected %s, got %s", expected, actual)
+	}
+}
+
+func TestCompareEscapesPointers(t *testing.T) {
+	patch := diff.Compare([]byte(`{}`), []byte(`{"a/b":{"c~d":1}}`))
+
+	if len(patch) != 1 || patch[0].Path != "/a~1b" {
+		t.Errorf("Expected a single add at /a~1b, got %v", patch)
+	}
+}
+
+func TestCompareIdenticalDocuments(t *testing.T) {
+	patch := diff.Compare([]byte(`{"a": [1, 2], "b": {"c": true}}`), []byte(`{"a":[1,2],"b":{"c":true}}`))
+
+	if len(patch) != 0 {
+		t.Errorf("Expected no operations, got %v", patch)
+	}
+}
+
+func TestFormat(t *testing.T) {
+	patch := diff.Compare([]byte(`{"a":1,"b":"x"}`), []byte(`{"b":"y","c":[1]}`))
+
+	var b bytes.Buffer
+	if err := patch.Format(&b, false); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := "- /a: 1\n~ /b: \"x\" -> \"y\"\n+ /c: [1]\n"
+	if b.String() != expected {
+		t.Errorf("Expected %q, got %q", expected, b.String())
+	}
+
+	b.Reset()
+	patch.Format(&b, true)
+	if !bytes.Contains(b.Bytes(), []byte("\x1b[32m+ /c: [1]\x1b[0m")) {
+		t.Errorf("Expected coloured additions, got %q", b.String())
+	}
+}
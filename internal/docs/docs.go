@@ -0,0 +1,121 @@
+package docs
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/codeis4fun/data-treatment-interpreter/internal/engine"
+	"github.com/tidwall/pretty"
+)
+
+// Page is the JSON representation of a transformer reference page
+type Page struct {
+	engine.Signature
+	Syntax string `json:"syntax"` // The signature in script syntax (e.g. "result = uppercase(value)")
+}
+
+// Pages builds one reference page per transformer, in the order of the given signatures
+func Pages(signatures []engine.Signature) []Page {
+	pages := make([]Page, 0, len(signatures))
+	for _, signature := range signatures {
+		pages = append(pages, Page{Signature: signature, Syntax: signature.String()})
+	}
+	return pages
+}
+
+// JSON writes the transformer reference as an indented JSON array
+func JSON(w io.Writer, signatures []engine.Signature) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(Pages(signatures))
+}
+
+// Markdown writes the transformer reference as a Markdown document
+func Markdown(w io.Writer, signatures []engine.Signature) error {
+	var b strings.Builder
+
+	b.WriteString("# Transformer Reference\n\n")
+	b.WriteString("This reference is generated from the transformers registered in the engine. Transformers that engines only register when given an option say so. Every example below is executed by the test suite.\n\n")
+	for _, signature := range signatures {
+		fmt.Fprintf(&b, "- [`%s`](#%s)\n", signature.Name, signature.Name)
+	}
+
+	for _, signature := range signatures {
+		writeSignature(&b, signature)
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// writeSignature writes the reference section of a single transformer
+func writeSignature(b *strings.Builder, signature engine.Signature) {
+	fmt.Fprintf(b, "\n## %s\n\n", signature.Name)
+	if signature.Description != "" {
+		fmt.Fprintf(b, "%s\n\n", signature.Description)
+	}
+	if signature.Requires != "" {
+		fmt.Fprintf(b, "Only registered by %s.\n\n", signature.Requires)
+	}
+	fmt.Fprintf(b, "```plaintext\nSET %s\n```\n", signature.String())
+
+	if len(signature.Params) > 0 {
+		b.WriteString("\n### Parameters\n\n")
+		b.WriteString("| Name | Kind | Type | Required |\n")
+		b.WriteString("| --- | --- | --- | --- |\n")
+		for i, param := range signature.Params {
+			name := param.Name
+			if signature.Variadic && i == len(signature.Params)-1 {
+				name += " (repeatable)"
+			}
+			required := "yes"
+			if param.Optional {
+				required = "no"
+			}
+			fmt.Fprintf(b, "| `%s` | %s | %s | %s |\n", name, param.Kind, param.Type, required)
+		}
+	}
+
+	b.WriteString("\n### Outputs\n\n")
+	for _, output := range signature.Outputs {
+		fmt.Fprintf(b, "- `%s`\n", output)
+	}
+	if signature.VariadicOutputs {
+		b.WriteString("\nThe number of outputs depends on the data; assign one variable per expected output.\n")
+	}
+
+	var examples, errorCases []engine.Example
+	for _, example := range signature.Examples {
+		if example.Error != "" {
+			errorCases = append(errorCases, example)
+		} else {
+			examples = append(examples, example)
+		}
+	}
+
+	if len(examples) > 0 {
+		b.WriteString("\n### Examples\n")
+		for _, example := range examples {
+			fmt.Fprintf(b, "\n```plaintext\n%s\n```\n\n", example.Script)
+			if example.Now != "" {
+				fmt.Fprintf(b, "With the clock at `%s`.\n\n", example.Now)
+			}
+			if example.Note != "" {
+				fmt.Fprintf(b, "%s\n\n", example.Note)
+			}
+			fmt.Fprintf(b, "Input:\n\n```json\n%s```\n\n", pretty.Pretty([]byte(example.Input)))
+			fmt.Fprintf(b, "Output:\n\n```json\n%s```\n", pretty.Pretty([]byte(example.Output)))
+		}
+	}
+
+	if len(errorCases) > 0 {
+		b.WriteString("\n### Errors\n")
+		for _, example := range errorCases {
+			fmt.Fprintf(b, "\n```plaintext\n%s\n```\n\n", example.Script)
+			fmt.Fprintf(b, "Input:\n\n```json\n%s```\n\n", pretty.Pretty([]byte(example.Input)))
+			fmt.Fprintf(b, "Fails with an error containing `%s`.\n", example.Error)
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package docs_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/codeis4fun/data-treatment-interpreter/internal/docs"
+	"github.com/codeis4fun/data-treatment-interpreter/internal/engine"
+)
+
+func TestMarkdown(t *testing.T) {
+	var b bytes.Buffer
+	if err := docs.Markdown(&b, engine.Reference()); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := []string{
+		"# Transformer Reference",
+		"- [`bmi`](#bmi)",
+		"## split",
+		"SET part... = split(value, 'separator')",
+		"| `rest (repeatable)` | field | any | yes |",
+		"- `isHealthy`",
+		"Fails with an error containing `weight and height must be numbers`.",
+		"## encrypt",
+		"## bmipercentile",
+		"Only registered by engine.WithGrowthReference (--growth-reference).",
+		"On a made-up chart whose L, M and S for boys of 24 months are 1, 16 and 0.1",
+	}
+	for _, want := range expected {
+		if !strings.Contains(b.String(), want) {
+			t.Errorf("Expected Markdown to contain %q", want)
+		}
+	}
+}
+
+func TestJSON(t *testing.T) {
+	var b bytes.Buffer
+	if err := docs.JSON(&b, engine.Reference()); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	var pages []map[string]any
+	if err := json.Unmarshal(b.Bytes(), &pages); err != nil {
+		t.Fatalf("Expected valid JSON, got %v", err)
+	}
+
+	if len(pages) != len(engine.Reference()) {
+		t.Fatalf("Expected one page per transformer, got %d", len(pages))
+	}
+
+	for _, page := range pages {
+		if page["name"] == "uppercase" {
+			if page["syntax"] != "result = uppercase(value)" {
+				t.Errorf("Expected syntax %q, got %v", "result = uppercase(value)", page["syntax"])
+			}
+			return
+		}
+	}
+	t.Errorf("Expected a page for uppercase")
+}
+
+func TestEveryTransformerHasExamples(t *testing.T) {
+	for _, signature := range engine.Reference() {
+		if len(signature.Examples) == 0 {
+			t.Errorf("Transformer '%s' has no examples, so the reference does not show how to use it", signature.Name)
+		}
+	}
+}
@@ -0,0 +1,455 @@
+// Package document holds a JSON document in memory so a script can read and update it in place,
+// instead of copying and re-parsing the whole document on every write.
+//
+// A Document is expanded lazily: only the objects and arrays on the paths that are written are
+// parsed, every other value keeps the raw JSON it was read with. Reads follow gjson path syntax
+// and writes follow sjson, so both models produce the same document.
+package document
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+// Document is a JSON document that can be read and updated in place. It is not safe for concurrent use
+type Document struct {
+	root *node
+	log  []func() // Undo operations for every change, in the order they were made
+}
+
+// node is a value of the document. Objects and arrays are expanded into their children the first time
+// something below them is written; until then, and for every other value, raw holds the JSON as read
+type node struct {
+	raw    string
+	kind   byte           // '{' or '[' once expanded, 0 while raw
+	keys   []key          // Keys of an expanded object, in document order
+	values []*node        // Values of an expanded object (one per key) or elements of an expanded array
+	index  map[string]int // Position of the first occurrence of each key of an expanded object
+}
+
+// key is an object key together with the JSON it is written as
+type key struct {
+	name string
+	raw  string
+}
+
+// Parse wraps a JSON document. Nothing is parsed until the document is read or written
+func Parse(data []byte) *Document {
+	return &Document{root: &node{raw: string(data)}}
+}
+
+// Get returns the value at a gjson path
+func (d *Document) Get(path string) gjson.Result {
+	segments, ok := split(path)
+	if !ok {
+		return gjson.Get(string(d.Bytes()), path)
+	}
+
+	n := d.root
+	for i, segment := range segments {
+		switch n.kind {
+		case 0:
+			// The rest of the path is inside a value that was never expanded
+			return gjson.Get(n.raw, join(segments[i:]))
+		case '{':
+			position, ok := n.index[segment]
+			if !ok {
+				return gjson.Result{}
+			}
+			n = n.values[position]
+		case '[':
+			position, ok := arrayIndex(segment)
+			if !ok || position >= len(n.values) {
+				return gjson.Result{}
+			}
+			n = n.values[position]
+		}
+	}
+
+	if n.kind == 0 {
+		return gjson.Parse(n.raw)
+	}
+	return gjson.Parse(string(n.appendTo(nil)))
+}
+
+// Set writes a value at an sjson path, encoding it the way sjson.SetBytes does
+func (d *Document) Set(path string, value any) error {
+	raw, err := Encode(value)
+	if err != nil {
+		return err
+	}
+	return d.SetRaw(path, raw)
+}
+
+// SetRaw writes raw JSON at an sjson path, creating the objects and arrays on the way when they are missing
+func (d *Document) SetRaw(path string, raw []byte) error {
+	segments, ok := split(path)
+	if !ok {
+		// Paths beyond plain keys and indexes are left to sjson on the serialised document
+		data, err := sjson.SetRawBytes(d.Bytes(), path, raw)
+		if err != nil {
+			return err
+		}
+		d.replaceRoot(string(data))
+		return nil
+	}
+
+	if strings.TrimSpace(d.root.raw) == "" && d.root.kind == 0 {
+		d.replaceRoot("{}")
+	}
+
+	n := d.root
+	for i, segment := range segments[:len(segments)-1] {
+		if !n.expand() {
+			return fmt.Errorf("cannot set '%s': value is not an object or array", path)
+		}
+		child := n.child(segment)
+		if child == nil || (child.kind == 0 && !isContainer(child.raw)) {
+			// Missing values and scalars are replaced by an array when the next key is an index, an object otherwise
+			child = &node{raw: "{}"}
+			if next := segments[i+1]; next == "-1" || isIndex(next) {
+				child.raw = "[]"
+			}
+			if err := d.assign(n, segment, child); err != nil {
+				return err
+			}
+		}
+		n = child
+	}
+
+	if !n.expand() {
+		return fmt.Errorf("cannot set '%s': value is not an object or array", path)
+	}
+	return d.assign(n, segments[len(segments)-1], &node{raw: string(raw)})
+}
+
+// Delete removes the value at an sjson path. Deleting a missing value does nothing
+func (d *Document) Delete(path string) error {
+	segments, ok := split(path)
+	if !ok {
+		data, err := sjson.DeleteBytes(d.Bytes(), path)
+		if err != nil {
+			return err
+		}
+		d.replaceRoot(string(data))
+		return nil
+	}
+
+	n := d.root
+	for _, segment := range segments[:len(segments)-1] {
+		if !n.expand() {
+			return nil
+		}
+		if n = n.child(segment); n == nil {
+			return nil
+		}
+	}
+	if !n.expand() {
+		return nil
+	}
+	d.remove(n, segments[len(segments)-1])
+	return nil
+}
+
+// Bytes serialises the document. Values that were never expanded keep their original formatting
+func (d *Document) Bytes() []byte {
+	return d.root.appendTo(nil)
+}
+
+// Mark returns a point in the history of the document that Rollback can return to
+func (d *Document) Mark() int {
+	return len(d.log)
+}
+
+// Rollback undoes every change made since the mark
+func (d *Document) Rollback(mark int) {
+	for i := len(d.log) - 1; i >= mark; i-- {
+		d.log[i]()
+	}
+	d.log = d.log[:mark]
+}
+
+// Commit forgets the history of the document, so the changes made so far can no longer be rolled back
+func (d *Document) Commit() {
+	d.log = d.log[:0]
+}
+
+// replaceRoot replaces the whole document with raw JSON
+func (d *Document) replaceRoot(raw string) {
+	previous := d.root
+	d.root = &node{raw: raw}
+	d.log = append(d.log, func() { d.root = previous })
+}
+
+// assign sets the child of an expanded object or array, appending keys that do not exist yet and
+// padding arrays with nulls up to the index written
+func (d *Document) assign(n *node, segment string, child *node) error {
+	if n.kind == '{' {
+		if position, ok := n.index[segment]; ok {
+			previous := n.values[position]
+			n.values[position] = child
+			d.log = append(d.log, func() { n.values[position] = previous })
+			return nil
+		}
+
+		n.keys = append(n.keys, key{name: segment, raw: string(appendString(nil, segment))})
+		n.values = append(n.values, child)
+		n.index[segment] = len(n.values) - 1
+		d.log = append(d.log, func() {
+			n.keys = n.keys[:len(n.keys)-1]
+			n.values = n.values[:len(n.values)-1]
+			delete(n.index, segment)
+		})
+		return nil
+	}
+
+	position := len(n.values)
+	if segment != "-1" {
+		var ok bool
+		if position, ok = arrayIndex(segment); !ok {
+			return fmt.Errorf("cannot set array element for non-numeric key '%s'", segment)
+		}
+	}
+	if position < len(n.values) {
+		previous := n.values[position]
+		n.values[position] = child
+		d.log = append(d.log, func() { n.values[position] = previous })
+		return nil
+	}
+
+	length := len(n.values)
+	for len(n.values) < position {
+		n.values = append(n.values, &node{raw: "null"})
+	}
+	n.values = append(n.values, child)
+	d.log = append(d.log, func() { n.values = n.values[:length] })
+	return nil
+}
+
+// remove deletes the child of an expanded object or array
+func (d *Document) remove(n *node, segment string) {
+	var position int
+	var ok bool
+	if n.kind == '{' {
+		position, ok = n.index[segment]
+	} else {
+		position, ok = arrayIndex(segment)
+		ok = ok && position < len(n.values)
+	}
+	if !ok {
+		return
+	}
+
+	keys := append([]key(nil), n.keys...)
+	values := append([]*node(nil), n.values...)
+	if n.kind == '{' {
+		n.keys = append(n.keys[:position:position], n.keys[position+1:]...)
+	}
+	n.values = append(n.values[:position:position], n.values[position+1:]...)
+	n.reindex()
+	d.log = append(d.log, func() {
+		n.keys, n.values = keys, values
+		n.reindex()
+	})
+}
+
+// expand parses an object or array into its children, reporting whether the node is a container
+func (n *node) expand() bool {
+	if n.kind != 0 {
+		return true
+	}
+
+	value := gjson.Parse(n.raw)
+	switch {
+	case value.IsObject():
+		n.kind = '{'
+		value.ForEach(func(k, v gjson.Result) bool {
+			n.keys = append(n.keys, key{name: k.Str, raw: k.Raw})
+			n.values = append(n.values, &node{raw: v.Raw})
+			return true
+		})
+		n.reindex()
+	case value.IsArray():
+		n.kind = '['
+		value.ForEach(func(_, v gjson.Result) bool {
+			n.values = append(n.values, &node{raw: v.Raw})
+			return true
+		})
+	default:
+		return false
+	}
+	n.raw = ""
+	return true
+}
+
+// reindex rebuilds the positions of the keys of an object
+func (n *node) reindex() {
+	if n.kind != '{' {
+		return
+	}
+	n.index = make(map[string]int, len(n.keys))
+	for i := len(n.keys) - 1; i >= 0; i-- {
+		n.index[n.keys[i].name] = i
+	}
+}
+
+// child returns the child of an expanded object or array, or nil when there is none
+func (n *node) child(segment string) *node {
+	if n.kind == '{' {
+		if position, ok := n.index[segment]; ok {
+			return n.values[position]
+		}
+		return nil
+	}
+	if position, ok := arrayIndex(segment); ok && position < len(n.values) {
+		return n.values[position]
+	}
+	return nil
+}
+
+// appendTo appends the JSON of the node to a buffer
+func (n *node) appendTo(buf []byte) []byte {
+	switch n.kind {
+	case '{':
+		buf = append(buf, '{')
+		for i, key := range n.keys {
+			if i > 0 {
+				buf = append(buf, ',')
+			}
+			buf = append(buf, key.raw...)
+			buf = append(buf, ':')
+			buf = n.values[i].appendTo(buf)
+		}
+		return append(buf, '}')
+	case '[':
+		buf = append(buf, '[')
+		for i, value := range n.values {
+			if i > 0 {
+				buf = append(buf, ',')
+			}
+			buf = value.appendTo(buf)
+		}
+		return append(buf, ']')
+	}
+	return append(buf, n.raw...)
+}
+
+// isContainer reports whether raw JSON is an object or an array
+func isContainer(raw string) bool {
+	raw = strings.TrimSpace(raw)
+	return raw != "" && (raw[0] == '{' || raw[0] == '[')
+}
+
+// split breaks a path made only of plain keys and indexes into its segments, unescaping '\.'.
+// Paths using any other gjson or sjson syntax (wildcards, queries, modifiers...) are not split
+func split(path string) ([]string, bool) {
+	if path == "" || strings.ContainsAny(path, "*?#@|!=<>%()[]{},:\"") {
+		return nil, false
+	}
+
+	var segments []string
+	var segment strings.Builder
+	for i := 0; i < len(path); i++ {
+		switch c := path[i]; c {
+		case '\\':
+			i++
+			if i == len(path) {
+				return nil, false
+			}
+			segment.WriteByte(path[i])
+		case '.':
+			segments = append(segments, segment.String())
+			segment.Reset()
+		default:
+			segment.WriteByte(c)
+		}
+	}
+	segments = append(segments, segment.String())
+
+	for _, segment := range segments {
+		if segment == "" {
+			return nil, false
+		}
+	}
+	return segments, true
+}
+
+// join rebuilds a path from its segments, escaping the dots inside them
+func join(segments []string) string {
+	escaped := make([]string, len(segments))
+	for i, segment := range segments {
+		escaped[i] = strings.ReplaceAll(segment, ".", `\.`)
+	}
+	return strings.Join(escaped, ".")
+}
+
+// isIndex reports whether a segment is made of digits only
+func isIndex(segment string) bool {
+	for i := 0; i < len(segment); i++ {
+		if segment[i] < '0' || segment[i] > '9' {
+			return false
+		}
+	}
+	return segment != ""
+}
+
+// arrayIndex parses a segment as an array index
+func arrayIndex(segment string) (int, bool) {
+	if !isIndex(segment) {
+		return 0, false
+	}
+	position, err := strconv.Atoi(segment)
+	return position, err == nil
+}
+
+// Encode converts a value to JSON the way sjson.SetBytes does: strings and byte slices become
+// JSON strings, numbers and booleans are formatted directly and anything else goes through json.Marshal
+func Encode(value any) ([]byte, error) {
+	switch v := value.(type) {
+	case string:
+		return appendString(nil, v), nil
+	case []byte:
+		return appendString(nil, string(v)), nil
+	case bool:
+		return strconv.AppendBool(nil, v), nil
+	case int8:
+		return strconv.AppendInt(nil, int64(v), 10), nil
+	case int16:
+		return strconv.AppendInt(nil, int64(v), 10), nil
+	case int32:
+		return strconv.AppendInt(nil, int64(v), 10), nil
+	case int64:
+		return strconv.AppendInt(nil, v, 10), nil
+	case uint8:
+		return strconv.AppendUint(nil, uint64(v), 10), nil
+	case uint16:
+		return strconv.AppendUint(nil, uint64(v), 10), nil
+	case uint32:
+		return strconv.AppendUint(nil, uint64(v), 10), nil
+	case uint64:
+		return strconv.AppendUint(nil, v, 10), nil
+	case float32:
+		return strconv.AppendFloat(nil, float64(v), 'f', -1, 64), nil
+	case float64:
+		return strconv.AppendFloat(nil, v, 'f', -1, 64), nil
+	}
+	return json.Marshal(value)
+}
+
+// appendString appends a JSON string, escaping it only when needed
+func appendString(buf []byte, s string) []byte {
+	for i := 0; i < len(s); i++ {
+		if s[i] < ' ' || s[i] > 0x7f || s[i] == '"' || s[i] == '\\' {
+			escaped, _ := json.Marshal(s)
+			return append(buf, escaped...)
+		}
+	}
+	buf = append(buf, '"')
+	buf = append(buf, s...)
+	return append(buf, '"')
+}
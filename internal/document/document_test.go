@@ -0,0 +1,131 @@
+package document_test
+
+import (
+	"testing"
+
+	"github.com/codeis4fun/data-treatment-interpreter/internal/document"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+const input = `{"name":"john","tags":["a","b"],"address":{"city":"Lisbon","geo":{"lat":38.7}},"friends":[{"name":"alice"},{"name":"bob","age":30}],"dotted.key":1}`
+
+func TestSetMatchesSjson(t *testing.T) {
+	tests := []struct {
+		path  string
+		value any
+	}{
+		{path: "name", value: "JOHN"},
+		{path: "surname", value: "doe"},
+		{path: "tags.1", value: "B"},
+		{path: "tags.4", value: "e"},
+		{path: "tags.-1", value: "z"},
+		{path: "address.city", value: "Porto"},
+		{path: "address.geo.lng", value: -9.1},
+		{path: "address.zip.code", value: 1000},
+		{path: "friends.1.age", value: 31},
+		{path: "friends.0.nick", value: nil},
+		{path: "scores.2", value: true},
+		{path: "name.first", value: "john"},
+		{path: `dotted\.key`, value: 2},
+		{path: "note", value: "<quote \" and é>"},
+		{path: "list", value: []int{1, 2}},
+		{path: "friends.#.name", value: "x"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			expected, expectedErr := sjson.SetBytes([]byte(input), tt.path, tt.value)
+
+			d := document.Parse([]byte(input))
+			err := d.Set(tt.path, tt.value)
+			if (err != nil) != (expectedErr != nil) {
+				t.Fatalf("Expected error %v, got %v", expectedErr, err)
+			}
+			if err == nil && string(d.Bytes()) != string(expected) {
+				t.Errorf("Expected %s, got %s", expected, d.Bytes())
+			}
+		})
+	}
+}
+
+func TestSetOnArrayWithKey(t *testing.T) {
+	d := document.Parse([]byte(input))
+	if err := d.Set("tags.first", "x"); err == nil {
+		t.Fatalf("Expected error, got nil")
+	}
+}
+
+func TestGetMatchesGjson(t *testing.T) {
+	d := document.Parse([]byte(input))
+	// Expand part of the document so reads go through both expanded and raw values
+	if err := d.Set("friends.0.nick", "al"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	output := d.Bytes()
+
+	for _, path := range []string{"name", "tags", "tags.1", "tags.9", "address.geo.lat", "friends", "friends.0", "friends.0.nick", "friends.1.age", "friends.#.name", "friends.#", "missing.path", `dotted\.key`, "name.first"} {
+		expected := gjson.GetBytes(output, path)
+		actual := d.Get(path)
+		if actual.Exists() != expected.Exists() || actual.Raw != expected.Raw {
+			t.Errorf("%s: expected %s, got %s", path, expected.Raw, actual.Raw)
+		}
+	}
+}
+
+func TestDelete(t *testing.T) {
+	for _, path := range []string{"name", "tags.0", "address.geo", "friends.1.age", "missing", "name.first", "friends.#.name"} {
+		t.Run(path, func(t *testing.T) {
+			expected, expectedErr := sjson.DeleteBytes([]byte(input), path)
+
+			d := document.Parse([]byte(input))
+			err := d.Delete(path)
+			if (err != nil) != (expectedErr != nil) {
+				t.Fatalf("Expected error %v, got %v", expectedErr, err)
+			}
+			if err == nil && string(d.Bytes()) != string(expected) {
+				t.Errorf("Expected %s, got %s", expected, d.Bytes())
+			}
+		})
+	}
+}
+
+func TestRollback(t *testing.T) {
+	d := document.Parse([]byte(input))
+	if err := d.Set("name", "JOHN"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	afterFirst := string(d.Bytes())
+
+	mark := d.Mark()
+	for _, path := range []string{"surname", "tags.5", "address.zip.code", "friends.0.name"} {
+		if err := d.Set(path, "x"); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+	for _, path := range []string{"address.geo", "tags.0", "friends.1"} {
+		if err := d.Delete(path); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+
+	d.Rollback(mark)
+	if string(d.Bytes()) != afterFirst {
+		t.Errorf("Expected %s, got %s", afterFirst, d.Bytes())
+	}
+	if d.Get("address.geo.lat").Float() != 38.7 {
+		t.Errorf("Expected address.geo.lat to be restored, got %s", d.Get("address.geo.lat").Raw)
+	}
+}
+
+func TestSetOnEmptyDocument(t *testing.T) {
+	d := document.Parse(nil)
+	if err := d.Set("a.0.b", 1); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := `{"a":[{"b":1}]}`
+	if string(d.Bytes()) != expected {
+		t.Errorf("Expected %s, got %s", expected, d.Bytes())
+	}
+}
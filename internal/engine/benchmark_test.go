@@ -0,0 +1,134 @@
+package engine_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/codeis4fun/data-treatment-interpreter/internal/engine"
+	"github.com/codeis4fun/data-treatment-interpreter/internal/parser"
+)
+
+const benchmarkScript = `SET _fullName = concatenate(' ', name, surname)
+SET fullName = uppercase(_fullName)
+SET bmi, isHealthy = bmi(weight, height)
+SET city, country = split(place, '/')
+SET friends.#.name = uppercase(friends.#.name)
+SET friends.#.nickname = coalesce(friends.#.nickname, friends.#.name)`
+
+var benchmarkJSON = []byte(`{"name":"john","surname":"doe","weight":75,"height":1.75,"place":"Lisbon/Portugal","friends":[{"name":"alice"},{"name":"bob","nickname":"bobby"},{"name":"carol"},{"name":"dave"}]}`)
+
+func benchmarkPrograms(b *testing.B) []*parser.Program {
+	b.Helper()
+	programs, err := parser.Parse(benchmarkScript)
+	if err != nil {
+		b.Fatalf("Unexpected error: %v", err)
+	}
+	return programs
+}
+
+func BenchmarkExecuteAll(b *testing.B) {
+	programs := benchmarkPrograms(b)
+	e := engine.NewEngine()
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := e.ExecuteAll(programs, benchmarkJSON); err != nil {
+			b.Fatalf("Unexpected error: %v", err)
+		}
+	}
+}
+
+func BenchmarkPlanExecute(b *testing.B) {
+	plan, err := engine.NewEngine().Compile(benchmarkPrograms(b))
+	if err != nil {
+		b.Fatalf("Unexpected error: %v", err)
+	}
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := plan.Execute(benchmarkJSON); err != nil {
+			b.Fatalf("Unexpected error: %v", err)
+		}
+	}
+}
+
+func BenchmarkPlanExecuteParallel(b *testing.B) {
+	plan, err := engine.NewEngine().Compile(benchmarkPrograms(b))
+	if err != nil {
+		b.Fatalf("Unexpected error: %v", err)
+	}
+
+	b.ReportAllocs()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			if _, err := plan.Execute(benchmarkJSON); err != nil {
+				b.Error(err)
+				return
+			}
+		}
+	})
+}
+
+// largeDocument builds an object with the given number of fields and an array of the given number of objects
+func largeDocument(fields, elements int) []byte {
+	var b strings.Builder
+	b.WriteString(`{`)
+	for i := 0; i < fields; i++ {
+		fmt.Fprintf(&b, `"field%d":"value %d",`, i, i)
+	}
+	b.WriteString(`"items":[`)
+	for i := 0; i < elements; i++ {
+		if i > 0 {
+			b.WriteString(",")
+		}
+		fmt.Fprintf(&b, `{"id":%d,"name":"item %d","tags":"a/b"}`, i, i)
+	}
+	b.WriteString(`]}`)
+	return []byte(b.String())
+}
+
+// benchmarkModels runs a script against a document under both document models
+func benchmarkModels(b *testing.B, script string, jsonData []byte) {
+	programs, err := parser.Parse(script)
+	if err != nil {
+		b.Fatalf("Unexpected error: %v", err)
+	}
+
+	models := []struct {
+		name string
+		opts []engine.Option
+	}{
+		{name: "bytes"},
+		{name: "document", opts: []engine.Option{engine.WithDocumentModel()}},
+	}
+	for _, model := range models {
+		b.Run(model.name, func(b *testing.B) {
+			plan, err := engine.NewEngine(model.opts...).Compile(programs)
+			if err != nil {
+				b.Fatalf("Unexpected error: %v", err)
+			}
+
+			b.ReportAllocs()
+			b.SetBytes(int64(len(jsonData)))
+			for i := 0; i < b.N; i++ {
+				if _, err := plan.Execute(jsonData); err != nil {
+					b.Fatalf("Unexpected error: %v", err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkLargeDocument(b *testing.B) {
+	var script strings.Builder
+	for i := 0; i < 50; i++ {
+		fmt.Fprintf(&script, "SET upper%d = uppercase(field%d)\n", i, i*10)
+	}
+	benchmarkModels(b, script.String(), largeDocument(1000, 0))
+}
+
+func BenchmarkWideArray(b *testing.B) {
+	benchmarkModels(b, `SET items.#.name = uppercase(items.#.name)
+SET items.#.first, items.#.second = split(items.#.tags, '/')`, largeDocument(0, 1000))
+}
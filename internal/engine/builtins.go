@@ -0,0 +1,186 @@
+package engine
+
+import (
+	"slices"
+
+	"github.com/codeis4fun/data-treatment-interpreter/internal/transformers"
+)
+
+// builtins lists the transformers registered by NewEngine
+var builtins = slices.Concat(coreBuiltins, textBuiltins, regexBuiltins, dateBuiltins, mathBuiltins, convertBuiltins, unitBuiltins, healthBuiltins, hashBuiltins, privacyBuiltins)
+
+// coreBuiltins are the general-purpose transformers
+var coreBuiltins = []registration{
+	{
+		signature: Signature{
+			Name:        "uppercase",
+			Description: "Converts the value of a field to uppercase.",
+			Params: []Param{
+				{Name: "value", Kind: FieldParam, Type: "string"},
+			},
+			Outputs: []string{"result"},
+			Examples: []Example{
+				{
+					Script: "SET name = uppercase(name)",
+					Input:  `{"name":"john"}`,
+					Output: `{"name":"JOHN"}`,
+				},
+				{
+					Script: "SET middleName = uppercase(middleName?)",
+					Input:  `{"name":"john"}`,
+					Output: `{"name":"john"}`,
+				},
+				{
+					Script: "SET name = uppercase(middleName)",
+					Input:  `{"name":"john"}`,
+					Error:  "argument 'middleName' not found in JSON",
+				},
+			},
+		},
+		factory: func(config transformers.Config) Transformer { return Adapt(&transformers.Uppercase{Config: config}) },
+	},
+	{
+		signature: Signature{
+			Name:        "concatenate",
+			Description: "Joins the values of two or more fields with a separator.",
+			Params: []Param{
+				{Name: "separator", Kind: LiteralParam, Type: "string"},
+				{Name: "first", Kind: FieldParam, Type: "any"},
+				{Name: "rest", Kind: FieldParam, Type: "any"},
+			},
+			Variadic: true,
+			Outputs:  []string{"result"},
+			Examples: []Example{
+				{
+					Script: "SET fullName = concatenate(' ', firstName, lastName)",
+					Input:  `{"firstName":"john","lastName":"doe"}`,
+					Output: `{"firstName":"john","lastName":"doe","fullName":"john doe"}`,
+				},
+				{
+					Script: "SET fullName = concatenate(' ', firstName, lastName)",
+					Input:  `{"firstName":"john"}`,
+					Error:  "argument 'lastName' not found in JSON",
+				},
+			},
+		},
+		factory: func(config transformers.Config) Transformer { return Adapt(&transformers.Concatenate{Config: config}) },
+	},
+	{
+		signature: Signature{
+			Name:        "bmi",
+			Description: "Calculates the Body Mass Index from a weight and a height, in kilograms and metres unless other units are given, rounded to one decimal place unless other digits are given, and whether it is in the normal range of the WHO (at least 18.5 and under 25, once rounded).",
+			Params:      bmiParams,
+			Outputs:     []string{"bmi", "isHealthy"},
+			Examples: []Example{
+				{
+					Script: "SET bmi, isHealthy = bmi(weight, height)",
+					Input:  `{"weight":75,"height":1.75}`,
+					Output: `{"weight":75,"height":1.75,"bmi":24.5,"isHealthy":true}`,
+				},
+				{
+					Script: "SET bmi, isHealthy = bmi(weight, height, 'lb', 'in', '2')",
+					Input:  `{"weight":165,"height":69}`,
+					Output: `{"weight":165,"height":69,"bmi":24.37,"isHealthy":true}`,
+				},
+				{
+					Script: "SET bmi, isHealthy = bmi(weight, height)",
+					Input:  `{"weight":"75kg","height":1.75}`,
+					Error:  "weight and height must be numbers",
+				},
+			},
+		},
+		factory: func(config transformers.Config) Transformer { return Adapt(&transformers.BMI{Config: config}) },
+	},
+	{
+		signature: Signature{
+			Name:        "bmicategory",
+			Description: "Calculates the Body Mass Index like bmi and also returns its category: underweight, normal, overweight or obese. The categories start at the WHO cutoffs (18.5, 25 and 30) unless other cutoffs are given.",
+			Params:      append(bmiParams, Param{Name: "cutoffs", Kind: LiteralParam, Type: "string", Optional: true}),
+			Outputs:     []string{"bmi", "isHealthy", "category"},
+			Examples: []Example{
+				{
+					Script: "SET bmi, isHealthy, category = bmicategory(weight, height, 'kg', 'cm')",
+					Input:  `{"weight":92,"height":180}`,
+					Output: `{"weight":92,"height":180,"bmi":28.4,"isHealthy":false,"category":"overweight"}`,
+				},
+				{
+					Script: "SET bmi, isHealthy, category = bmicategory(weight, height, 'kg', 'm', '1', '18.5,23,27.5')",
+					Input:  `{"weight":75,"height":1.75}`,
+					Output: `{"weight":75,"height":1.75,"bmi":24.5,"isHealthy":false,"category":"overweight"}`,
+				},
+				{
+					Script: "SET bmi, isHealthy, category = bmicategory(weight, height, 'kg', 'm', '1', '30,25')",
+					Input:  `{"weight":75,"height":1.75}`,
+					Error:  "cutoffs must be three increasing numbers (normal, overweight, obese), got '30,25'",
+				},
+			},
+		},
+		factory: func(config transformers.Config) Transformer {
+			return Adapt(&transformers.BMI{Config: config, Category: true})
+		},
+	},
+	{
+		signature: Signature{
+			Name:        "split",
+			Description: "Splits the value of a field by a separator, returning one output per part.",
+			Params: []Param{
+				{Name: "value", Kind: FieldParam, Type: "string"},
+				{Name: "separator", Kind: LiteralParam, Type: "string"},
+			},
+			Outputs:         []string{"part"},
+			VariadicOutputs: true,
+			Examples: []Example{
+				{
+					Script: "SET city, country = split(place, '/')",
+					Input:  `{"place":"New York/USA"}`,
+					Output: `{"place":"New York/USA","city":"New York","country":"USA"}`,
+				},
+				{
+					Script: "SET city, country = split(place, '/')",
+					Input:  `{"place":"New York"}`,
+					Error:  "number of output values does not match",
+				},
+			},
+		},
+		factory: func(config transformers.Config) Transformer { return Adapt(&transformers.Split{Config: config}) },
+	},
+	{
+		signature: Signature{
+			Name:        "coalesce",
+			Description: "Returns the first argument that is present and not null. Fields keep their JSON type; literals are strings.",
+			Params: []Param{
+				{Name: "first", Kind: AnyParam, Type: "any"},
+				{Name: "rest", Kind: AnyParam, Type: "any"},
+			},
+			Variadic: true,
+			Outputs:  []string{"result"},
+			Examples: []Example{
+				{
+					Script: "SET displayName = coalesce(nickname, firstName, 'anonymous')",
+					Input:  `{"nickname":null,"firstName":"john"}`,
+					Output: `{"nickname":null,"firstName":"john","displayName":"john"}`,
+				},
+				{
+					Script: "SET displayName = coalesce(nickname, firstName, 'anonymous')",
+					Input:  `{}`,
+					Output: `{"displayName":"anonymous"}`,
+				},
+				{
+					Script: "SET displayName = coalesce(nickname, firstName)",
+					Input:  `{"nickname":null}`,
+					Error:  "no value found in nickname, firstName",
+				},
+			},
+		},
+		factory: func(config transformers.Config) Transformer { return Adapt(&transformers.Coalesce{Config: config}) },
+	},
+}
+
+// bmiParams are the parameters of bmi, shared by bmicategory
+var bmiParams = []Param{
+	{Name: "weight", Kind: FieldParam, Type: "number"},
+	{Name: "height", Kind: FieldParam, Type: "number"},
+	{Name: "weightUnit", Kind: LiteralParam, Type: "string", Optional: true},
+	{Name: "heightUnit", Kind: LiteralParam, Type: "string", Optional: true},
+	{Name: "digits", Kind: LiteralParam, Type: "integer", Optional: true},
+}
@@ -0,0 +1,110 @@
+package engine
+
+import "github.com/codeis4fun/data-treatment-interpreter/internal/transformers"
+
+// convertBuiltins are the type conversion transformers. They take an optional mode: strict, the
+// default, fails on values it cannot convert, while lenient accepts loose forms and writes null otherwise
+var convertBuiltins = []registration{
+	conversionBuiltin("tonumber", "Converts a number or a string holding a number to a number. Lenient mode also accepts booleans (1 and 0), a leading + and thousands separators.",
+		func(config transformers.Config) Transformer { return Adapt(&transformers.ToNumber{Config: config}) },
+		Example{Script: "SET weight = tonumber(weight)", Input: `{"weight":"75.50"}`, Output: `{"weight":75.5}`},
+		Example{Script: "SET price = tonumber(price, 'lenient')", Input: `{"price":"+1,234.50"}`, Output: `{"price":1234.5}`},
+		Example{Script: "SET price = tonumber(price, 'lenient')", Input: `{"price":"n/a"}`, Output: `{"price":null}`},
+		Example{Script: "SET weight = tonumber(weight)", Input: `{"weight":"n/a"}`, Error: `cannot convert "n/a" to a number`}),
+	conversionBuiltin("toint", "Converts a number or a string holding a number to an integer. Strict mode fails on numbers with a fraction, which lenient mode truncates towards zero.",
+		func(config transformers.Config) Transformer { return Adapt(&transformers.ToInt{Config: config}) },
+		Example{Script: "SET age = toint(age)", Input: `{"age":"42"}`, Output: `{"age":42}`},
+		Example{Script: "SET age = toint(age, 'lenient')", Input: `{"age":42.9}`, Output: `{"age":42}`},
+		Example{Script: "SET age = toint(age)", Input: `{"age":42.9}`, Error: "cannot convert 42.9 to an integer without losing its fraction"}),
+	conversionBuiltin("tobool", "Converts a value to a boolean. Strict mode accepts booleans, 1 and 0, and the strings true, false, 1 and 0 in any case; lenient mode also accepts any number (true unless 0) and t, f, yes, no, y, n, on and off.",
+		func(config transformers.Config) Transformer { return Adapt(&transformers.ToBool{Config: config}) },
+		Example{Script: "SET active = tobool(active)", Input: `{"active":1}`, Output: `{"active":true}`},
+		Example{Script: "SET smoker = tobool(smoker, 'lenient')", Input: `{"smoker":"No"}`, Output: `{"smoker":false}`},
+		Example{Script: "SET smoker = tobool(smoker)", Input: `{"smoker":"No"}`, Error: `cannot convert "No" to a boolean`}),
+	conversionBuiltin("tostring", "Converts a string, number or boolean to a string. Lenient mode also writes arrays and objects as their JSON text.",
+		func(config transformers.Config) Transformer { return Adapt(&transformers.ToString{Config: config}) },
+		Example{Script: "SET zip = tostring(zip)", Input: `{"zip":2108}`, Output: `{"zip":"2108"}`},
+		Example{Script: "SET tags = tostring(tags, 'lenient')", Input: `{"tags":["a","b"]}`, Output: `{"tags":"[\"a\",\"b\"]"}`},
+		Example{Script: "SET tags = tostring(tags)", Input: `{"tags":["a","b"]}`, Error: `cannot convert ["a","b"] to a string`}),
+	{
+		signature: Signature{
+			Name:        "toarray",
+			Description: "Wraps a value in an array. Arrays are returned unchanged and null becomes an empty array.",
+			Params: []Param{
+				{Name: "value", Kind: AnyParam, Type: "any"},
+			},
+			Outputs: []string{"result"},
+			Examples: []Example{
+				{
+					Script: "SET phones = toarray(phones)",
+					Input:  `{"phones":"555-0100"}`,
+					Output: `{"phones":["555-0100"]}`,
+				},
+				{
+					Script: "SET phones = toarray(phones)",
+					Input:  `{"phones":null}`,
+					Output: `{"phones":[]}`,
+				},
+			},
+		},
+		factory: func(config transformers.Config) Transformer { return Adapt(&transformers.ToArray{Config: config}) },
+	},
+	{
+		signature: Signature{
+			Name:        "typeof",
+			Description: "Returns the JSON type of a field: string, number, boolean, null, array or object, or missing when it does not exist.",
+			Params: []Param{
+				{Name: "value", Kind: FieldParam, Type: "any"},
+			},
+			Outputs: []string{"type"},
+			Examples: []Example{
+				{
+					Script: "SET weightType = typeof(weight)",
+					Input:  `{"weight":"75"}`,
+					Output: `{"weight":"75","weightType":"string"}`,
+				},
+				{
+					Script: "SET weightType = typeof(weight)",
+					Input:  `{}`,
+					Output: `{"weightType":"missing"}`,
+				},
+			},
+		},
+		factory: func(config transformers.Config) Transformer { return Adapt(&transformers.TypeOf{Config: config}) },
+	},
+	{
+		signature: Signature{
+			Name:        "isnull",
+			Description: "Returns whether a field is null or missing.",
+			Params: []Param{
+				{Name: "value", Kind: FieldParam, Type: "any"},
+			},
+			Outputs: []string{"result"},
+			Examples: []Example{
+				{
+					Script: "SET noEmail = isnull(email)",
+					Input:  `{"email":null}`,
+					Output: `{"email":null,"noEmail":true}`,
+				},
+			},
+		},
+		factory: func(config transformers.Config) Transformer { return Adapt(&transformers.IsNull{Config: config}) },
+	},
+}
+
+// conversionBuiltin registers a conversion taking a value and an optional mode
+func conversionBuiltin(name, description string, factory Factory, examples ...Example) registration {
+	return registration{
+		signature: Signature{
+			Name:        name,
+			Description: description,
+			Params: []Param{
+				{Name: "value", Kind: AnyParam, Type: "any"},
+				{Name: "mode", Kind: LiteralParam, Type: "string", Optional: true},
+			},
+			Outputs:  []string{"result"},
+			Examples: examples,
+		},
+		factory: factory,
+	}
+}
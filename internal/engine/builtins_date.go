@@ -0,0 +1,194 @@
+package engine
+
+import (
+	"time"
+
+	"github.com/codeis4fun/data-treatment-interpreter/internal/transformers"
+)
+
+// WithClock sets the clock read by now and age, so executions can be reproduced in tests
+func WithClock(clock func() time.Time) Option {
+	return func(e *Engine) {
+		e.clock = clock
+	}
+}
+
+// dateBuiltins are the date and time transformers. Dates are read from RFC 3339 strings (with or without
+// a zone or time), from Unix epochs in seconds or milliseconds, or with an explicit layout, and are
+// returned in RFC 3339
+var dateBuiltins = []registration{
+	{
+		signature: Signature{
+			Name:        "parsedate",
+			Description: "Reads a date with a layout (strftime such as '%d/%m/%Y', a Go layout, rfc3339, unix or unixmilli) and returns it in RFC 3339. Without a layout, RFC 3339 and epochs are recognised. Dates without a zone are in the given time zone, UTC by default.",
+			Params: []Param{
+				{Name: "value", Kind: FieldParam, Type: "any"},
+				{Name: "layout", Kind: LiteralParam, Type: "string", Optional: true},
+				{Name: "timezone", Kind: LiteralParam, Type: "string", Optional: true},
+			},
+			Outputs: []string{"date"},
+			Examples: []Example{
+				{
+					Script: "SET createdAt = parsedate(createdAt, '%d/%m/%Y %H:%M', 'Europe/Lisbon')",
+					Input:  `{"createdAt":"15/07/2024 09:30"}`,
+					Output: `{"createdAt":"2024-07-15T09:30:00+01:00"}`,
+				},
+				{
+					Script: "SET createdAt = parsedate(createdAt)",
+					Input:  `{"createdAt":1721032200000}`,
+					Output: `{"createdAt":"2024-07-15T08:30:00Z"}`,
+				},
+				{
+					Script: "SET createdAt = parsedate(createdAt, '%d/%m/%Y')",
+					Input:  `{"createdAt":"2024-07-15"}`,
+					Error:  "cannot parse date '2024-07-15' with layout '%d/%m/%Y'",
+				},
+			},
+		},
+		factory: func(config transformers.Config) Transformer { return Adapt(&transformers.ParseDate{Config: config}) },
+	},
+	{
+		signature: Signature{
+			Name:        "formatdate",
+			Description: "Formats a date with a layout (strftime, a Go layout, rfc3339, unix or unixmilli), optionally in another time zone. Epoch layouts return numbers.",
+			Params: []Param{
+				{Name: "value", Kind: FieldParam, Type: "any"},
+				{Name: "layout", Kind: LiteralParam, Type: "string"},
+				{Name: "timezone", Kind: LiteralParam, Type: "string", Optional: true},
+			},
+			Outputs: []string{"result"},
+			Examples: []Example{
+				{
+					Script: "SET day = formatdate(createdAt, '%A %d %B %Y, %H:%M', 'America/Sao_Paulo')",
+					Input:  `{"createdAt":"2024-07-15T08:30:00Z"}`,
+					Output: `{"createdAt":"2024-07-15T08:30:00Z","day":"Monday 15 July 2024, 05:30"}`,
+				},
+				{
+					Script: "SET epoch = formatdate(createdAt, 'unix')",
+					Input:  `{"createdAt":"2024-07-15T08:30:00Z"}`,
+					Output: `{"createdAt":"2024-07-15T08:30:00Z","epoch":1721032200}`,
+				},
+				{
+					Script: "SET day = formatdate(createdAt, '%Q')",
+					Input:  `{"createdAt":"2024-07-15T08:30:00Z"}`,
+					Error:  "unsupported directive '%Q'",
+				},
+			},
+		},
+		factory: func(config transformers.Config) Transformer { return Adapt(&transformers.FormatDate{Config: config}) },
+	},
+	{
+		signature: Signature{
+			Name:        "now",
+			Description: "Returns the current time in RFC 3339, in UTC.",
+			Outputs:     []string{"date"},
+			Examples: []Example{
+				{
+					Script: "SET processedAt = now()",
+					Input:  `{}`,
+					Output: `{"processedAt":"2024-07-15T08:30:00Z"}`,
+					Now:    "2024-07-15T08:30:00Z",
+				},
+			},
+		},
+		factory: func(config transformers.Config) Transformer { return Adapt(&transformers.Now{Config: config}) },
+	},
+	{
+		signature: Signature{
+			Name:        "dateadd",
+			Description: "Adds a period such as '3d', '-1y6mo' or '1h30m' to a date. Years (y), months (mo), weeks (w) and days (d) follow the calendar, ending on the last day of shorter months; hours (h), minutes (m), seconds (s) and milliseconds (ms) are exact.",
+			Params: []Param{
+				{Name: "value", Kind: FieldParam, Type: "any"},
+				{Name: "period", Kind: LiteralParam, Type: "string"},
+			},
+			Outputs: []string{"date"},
+			Examples: []Example{
+				{
+					Script: "SET dueAt = dateadd(issuedAt, '1mo15d')",
+					Input:  `{"issuedAt":"2024-01-31"}`,
+					Output: `{"issuedAt":"2024-01-31","dueAt":"2024-03-15T00:00:00Z"}`,
+				},
+				{
+					Script: "SET dueAt = dateadd(issuedAt, '3q')",
+					Input:  `{"issuedAt":"2024-01-31"}`,
+					Error:  "unknown unit 'q'",
+				},
+			},
+		},
+		factory: func(config transformers.Config) Transformer { return Adapt(&transformers.DateAdd{Config: config}) },
+	},
+	{
+		signature: Signature{
+			Name:        "datediff",
+			Description: "Counts the whole units (milliseconds, seconds, minutes, hours, days, weeks, months or years) from one date to another, negative when the second is earlier.",
+			Params: []Param{
+				{Name: "start", Kind: FieldParam, Type: "any"},
+				{Name: "end", Kind: FieldParam, Type: "any"},
+				{Name: "unit", Kind: LiteralParam, Type: "string"},
+			},
+			Outputs: []string{"count"},
+			Examples: []Example{
+				{
+					Script: "SET stayDays = datediff(admittedAt, dischargedAt, 'days')",
+					Input:  `{"admittedAt":"2024-07-01T22:00:00Z","dischargedAt":"2024-07-04T10:00:00Z"}`,
+					Output: `{"admittedAt":"2024-07-01T22:00:00Z","dischargedAt":"2024-07-04T10:00:00Z","stayDays":2}`,
+				},
+				{
+					Script: "SET tenure = datediff(hiredAt, leftAt, 'months')",
+					Input:  `{"hiredAt":"2023-01-31","leftAt":"2024-02-29"}`,
+					Output: `{"hiredAt":"2023-01-31","leftAt":"2024-02-29","tenure":13}`,
+				},
+			},
+		},
+		factory: func(config transformers.Config) Transformer { return Adapt(&transformers.DateDiff{Config: config}) },
+	},
+	{
+		signature: Signature{
+			Name:        "age",
+			Description: "Counts the whole years from a birth date to another date, or to now.",
+			Params: []Param{
+				{Name: "birthDate", Kind: FieldParam, Type: "any"},
+				{Name: "on", Kind: FieldParam, Type: "any", Optional: true},
+			},
+			Outputs: []string{"years"},
+			Examples: []Example{
+				{
+					Script: "SET age = age(birthDate)",
+					Input:  `{"birthDate":"1990-07-16"}`,
+					Output: `{"birthDate":"1990-07-16","age":33}`,
+					Now:    "2024-07-15T08:30:00Z",
+				},
+				{
+					Script: "SET ageAtAdmission = age(birthDate, admittedAt)",
+					Input:  `{"birthDate":"1990-07-16","admittedAt":"2020-07-16T10:00:00Z"}`,
+					Output: `{"birthDate":"1990-07-16","admittedAt":"2020-07-16T10:00:00Z","ageAtAdmission":30}`,
+				},
+			},
+		},
+		factory: func(config transformers.Config) Transformer { return Adapt(&transformers.Age{Config: config}) },
+	},
+	{
+		signature: Signature{
+			Name:        "totimezone",
+			Description: "Converts a date to a time zone (an IANA name such as 'Europe/Lisbon'), returning the same instant in RFC 3339.",
+			Params: []Param{
+				{Name: "value", Kind: FieldParam, Type: "any"},
+				{Name: "timezone", Kind: LiteralParam, Type: "string"},
+			},
+			Outputs: []string{"date"},
+			Examples: []Example{
+				{
+					Script: "SET localTime = totimezone(createdAt, 'Asia/Tokyo')",
+					Input:  `{"createdAt":"2024-07-15T20:30:00Z"}`,
+					Output: `{"createdAt":"2024-07-15T20:30:00Z","localTime":"2024-07-16T05:30:00+09:00"}`,
+				},
+				{
+					Script: "SET localTime = totimezone(createdAt, 'Mars/Olympus')",
+					Input:  `{"createdAt":"2024-07-15T20:30:00Z"}`,
+					Error:  "unknown time zone 'Mars/Olympus'",
+				},
+			},
+		},
+		factory: func(config transformers.Config) Transformer { return Adapt(&transformers.ToTimezone{Config: config}) },
+	},
+}
@@ -0,0 +1,64 @@
+package engine
+
+import "github.com/codeis4fun/data-treatment-interpreter/internal/transformers"
+
+// WithKeyring registers encrypt and decrypt, which encrypt fields with the keys of the given keyring (see
+// transformers.ParseKeyring). Scripts only name the keys, so the transformers only exist on engines given one
+func WithKeyring(keyring *transformers.Keyring) Option {
+	return func(e *Engine) {
+		e.Register(encryptSignature, func(config transformers.Config) Transformer {
+			return Adapt(&transformers.Encrypt{Config: config, Keyring: keyring})
+		})
+		e.Register(decryptSignature, func(config transformers.Config) Transformer {
+			return Adapt(&transformers.Decrypt{Config: config, Keyring: keyring})
+		})
+	}
+}
+
+// encryptSignature is the signature of encrypt, registered by WithKeyring
+var encryptSignature = Signature{
+	Name:        "encrypt",
+	Description: "Encrypts a value with AES-GCM and the key of the keyring with the given id, returning an envelope naming the key and the nonce (enc:v1:<key id>:<nonce>:<ciphertext>). Encrypting the same value twice gives different envelopes.",
+	Params: []Param{
+		{Name: "value", Kind: FieldParam, Type: "any"},
+		{Name: "keyId", Kind: LiteralParam, Type: "string"},
+	},
+	Outputs:  []string{"encrypted"},
+	Requires: "engine.WithKeyring (--keyring)",
+	Examples: []Example{
+		{
+			Script: "SET _ssn = encrypt(ssn, '2026-01')\nSET restored = decrypt(_ssn)",
+			Input:  `{"ssn":"123-45-6789"}`,
+			Output: `{"ssn":"123-45-6789","restored":"123-45-6789"}`,
+		},
+		{
+			Script: "SET ssn = encrypt(ssn, '2025-01')",
+			Input:  `{"ssn":"123-45-6789"}`,
+			Error:  "unknown key '2025-01'",
+		},
+	},
+}
+
+// decryptSignature is the signature of decrypt, registered by WithKeyring
+var decryptSignature = Signature{
+	Name:        "decrypt",
+	Description: "Decrypts an envelope written by encrypt with the key it names, restoring the JSON type of the value. When a key id is given, envelopes naming another key are rejected.",
+	Params: []Param{
+		{Name: "value", Kind: FieldParam, Type: "string"},
+		{Name: "keyId", Kind: LiteralParam, Type: "string", Optional: true},
+	},
+	Outputs:  []string{"decrypted"},
+	Requires: "engine.WithKeyring (--keyring)",
+	Examples: []Example{
+		{
+			Script: "SET _total = encrypt(total, '2026-01')\nSET restored = decrypt(_total, '2026-01')",
+			Input:  `{"total":12.5}`,
+			Output: `{"total":12.5,"restored":12.5}`,
+		},
+		{
+			Script: "SET ssn = decrypt(ssn)",
+			Input:  `{"ssn":"123-45-6789"}`,
+			Error:  "argument 'ssn' is not an encrypted value",
+		},
+	},
+}
@@ -0,0 +1,190 @@
+package engine
+
+import "github.com/codeis4fun/data-treatment-interpreter/internal/transformers"
+
+// hashBuiltins are the hashing, checksum and encoding transformers. They read the text of strings and the
+// canonical JSON of objects and arrays (compact, with sorted keys), so equal documents hash the same
+var hashBuiltins = []registration{
+	hashBuiltin("md5", "Returns the MD5 digest of a value as hexadecimal. MD5 is broken for security purposes; use it only for checksums and existing identifiers.",
+		Example{Script: "SET emailId = md5(email)", Input: `{"email":"john.doe@example.com"}`, Output: `{"email":"john.doe@example.com","emailId":"8eb1b522f60d11fa897de1dc6351b7e8"}`}),
+	hashBuiltin("sha1", "Returns the SHA-1 digest of a value as hexadecimal. SHA-1 is broken for security purposes; use it only for checksums and existing identifiers.",
+		Example{Script: "SET emailId = sha1(email)", Input: `{"email":"john.doe@example.com"}`, Output: `{"email":"john.doe@example.com","emailId":"73ec53c4ba1747d485ae2a0d7bfafa6cda80a5a9"}`}),
+	hashBuiltin("sha256", "Returns the SHA-256 digest of a value as hexadecimal.",
+		Example{Script: "SET emailId = sha256(email)", Input: `{"email":"john.doe@example.com"}`, Output: `{"email":"john.doe@example.com","emailId":"836f82db99121b3481011f16b49dfa5fbc714a0d1b1b9f784a1ebbbf5b39577f"}`},
+		Example{Script: "SET addressId = sha256(address)", Input: `{"address":{ "zip": "1100", "city": "Lisbon" }}`, Output: `{"address":{ "zip": "1100", "city": "Lisbon" },"addressId":"401b9af9af4ff4785f0c8366c7ef7a5517abf64d42eacdbb38924eb3bace43a4"}`}),
+	hashBuiltin("sha512", "Returns the SHA-512 digest of a value as hexadecimal.",
+		Example{Script: "SET emailId = sha512(email)", Input: `{"email":"john.doe@example.com"}`, Output: `{"email":"john.doe@example.com","emailId":"17f3550769bc531af50ccac6e35a21dc21dc1c45302d4ca591553765bfcbde52c8b0836db697a486895988460f7cc281319bbde25c44888a3009502ca59c6728"}`}),
+	{
+		signature: Signature{
+			Name:        "hmac",
+			Description: "Returns the HMAC of a value with a key, usually a $parameter, as hexadecimal. The hash function is sha256 unless md5, sha1 or sha512 is given.",
+			Params: []Param{
+				{Name: "value", Kind: AnyParam, Type: "any"},
+				{Name: "key", Kind: LiteralParam, Type: "string", Secret: true},
+				{Name: "algorithm", Kind: LiteralParam, Type: "string", Optional: true},
+			},
+			Outputs: []string{"result"},
+			Examples: []Example{
+				{
+					Script: "SET patientId = hmac(patientId, 's3cret')",
+					Input:  `{"patientId":12345}`,
+					Output: `{"patientId":"672b04fffe37d7340773aec34f538dce4d75a078cf87db130e54d794ed677d4a"}`,
+				},
+				{
+					Script: "SET patientId = hmac(patientId, 's3cret', 'sha3')",
+					Input:  `{"patientId":12345}`,
+					Error:  "unknown hash algorithm 'sha3' (expected md5, sha1, sha256 or sha512)",
+				},
+			},
+		},
+		factory: func(config transformers.Config) Transformer { return Adapt(&transformers.HMAC{Config: config}) },
+	},
+	{
+		signature: Signature{
+			Name:        "crc32",
+			Description: "Returns the IEEE CRC-32 checksum of a value as eight hexadecimal digits.",
+			Params: []Param{
+				{Name: "value", Kind: AnyParam, Type: "any"},
+			},
+			Outputs: []string{"result"},
+			Examples: []Example{
+				{
+					Script: "SET checksum = crc32(payload)",
+					Input:  `{"payload":"hello world"}`,
+					Output: `{"payload":"hello world","checksum":"0d4a1185"}`,
+				},
+			},
+		},
+		factory: func(config transformers.Config) Transformer { return Adapt(&transformers.CRC32{Config: config}) },
+	},
+	{
+		signature: Signature{
+			Name:        "base64encode",
+			Description: "Encodes a value in standard base64, or in the URL-safe alphabet without padding when 'url' is given.",
+			Params: []Param{
+				{Name: "value", Kind: AnyParam, Type: "any"},
+				{Name: "variant", Kind: LiteralParam, Type: "string", Optional: true},
+			},
+			Outputs: []string{"result"},
+			Examples: []Example{
+				{
+					Script: "SET token = base64encode(token)",
+					Input:  `{"token":"john:s3cret?"}`,
+					Output: `{"token":"am9objpzM2NyZXQ/"}`,
+				},
+				{
+					Script: "SET token = base64encode(token, 'url')",
+					Input:  `{"token":"john:s3cret?"}`,
+					Output: `{"token":"am9objpzM2NyZXQ_"}`,
+				},
+			},
+		},
+		factory: func(config transformers.Config) Transformer { return Adapt(&transformers.Base64Encode{Config: config}) },
+	},
+	{
+		signature: Signature{
+			Name:        "base64decode",
+			Description: "Decodes a base64 field, in the standard or the URL-safe alphabet, with or without padding. The decoded value must be UTF-8 text.",
+			Params: []Param{
+				{Name: "value", Kind: FieldParam, Type: "string"},
+			},
+			Outputs: []string{"result"},
+			Examples: []Example{
+				{
+					Script: "SET token = base64decode(token)",
+					Input:  `{"token":"am9objpzM2NyZXQ_"}`,
+					Output: `{"token":"john:s3cret?"}`,
+				},
+				{
+					Script: "SET token = base64decode(token)",
+					Input:  `{"token":"not base64!"}`,
+					Error:  "argument 'token' is not valid base64",
+				},
+			},
+		},
+		factory: func(config transformers.Config) Transformer { return Adapt(&transformers.Base64Decode{Config: config}) },
+	},
+	{
+		signature: Signature{
+			Name:        "hexencode",
+			Description: "Encodes the bytes of a value as hexadecimal.",
+			Params: []Param{
+				{Name: "value", Kind: AnyParam, Type: "any"},
+			},
+			Outputs: []string{"result"},
+			Examples: []Example{
+				{
+					Script: "SET code = hexencode(code)",
+					Input:  `{"code":"Ok!"}`,
+					Output: `{"code":"4f6b21"}`,
+				},
+			},
+		},
+		factory: func(config transformers.Config) Transformer { return Adapt(&transformers.HexEncode{Config: config}) },
+	},
+	{
+		signature: Signature{
+			Name:        "urlencode",
+			Description: "Escapes a value for a URL query string, or for a path segment when 'path' is given.",
+			Params: []Param{
+				{Name: "value", Kind: AnyParam, Type: "any"},
+				{Name: "component", Kind: LiteralParam, Type: "string", Optional: true},
+			},
+			Outputs: []string{"result"},
+			Examples: []Example{
+				{
+					Script: "SET query = urlencode(search)",
+					Input:  `{"search":"fish & chips"}`,
+					Output: `{"search":"fish & chips","query":"fish+%26+chips"}`,
+				},
+				{
+					Script: "SET segment = urlencode(search, 'path')",
+					Input:  `{"search":"fish & chips"}`,
+					Output: `{"search":"fish & chips","segment":"fish%20&%20chips"}`,
+				},
+			},
+		},
+		factory: func(config transformers.Config) Transformer { return Adapt(&transformers.URLEncode{Config: config}) },
+	},
+	{
+		signature: Signature{
+			Name:        "urldecode",
+			Description: "Unescapes a field of a URL query string, where + is a space.",
+			Params: []Param{
+				{Name: "value", Kind: FieldParam, Type: "string"},
+			},
+			Outputs: []string{"result"},
+			Examples: []Example{
+				{
+					Script: "SET search = urldecode(query)",
+					Input:  `{"query":"fish+%26+chips"}`,
+					Output: `{"query":"fish+%26+chips","search":"fish & chips"}`,
+				},
+				{
+					Script: "SET search = urldecode(query)",
+					Input:  `{"query":"100%"}`,
+					Error:  "argument 'query' is not a valid URL-encoded value",
+				},
+			},
+		},
+		factory: func(config transformers.Config) Transformer { return Adapt(&transformers.URLDecode{Config: config}) },
+	},
+}
+
+// hashBuiltin registers a transformer returning the digest of a value
+func hashBuiltin(algorithm, description string, examples ...Example) registration {
+	return registration{
+		signature: Signature{
+			Name:        algorithm,
+			Description: description,
+			Params: []Param{
+				{Name: "value", Kind: AnyParam, Type: "any"},
+			},
+			Outputs:  []string{"result"},
+			Examples: examples,
+		},
+		factory: func(config transformers.Config) Transformer {
+			return Adapt(&transformers.Hash{Config: config, Algorithm: algorithm})
+		},
+	}
+}
@@ -0,0 +1,184 @@
+package engine
+
+import "github.com/codeis4fun/data-treatment-interpreter/internal/transformers"
+
+// WithGrowthReference registers bmipercentile, which places the BMI of children on the given growth chart.
+// The charts are published by the CDC and the WHO (see transformers.ParseGrowthReference) and are not
+// bundled, so the transformer only exists on engines given one
+func WithGrowthReference(reference *transformers.GrowthReference) Option {
+	return func(e *Engine) {
+		e.Register(bmiPercentileSignature, func(config transformers.Config) Transformer {
+			return Adapt(&transformers.BMIPercentile{Config: config, Reference: reference})
+		})
+	}
+}
+
+// bmiPercentileSignature is the signature of bmipercentile, registered by WithGrowthReference
+var bmiPercentileSignature = Signature{
+	Name:        "bmipercentile",
+	Description: "Places the BMI of a child on the growth chart given to the engine, returning its z-score and its percentile for the age (in months unless 'years' is given) and sex (male or female).",
+	Params: []Param{
+		{Name: "bmi", Kind: AnyParam, Type: "number"},
+		{Name: "age", Kind: AnyParam, Type: "number"},
+		{Name: "sex", Kind: AnyParam, Type: "string"},
+		{Name: "ageUnit", Kind: LiteralParam, Type: "string", Optional: true},
+	},
+	Outputs:  []string{"zscore", "percentile"},
+	Requires: "engine.WithGrowthReference (--growth-reference)",
+	Examples: []Example{
+		{
+			Script: "SET bmiZScore, bmiPercentile = bmipercentile(bmi, age, sex)",
+			Input:  `{"bmi":17.6,"age":24,"sex":"male"}`,
+			Output: `{"bmi":17.6,"age":24,"sex":"male","bmiZScore":1,"bmiPercentile":84.1}`,
+			Note:   "On a made-up chart whose L, M and S for boys of 24 months are 1, 16 and 0.1; the published charts give other values.",
+		},
+		{
+			Script: "SET bmiZScore, bmiPercentile = bmipercentile(bmi, age, sex, 'weeks')",
+			Input:  `{"bmi":16,"age":130,"sex":"female"}`,
+			Error:  "unknown age unit 'weeks' (expected months or years)",
+		},
+	},
+}
+
+// healthBuiltins are the clinical calculators. Their measurements are in metric units unless other units
+// are given, and they return every standard formula as a separate output
+var healthBuiltins = []registration{
+	{
+		signature: Signature{
+			Name:        "bsa",
+			Description: "Calculates the body surface area in square metres with the Mosteller and DuBois formulas, from a weight and a height in kilograms and centimetres unless other units are given.",
+			Params: []Param{
+				{Name: "weight", Kind: AnyParam, Type: "number"},
+				{Name: "height", Kind: AnyParam, Type: "number"},
+				{Name: "weightUnit", Kind: LiteralParam, Type: "string", Optional: true},
+				{Name: "heightUnit", Kind: LiteralParam, Type: "string", Optional: true},
+			},
+			Outputs: []string{"mosteller", "dubois"},
+			Examples: []Example{
+				{
+					Script: "SET bsa, bsaDuBois = bsa(weight, height)",
+					Input:  `{"weight":70,"height":175}`,
+					Output: `{"weight":70,"height":175,"bsa":1.84,"bsaDuBois":1.85}`,
+				},
+				{
+					Script: "SET bsa, bsaDuBois = bsa(weight, height, 'lb', 'in')",
+					Input:  `{"weight":154,"height":69}`,
+					Output: `{"weight":154,"height":69,"bsa":1.84,"bsaDuBois":1.85}`,
+				},
+			},
+		},
+		factory: func(config transformers.Config) Transformer { return Adapt(&transformers.BSA{Config: config}) },
+	},
+	{
+		signature: Signature{
+			Name:        "bmr",
+			Description: "Calculates the basal metabolic rate in kcal per day with the Mifflin-St Jeor and the revised Harris-Benedict equations, from a weight and a height in kilograms and centimetres unless other units are given, an age in years and a sex (male or female).",
+			Params: []Param{
+				{Name: "weight", Kind: AnyParam, Type: "number"},
+				{Name: "height", Kind: AnyParam, Type: "number"},
+				{Name: "age", Kind: AnyParam, Type: "number"},
+				{Name: "sex", Kind: AnyParam, Type: "string"},
+				{Name: "weightUnit", Kind: LiteralParam, Type: "string", Optional: true},
+				{Name: "heightUnit", Kind: LiteralParam, Type: "string", Optional: true},
+			},
+			Outputs: []string{"mifflinStJeor", "harrisBenedict"},
+			Examples: []Example{
+				{
+					Script: "SET bmr, bmrHarrisBenedict = bmr(weight, height, age, sex)",
+					Input:  `{"weight":70,"height":175,"age":40,"sex":"male"}`,
+					Output: `{"weight":70,"height":175,"age":40,"sex":"male","bmr":1599,"bmrHarrisBenedict":1639}`,
+				},
+				{
+					Script: "SET bmr, bmrHarrisBenedict = bmr(weight, height, age, sex)",
+					Input:  `{"weight":70,"height":175,"age":40,"sex":"x"}`,
+					Error:  `sex must be male or female, got "x"`,
+				},
+			},
+		},
+		factory: func(config transformers.Config) Transformer { return Adapt(&transformers.BMR{Config: config}) },
+	},
+	{
+		signature: Signature{
+			Name:        "ibw",
+			Description: "Calculates the ideal body weight in kilograms of an adult with the Devine and Robinson formulas, from a height in centimetres unless another unit is given and a sex (male or female).",
+			Params: []Param{
+				{Name: "height", Kind: AnyParam, Type: "number"},
+				{Name: "sex", Kind: AnyParam, Type: "string"},
+				{Name: "heightUnit", Kind: LiteralParam, Type: "string", Optional: true},
+			},
+			Outputs: []string{"devine", "robinson"},
+			Examples: []Example{
+				{
+					Script: "SET ibw, ibwRobinson = ibw(height, sex)",
+					Input:  `{"height":175,"sex":"male"}`,
+					Output: `{"height":175,"sex":"male","ibw":70.5,"ibwRobinson":68.9}`,
+				},
+				{
+					Script: "SET ibw, ibwRobinson = ibw(height, sex, 'in')",
+					Input:  `{"height":69,"sex":"F"}`,
+					Output: `{"height":69,"sex":"F","ibw":66.2,"ibwRobinson":64.3}`,
+				},
+			},
+		},
+		factory: func(config transformers.Config) Transformer { return Adapt(&transformers.IdealWeight{Config: config}) },
+	},
+	{
+		signature: Signature{
+			Name:        "whtr",
+			Description: "Calculates the waist-to-height ratio from a waist and a height in centimetres unless other units are given, and whether it is 0.5 or more, from where health risks increase.",
+			Params: []Param{
+				{Name: "waist", Kind: AnyParam, Type: "number"},
+				{Name: "height", Kind: AnyParam, Type: "number"},
+				{Name: "waistUnit", Kind: LiteralParam, Type: "string", Optional: true},
+				{Name: "heightUnit", Kind: LiteralParam, Type: "string", Optional: true},
+			},
+			Outputs: []string{"ratio", "isAtRisk"},
+			Examples: []Example{
+				{
+					Script: "SET whtr, isAtRisk = whtr(waist, height)",
+					Input:  `{"waist":90,"height":175}`,
+					Output: `{"waist":90,"height":175,"whtr":0.51,"isAtRisk":true}`,
+				},
+				{
+					Script: "SET whtr, isAtRisk = whtr(waist, height, 'in', 'cm')",
+					Input:  `{"waist":32,"height":175}`,
+					Output: `{"waist":32,"height":175,"whtr":0.46,"isAtRisk":false}`,
+				},
+			},
+		},
+		factory: func(config transformers.Config) Transformer {
+			return Adapt(&transformers.WaistToHeight{Config: config})
+		},
+	},
+	{
+		signature: Signature{
+			Name:        "egfr",
+			Description: "Estimates the glomerular filtration rate in mL/min/1.73m² with the race-free CKD-EPI 2021 creatinine equation, from a serum creatinine in mg/dL unless 'umol/l' is given, an age in years and a sex (male or female), and returns its KDIGO category (G1, G2, G3a, G3b, G4 or G5).",
+			Params: []Param{
+				{Name: "creatinine", Kind: AnyParam, Type: "number"},
+				{Name: "age", Kind: AnyParam, Type: "number"},
+				{Name: "sex", Kind: AnyParam, Type: "string"},
+				{Name: "creatinineUnit", Kind: LiteralParam, Type: "string", Optional: true},
+			},
+			Outputs: []string{"egfr", "category"},
+			Examples: []Example{
+				{
+					Script: "SET egfr, ckdStage = egfr(creatinine, age, sex)",
+					Input:  `{"creatinine":1.1,"age":60,"sex":"male"}`,
+					Output: `{"creatinine":1.1,"age":60,"sex":"male","egfr":77,"ckdStage":"G2"}`,
+				},
+				{
+					Script: "SET egfr, ckdStage = egfr(creatinine, age, sex, 'umol/l')",
+					Input:  `{"creatinine":97,"age":60,"sex":"female"}`,
+					Output: `{"creatinine":97,"age":60,"sex":"female","egfr":58,"ckdStage":"G3a"}`,
+				},
+				{
+					Script: "SET egfr, ckdStage = egfr(creatinine, age, sex)",
+					Input:  `{"creatinine":0,"age":60,"sex":"male"}`,
+					Error:  "creatinine must be greater than 0, got 0",
+				},
+			},
+		},
+		factory: func(config transformers.Config) Transformer { return Adapt(&transformers.EGFR{Config: config}) },
+	},
+}
@@ -0,0 +1,188 @@
+package engine
+
+import "github.com/codeis4fun/data-treatment-interpreter/internal/transformers"
+
+// mathBuiltins are the numeric transformers. Numbers are read from JSON numbers or from strings holding
+// a number, and computed as exact decimals, so money never picks up floating point errors
+var mathBuiltins = []registration{
+	{
+		signature: Signature{
+			Name:        "round",
+			Description: "Rounds a number to a number of decimal places (0 by default, negative for tens, hundreds, ...). Halves are rounded away from zero unless a mode is given: half-up, half-even, floor, ceil or truncate.",
+			Params: []Param{
+				{Name: "value", Kind: AnyParam, Type: "number"},
+				{Name: "digits", Kind: LiteralParam, Type: "integer", Optional: true},
+				{Name: "mode", Kind: LiteralParam, Type: "string", Optional: true},
+			},
+			Outputs: []string{"result"},
+			Examples: []Example{
+				{
+					Script: "SET price = round(price, '2')",
+					Input:  `{"price":"19.995"}`,
+					Output: `{"price":20}`,
+				},
+				{
+					Script: "SET total = round(total, '1', 'half-even')",
+					Input:  `{"total":2.25}`,
+					Output: `{"total":2.2}`,
+				},
+				{
+					Script: "SET population = round(population, '-3')",
+					Input:  `{"population":504871}`,
+					Output: `{"population":505000}`,
+				},
+				{
+					Script: "SET price = round(price, '2')",
+					Input:  `{"price":"n/a"}`,
+					Error:  `argument 'price' is not a number: "n/a"`,
+				},
+			},
+		},
+		factory: func(config transformers.Config) Transformer {
+			return Adapt(&transformers.Round{Config: config, Mode: transformers.HalfUp})
+		},
+	},
+	roundingBuiltin("floor", "Rounds a number down, towards negative infinity, to a number of decimal places (0 by default).", transformers.Floor,
+		Example{Script: "SET temperature = floor(temperature, '1')", Input: `{"temperature":-3.45}`, Output: `{"temperature":-3.5}`}),
+	roundingBuiltin("ceil", "Rounds a number up, towards positive infinity, to a number of decimal places (0 by default).", transformers.Ceil,
+		Example{Script: "SET boxes = ceil(boxes)", Input: `{"boxes":4.1}`, Output: `{"boxes":5}`}),
+	{
+		signature: Signature{
+			Name:        "abs",
+			Description: "Returns the absolute value of a number.",
+			Params: []Param{
+				{Name: "value", Kind: AnyParam, Type: "number"},
+			},
+			Outputs: []string{"result"},
+			Examples: []Example{
+				{
+					Script: "SET delta = abs(delta)",
+					Input:  `{"delta":-12.5}`,
+					Output: `{"delta":12.5}`,
+				},
+			},
+		},
+		factory: func(config transformers.Config) Transformer { return Adapt(&transformers.Abs{Config: config}) },
+	},
+	extremeBuiltin("min", "Returns the smallest of the numbers.", false,
+		Example{Script: "SET lowest = min(q1, q2, q3)", Input: `{"q1":12,"q2":"9.5","q3":11}`, Output: `{"q1":12,"q2":"9.5","q3":11,"lowest":9.5}`}),
+	extremeBuiltin("max", "Returns the largest of the numbers.", true,
+		Example{Script: "SET score = max(score, '0')", Input: `{"score":-4}`, Output: `{"score":0}`}),
+	{
+		signature: Signature{
+			Name:        "clamp",
+			Description: "Restricts a number to a range: smaller numbers become the lower bound and larger ones the upper bound.",
+			Params: []Param{
+				{Name: "value", Kind: AnyParam, Type: "number"},
+				{Name: "lower", Kind: AnyParam, Type: "number"},
+				{Name: "upper", Kind: AnyParam, Type: "number"},
+			},
+			Outputs: []string{"result"},
+			Examples: []Example{
+				{
+					Script: "SET discount = clamp(discount, '0', '50')",
+					Input:  `{"discount":75}`,
+					Output: `{"discount":50}`,
+				},
+				{
+					Script: "SET discount = clamp(discount, '50', '0')",
+					Input:  `{"discount":75}`,
+					Error:  "lower bound 50 is greater than upper bound 0",
+				},
+			},
+		},
+		factory: func(config transformers.Config) Transformer { return Adapt(&transformers.Clamp{Config: config}) },
+	},
+	{
+		signature: Signature{
+			Name:        "percent",
+			Description: "Returns the percentage a part is of a total, rounded half-up to a number of decimal places (2 by default).",
+			Params: []Param{
+				{Name: "part", Kind: AnyParam, Type: "number"},
+				{Name: "total", Kind: AnyParam, Type: "number"},
+				{Name: "digits", Kind: LiteralParam, Type: "integer", Optional: true},
+			},
+			Outputs: []string{"percent"},
+			Examples: []Example{
+				{
+					Script: "SET completion = percent(done, total)",
+					Input:  `{"done":1,"total":3}`,
+					Output: `{"done":1,"total":3,"completion":33.33}`,
+				},
+				{
+					Script: "SET completion = percent(done, total)",
+					Input:  `{"done":1,"total":0}`,
+					Error:  "division by zero: 'total' is 0",
+				},
+			},
+		},
+		factory: func(config transformers.Config) Transformer { return Adapt(&transformers.Percent{Config: config}) },
+	},
+	arithmeticBuiltin(transformers.Add, "Adds two or more numbers exactly.",
+		Example{Script: "SET total = add(subtotal, shipping, tax)", Input: `{"subtotal":"0.1","shipping":0.2,"tax":0}`, Output: `{"subtotal":"0.1","shipping":0.2,"tax":0,"total":0.3}`}),
+	arithmeticBuiltin(transformers.Subtract, "Subtracts the other numbers from the first one exactly.",
+		Example{Script: "SET balance = subtract(balance, withdrawal)", Input: `{"balance":1.1,"withdrawal":1}`, Output: `{"balance":0.1,"withdrawal":1}`}),
+	arithmeticBuiltin(transformers.Multiply, "Multiplies two or more numbers exactly.",
+		Example{Script: "SET lineTotal = multiply(price, quantity)", Input: `{"price":19.99,"quantity":3}`, Output: `{"price":19.99,"quantity":3,"lineTotal":59.97}`}),
+	arithmeticBuiltin(transformers.Divide, "Divides the first number by the others. Results that cannot be written exactly, such as 1/3, are rounded half-up to 10 decimal places.",
+		Example{Script: "SET share = divide(amount, people)", Input: `{"amount":100,"people":3}`, Output: `{"amount":100,"people":3,"share":33.3333333333}`},
+		Example{Script: "SET share = divide(amount, people)", Input: `{"amount":100,"people":0}`, Error: "division by zero: 'people' is 0"}),
+}
+
+// roundingBuiltin registers a transformer rounding numbers with a fixed mode
+func roundingBuiltin(name, description string, mode transformers.RoundingMode, examples ...Example) registration {
+	return registration{
+		signature: Signature{
+			Name:        name,
+			Description: description,
+			Params: []Param{
+				{Name: "value", Kind: AnyParam, Type: "number"},
+				{Name: "digits", Kind: LiteralParam, Type: "integer", Optional: true},
+			},
+			Outputs:  []string{"result"},
+			Examples: examples,
+		},
+		factory: func(config transformers.Config) Transformer {
+			return Adapt(&transformers.Round{Config: config, Mode: mode})
+		},
+	}
+}
+
+// extremeBuiltin registers min or max
+func extremeBuiltin(name, description string, largest bool, examples ...Example) registration {
+	return registration{
+		signature: Signature{
+			Name:        name,
+			Description: description,
+			Params: []Param{
+				{Name: "values", Kind: AnyParam, Type: "number"},
+			},
+			Variadic: true,
+			Outputs:  []string{"result"},
+			Examples: examples,
+		},
+		factory: func(config transformers.Config) Transformer {
+			return Adapt(&transformers.Extreme{Config: config, Max: largest})
+		},
+	}
+}
+
+// arithmeticBuiltin registers an exact arithmetic operation over two or more numbers
+func arithmeticBuiltin(operator transformers.Operator, description string, examples ...Example) registration {
+	return registration{
+		signature: Signature{
+			Name:        string(operator),
+			Description: description,
+			Params: []Param{
+				{Name: "first", Kind: AnyParam, Type: "number"},
+				{Name: "rest", Kind: AnyParam, Type: "number"},
+			},
+			Variadic: true,
+			Outputs:  []string{"result"},
+			Examples: examples,
+		},
+		factory: func(config transformers.Config) Transformer {
+			return Adapt(&transformers.Arithmetic{Config: config, Operator: operator})
+		},
+	}
+}
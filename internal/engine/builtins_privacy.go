@@ -0,0 +1,145 @@
+package engine
+
+import "github.com/codeis4fun/data-treatment-interpreter/internal/transformers"
+
+// privacyBuiltins are the masking, redaction and pseudonymization transformers. Keyed transformers take
+// their key as a literal, which scripts should pass as a $parameter rather than write down
+var privacyBuiltins = []registration{
+	{
+		signature: Signature{
+			Name:        "redact",
+			Description: "Replaces a value with [REDACTED], or with the given replacement. The REDACT statement applies it to a path, skipping missing fields.",
+			Params: []Param{
+				{Name: "value", Kind: FieldParam, Type: "any"},
+				{Name: "replacement", Kind: LiteralParam, Type: "string", Optional: true},
+			},
+			Outputs: []string{"result"},
+			Examples: []Example{
+				{
+					Script: "SET ssn = redact(ssn)",
+					Input:  `{"ssn":"078-05-1120"}`,
+					Output: `{"ssn":"[REDACTED]"}`,
+				},
+				{
+					Script: "REDACT friends.#.name",
+					Input:  `{"friends":[{"name":"Alice"},{"nickname":"Bob"}]}`,
+					Output: `{"friends":[{"name":"[REDACTED]"},{"nickname":"Bob"}]}`,
+				},
+			},
+		},
+		factory: func(config transformers.Config) Transformer { return Adapt(&transformers.Redact{Config: config}) },
+	},
+	{
+		signature: Signature{
+			Name:        "mask",
+			Description: "Replaces every character of a field but the last ones (4 by default) with *, or with the given character.",
+			Params: []Param{
+				{Name: "value", Kind: FieldParam, Type: "string"},
+				{Name: "keepLast", Kind: LiteralParam, Type: "integer", Optional: true},
+				{Name: "character", Kind: LiteralParam, Type: "string", Optional: true},
+			},
+			Outputs: []string{"result"},
+			Examples: []Example{
+				{
+					Script: "SET card = mask(card)",
+					Input:  `{"card":"4111111111111111"}`,
+					Output: `{"card":"************1111"}`,
+				},
+				{
+					Script: "SET iban = mask(iban, '2', '#')",
+					Input:  `{"iban":"PT50000201231234567890154"}`,
+					Output: `{"iban":"#######################54"}`,
+				},
+			},
+		},
+		factory: func(config transformers.Config) Transformer { return Adapt(&transformers.Mask{Config: config}) },
+	},
+	{
+		signature: Signature{
+			Name:        "maskemail",
+			Description: "Masks the local part of an email address but its first character, keeping the domain.",
+			Params: []Param{
+				{Name: "value", Kind: FieldParam, Type: "string"},
+			},
+			Outputs: []string{"result"},
+			Examples: []Example{
+				{
+					Script: "SET email = maskemail(email)",
+					Input:  `{"email":"john.doe@example.com"}`,
+					Output: `{"email":"j*******@example.com"}`,
+				},
+				{
+					Script: "SET email = maskemail(email)",
+					Input:  `{"email":"john.doe"}`,
+					Error:  "value 'john.doe' is not an email address",
+				},
+			},
+		},
+		factory: func(config transformers.Config) Transformer { return Adapt(&transformers.MaskEmail{Config: config}) },
+	},
+	{
+		signature: Signature{
+			Name:        "maskphone",
+			Description: "Masks every digit of a phone number but the last ones (4 by default), keeping its formatting.",
+			Params: []Param{
+				{Name: "value", Kind: FieldParam, Type: "string"},
+				{Name: "keepLast", Kind: LiteralParam, Type: "integer", Optional: true},
+			},
+			Outputs: []string{"result"},
+			Examples: []Example{
+				{
+					Script: "SET phone = maskphone(phone)",
+					Input:  `{"phone":"+1 (555) 123-4567"}`,
+					Output: `{"phone":"+* (***) ***-4567"}`,
+				},
+			},
+		},
+		factory: func(config transformers.Config) Transformer { return Adapt(&transformers.MaskPhone{Config: config}) },
+	},
+	{
+		signature: Signature{
+			Name:        "pseudonymize",
+			Description: "Replaces a value with a token: its HMAC-SHA256 with the key, truncated to 128 bits, in hexadecimal after the optional prefix. The same value and key always give the same token, so records can still be joined, but the value cannot be recovered without the key.",
+			Params: []Param{
+				{Name: "value", Kind: AnyParam, Type: "any"},
+				{Name: "key", Kind: LiteralParam, Type: "string", Secret: true},
+				{Name: "prefix", Kind: LiteralParam, Type: "string", Optional: true},
+			},
+			Outputs: []string{"token"},
+			Examples: []Example{
+				{
+					Script: "SET userId = pseudonymize(email, 's3cret', 'usr_')",
+					Input:  `{"email":"john.doe@example.com"}`,
+					Output: `{"email":"john.doe@example.com","userId":"usr_73a27700e829713f632a46c336997a37"}`,
+				},
+				{
+					Script: "SET userId = pseudonymize(email, '')",
+					Input:  `{"email":"john.doe@example.com"}`,
+					Error:  "pseudonymize key must not be empty",
+				},
+			},
+		},
+		factory: func(config transformers.Config) Transformer { return Adapt(&transformers.Pseudonymize{Config: config}) },
+	},
+	{
+		signature: Signature{
+			Name:        "fpmask",
+			Description: "Replaces every digit with a digit and every ASCII letter with a letter of the same case, derived from the value and the key, keeping the other characters, so the result keeps the format of the value. The same value and key always give the same result. It is a one-way mask, not encryption.",
+			Params: []Param{
+				{Name: "value", Kind: FieldParam, Type: "string"},
+				{Name: "key", Kind: LiteralParam, Type: "string", Secret: true},
+			},
+			Outputs: []string{"result"},
+			Examples: []Example{
+				{
+					Script: "SET card = fpmask(card, 's3cret')",
+					Input:  `{"card":"4111-1111-1111-1111"}`,
+					Output: `{"card":"1967-1128-7280-0047"}`,
+				},
+			},
+		},
+		factory: func(config transformers.Config) Transformer {
+			return Adapt(&transformers.FormatPreservingMask{Config: config})
+		},
+	},
+}
@@ -0,0 +1,92 @@
+package engine
+
+import "github.com/codeis4fun/data-treatment-interpreter/internal/transformers"
+
+// regexBuiltins are the regular expression transformers. Patterns use the RE2 syntax of Go's regexp
+// package; literal patterns are checked when the script is vetted
+var regexBuiltins = []registration{
+	{
+		signature: Signature{
+			Name:        "match",
+			Description: "Tells whether the value of a field matches a regular expression anywhere; anchor it with ^ and $ to match the whole value.",
+			Params: []Param{
+				{Name: "value", Kind: FieldParam, Type: "string"},
+				{Name: "pattern", Kind: LiteralParam, Type: "regex"},
+			},
+			Outputs: []string{"result"},
+			Examples: []Example{
+				{
+					Script: `SET isPostcode = match(postcode, '^\d{4}-\d{3}$')`,
+					Input:  `{"postcode":"1000-001"}`,
+					Output: `{"postcode":"1000-001","isPostcode":true}`,
+				},
+				{
+					Script: `SET isPostcode = match(postcode, '^(\d{4}')`,
+					Input:  `{"postcode":"1000-001"}`,
+					Error:  "is not a valid regular expression",
+				},
+			},
+		},
+		factory: func(config transformers.Config) Transformer { return Adapt(&transformers.Match{Config: config}) },
+	},
+	{
+		signature: Signature{
+			Name:        "extract",
+			Description: "Returns the capture groups of the first match of a regular expression, numbered or named, in the order they appear in the pattern, or the whole match when there are no groups.",
+			Params: []Param{
+				{Name: "value", Kind: FieldParam, Type: "string"},
+				{Name: "pattern", Kind: LiteralParam, Type: "regex"},
+			},
+			Outputs:         []string{"group"},
+			VariadicOutputs: true,
+			Examples: []Example{
+				{
+					Script: `SET city, country = extract(place, '^(.+?)\s*[/-]\s*(.+)$')`,
+					Input:  `{"place":"São Paulo - SP"}`,
+					Output: `{"place":"São Paulo - SP","city":"São Paulo","country":"SP"}`,
+				},
+				{
+					Script: `SET year, month = extract(date, '(?P<year>\d{4})-(?P<month>\d{2})')`,
+					Input:  `{"date":"2024-03-15"}`,
+					Output: `{"date":"2024-03-15","year":"2024","month":"03"}`,
+				},
+				{
+					Script: `SET digits = extract(phone, '\d+')`,
+					Input:  `{"phone":"tel. 555 123"}`,
+					Output: `{"phone":"tel. 555 123","digits":"555"}`,
+				},
+				{
+					Script: `SET city, country = extract(place, '^(.+)/(.+)$')`,
+					Input:  `{"place":"Lisbon"}`,
+					Error:  "value 'Lisbon' does not match",
+				},
+			},
+		},
+		factory: func(config transformers.Config) Transformer { return Adapt(&transformers.Extract{Config: config}) },
+	},
+	{
+		signature: Signature{
+			Name:        "regexreplace",
+			Description: "Replaces every match of a regular expression in the value of a field. In the replacement, $1 or ${name} stand for capture groups.",
+			Params: []Param{
+				{Name: "value", Kind: FieldParam, Type: "string"},
+				{Name: "pattern", Kind: LiteralParam, Type: "regex"},
+				{Name: "replacement", Kind: LiteralParam, Type: "string"},
+			},
+			Outputs: []string{"result"},
+			Examples: []Example{
+				{
+					Script: `SET phone = regexreplace(phone, '\D', '')`,
+					Input:  `{"phone":"(555) 123-456"}`,
+					Output: `{"phone":"555123456"}`,
+				},
+				{
+					Script: `SET name = regexreplace(name, '^(\w+),\s*(\w+)$', '${2} $1')`,
+					Input:  `{"name":"Doe, John"}`,
+					Output: `{"name":"John Doe"}`,
+				},
+			},
+		},
+		factory: func(config transformers.Config) Transformer { return Adapt(&transformers.RegexReplace{Config: config}) },
+	},
+}
@@ -0,0 +1,244 @@
+package engine
+
+import (
+	"strings"
+
+	"github.com/codeis4fun/data-treatment-interpreter/internal/transformers"
+)
+
+// textBuiltins are the string transformers. They count characters (Unicode code points), not bytes
+var textBuiltins = []registration{
+	{
+		signature: Signature{
+			Name:        "lowercase",
+			Description: "Converts the value of a field to lowercase.",
+			Params: []Param{
+				{Name: "value", Kind: FieldParam, Type: "string"},
+			},
+			Outputs: []string{"result"},
+			Examples: []Example{
+				{
+					Script: "SET email = lowercase(email)",
+					Input:  `{"email":"John.Doe@Example.COM"}`,
+					Output: `{"email":"john.doe@example.com"}`,
+				},
+				{
+					Script: "SET city = lowercase(city)",
+					Input:  `{"city":"SÃO PAULO"}`,
+					Output: `{"city":"são paulo"}`,
+				},
+			},
+		},
+		factory: func(config transformers.Config) Transformer { return Adapt(&transformers.Lowercase{Config: config}) },
+	},
+	{
+		signature: Signature{
+			Name:        "titlecase",
+			Description: "Capitalises the first letter of every word and lowercases the others. Words start after anything but a letter, digit or apostrophe.",
+			Params: []Param{
+				{Name: "value", Kind: FieldParam, Type: "string"},
+			},
+			Outputs: []string{"result"},
+			Examples: []Example{
+				{
+					Script: "SET name = titlecase(name)",
+					Input:  `{"name":"jEAN-LUC o'neil"}`,
+					Output: `{"name":"Jean-Luc O'neil"}`,
+				},
+				{
+					Script: "SET city = titlecase(city)",
+					Input:  `{"city":"SÃO PAULO"}`,
+					Output: `{"city":"São Paulo"}`,
+				},
+			},
+		},
+		factory: func(config transformers.Config) Transformer { return Adapt(&transformers.Titlecase{Config: config}) },
+	},
+	trimBuiltin("trim", "Removes leading and trailing whitespace, or the given characters, from the value of a field.", true, true,
+		Example{Script: "SET name = trim(name)", Input: `{"name":"  john  "}`, Output: `{"name":"john"}`},
+		Example{Script: "SET code = trim(code, '*-')", Input: `{"code":"*-42-*"}`, Output: `{"code":"42"}`}),
+	trimBuiltin("ltrim", "Removes leading whitespace, or the given characters, from the value of a field.", true, false,
+		Example{Script: "SET name = ltrim(name)", Input: `{"name":"  john  "}`, Output: `{"name":"john  "}`},
+		Example{Script: "SET code = ltrim(code, '0')", Input: `{"code":"00420"}`, Output: `{"code":"420"}`}),
+	trimBuiltin("rtrim", "Removes trailing whitespace, or the given characters, from the value of a field.", false, true,
+		Example{Script: "SET name = rtrim(name)", Input: `{"name":"  john  "}`, Output: `{"name":"  john"}`},
+		Example{Script: "SET path = rtrim(path, '/')", Input: `{"path":"/home/john//"}`, Output: `{"path":"/home/john"}`}),
+	{
+		signature: Signature{
+			Name:        "replace",
+			Description: "Replaces every occurrence of a text in the value of a field.",
+			Params: []Param{
+				{Name: "value", Kind: FieldParam, Type: "string"},
+				{Name: "old", Kind: LiteralParam, Type: "string"},
+				{Name: "new", Kind: LiteralParam, Type: "string"},
+			},
+			Outputs: []string{"result"},
+			Examples: []Example{
+				{
+					Script: "SET phone = replace(phone, '-', '')",
+					Input:  `{"phone":"555-123-456"}`,
+					Output: `{"phone":"555123456"}`,
+				},
+			},
+		},
+		factory: func(config transformers.Config) Transformer { return Adapt(&transformers.Replace{Config: config}) },
+	},
+	{
+		signature: Signature{
+			Name:        "substring",
+			Description: "Extracts the characters of the value of a field from start (negative counts from the end), up to length characters. Positions past the end are clamped.",
+			Params: []Param{
+				{Name: "value", Kind: FieldParam, Type: "string"},
+				{Name: "start", Kind: LiteralParam, Type: "integer"},
+				{Name: "length", Kind: LiteralParam, Type: "integer", Optional: true},
+			},
+			Outputs: []string{"result"},
+			Examples: []Example{
+				{
+					Script: "SET initials = substring(name, '0', '2')",
+					Input:  `{"name":"Élodie"}`,
+					Output: `{"name":"Élodie","initials":"Él"}`,
+				},
+				{
+					Script: "SET suffix = substring(code, '-3')",
+					Input:  `{"code":"PT-1000-XYZ"}`,
+					Output: `{"code":"PT-1000-XYZ","suffix":"XYZ"}`,
+				},
+				{
+					Script: "SET suffix = substring(code, 'three')",
+					Input:  `{"code":"PT-1000-XYZ"}`,
+					Error:  "argument 2 of 'substring' (start) must be an integer, got 'three'",
+				},
+			},
+		},
+		factory: func(config transformers.Config) Transformer { return Adapt(&transformers.Substring{Config: config}) },
+	},
+	padBuiltin("padleft", "Pads the value of a field on the left with spaces, or the given padding, up to a width in characters.", true,
+		Example{Script: "SET code = padleft(code, '5', '0')", Input: `{"code":42}`, Output: `{"code":"00042"}`}),
+	padBuiltin("padright", "Pads the value of a field on the right with spaces, or the given padding, up to a width in characters.", false,
+		Example{Script: "SET name = padright(name, '6', '.')", Input: `{"name":"zoë"}`, Output: `{"name":"zoë..."}`}),
+	{
+		signature: Signature{
+			Name:        "length",
+			Description: "Counts the characters of the value of a field.",
+			Params: []Param{
+				{Name: "value", Kind: FieldParam, Type: "string"},
+			},
+			Outputs: []string{"length"},
+			Examples: []Example{
+				{
+					Script: "SET nameLength = length(name)",
+					Input:  `{"name":"José"}`,
+					Output: `{"name":"José","nameLength":4}`,
+				},
+			},
+		},
+		factory: func(config transformers.Config) Transformer { return Adapt(&transformers.Length{Config: config}) },
+	},
+	containsBuiltin("startswith", "Tells whether the value of a field starts with a text.", strings.HasPrefix,
+		Example{Script: "SET isMobile = startswith(phone, '+3519')", Input: `{"phone":"+351912345678"}`, Output: `{"phone":"+351912345678","isMobile":true}`}),
+	containsBuiltin("endswith", "Tells whether the value of a field ends with a text.", strings.HasSuffix,
+		Example{Script: "SET isCorporate = endswith(email, '@acme.com')", Input: `{"email":"john@gmail.com"}`, Output: `{"email":"john@gmail.com","isCorporate":false}`}),
+	containsBuiltin("contains", "Tells whether the value of a field contains a text.", strings.Contains,
+		Example{Script: "SET isSuite = contains(address, 'Suite')", Input: `{"address":"1 Main St, Suite 4"}`, Output: `{"address":"1 Main St, Suite 4","isSuite":true}`}),
+	{
+		signature: Signature{
+			Name:        "repeat",
+			Description: "Repeats the value of a field a number of times.",
+			Params: []Param{
+				{Name: "value", Kind: FieldParam, Type: "string"},
+				{Name: "count", Kind: LiteralParam, Type: "integer"},
+			},
+			Outputs: []string{"result"},
+			Examples: []Example{
+				{
+					Script: "SET rule = repeat(dash, '5')",
+					Input:  `{"dash":"-"}`,
+					Output: `{"dash":"-","rule":"-----"}`,
+				},
+				{
+					Script: "SET rule = repeat(dash, '-1')",
+					Input:  `{"dash":"-"}`,
+					Error:  "count must not be negative",
+				},
+			},
+		},
+		factory: func(config transformers.Config) Transformer { return Adapt(&transformers.Repeat{Config: config}) },
+	},
+	{
+		signature: Signature{
+			Name:        "reverse",
+			Description: "Reverses the characters of the value of a field, keeping combining accents on their letter.",
+			Params: []Param{
+				{Name: "value", Kind: FieldParam, Type: "string"},
+			},
+			Outputs: []string{"result"},
+			Examples: []Example{
+				{
+					Script: "SET reversed = reverse(word)",
+					Input:  `{"word":"añejo"}`,
+					Output: `{"word":"añejo","reversed":"ojeña"}`,
+				},
+			},
+		},
+		factory: func(config transformers.Config) Transformer { return Adapt(&transformers.Reverse{Config: config}) },
+	},
+}
+
+// trimBuiltin registers a trim transformer for the given sides
+func trimBuiltin(name, description string, left, right bool, examples ...Example) registration {
+	return registration{
+		signature: Signature{
+			Name:        name,
+			Description: description,
+			Params: []Param{
+				{Name: "value", Kind: FieldParam, Type: "string"},
+				{Name: "characters", Kind: LiteralParam, Type: "string", Optional: true},
+			},
+			Outputs:  []string{"result"},
+			Examples: examples,
+		},
+		factory: func(config transformers.Config) Transformer {
+			return Adapt(&transformers.Trim{Config: config, Name: name, Left: left, Right: right})
+		},
+	}
+}
+
+// padBuiltin registers a pad transformer for the given side
+func padBuiltin(name, description string, left bool, examples ...Example) registration {
+	return registration{
+		signature: Signature{
+			Name:        name,
+			Description: description,
+			Params: []Param{
+				{Name: "value", Kind: FieldParam, Type: "string"},
+				{Name: "width", Kind: LiteralParam, Type: "integer"},
+				{Name: "padding", Kind: LiteralParam, Type: "string", Optional: true},
+			},
+			Outputs:  []string{"result"},
+			Examples: examples,
+		},
+		factory: func(config transformers.Config) Transformer {
+			return Adapt(&transformers.Pad{Config: config, Name: name, Left: left})
+		},
+	}
+}
+
+// containsBuiltin registers a transformer testing the value of a field against a text
+func containsBuiltin(name, description string, test func(s, substr string) bool, examples ...Example) registration {
+	return registration{
+		signature: Signature{
+			Name:        name,
+			Description: description,
+			Params: []Param{
+				{Name: "value", Kind: FieldParam, Type: "string"},
+				{Name: "text", Kind: LiteralParam, Type: "string"},
+			},
+			Outputs:  []string{"result"},
+			Examples: examples,
+		},
+		factory: func(config transformers.Config) Transformer {
+			return Adapt(&transformers.Contains{Config: config, Name: name, Test: test})
+		},
+	}
+}
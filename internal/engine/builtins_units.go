@@ -0,0 +1,43 @@
+package engine
+
+import "github.com/codeis4fun/data-treatment-interpreter/internal/transformers"
+
+// unitBuiltins are the unit conversion transformers
+var unitBuiltins = []registration{
+	{
+		signature: Signature{
+			Name:        "convert",
+			Description: "Converts a number between units of the same quantity, exactly, optionally rounding half-up to a number of decimal places. Mass: mg, g, kg, t, oz, lb, st. Length: mm, cm, m, km, in, ft, yd, mi. Temperature: c, f, k. Volume (US customary): ml, cl, dl, l, tsp, tbsp, floz, cup, pt, qt, gal.",
+			Params: []Param{
+				{Name: "value", Kind: AnyParam, Type: "number"},
+				{Name: "from", Kind: LiteralParam, Type: "string"},
+				{Name: "to", Kind: LiteralParam, Type: "string"},
+				{Name: "digits", Kind: LiteralParam, Type: "integer", Optional: true},
+			},
+			Outputs: []string{"result"},
+			Examples: []Example{
+				{
+					Script: "SET weight = convert(weight, 'lb', 'kg')",
+					Input:  `{"weight":154}`,
+					Output: `{"weight":69.85322498}`,
+				},
+				{
+					Script: "SET height = convert(height, 'in', 'cm', '1')",
+					Input:  `{"height":"69"}`,
+					Output: `{"height":175.3}`,
+				},
+				{
+					Script: "SET temperature = convert(temperature, 'F', 'C', '1')",
+					Input:  `{"temperature":98.6}`,
+					Output: `{"temperature":37}`,
+				},
+				{
+					Script: "SET weight = convert(weight, 'lb', 'cm')",
+					Input:  `{"weight":154}`,
+					Error:  "cannot convert lb (mass) to cm (length)",
+				},
+			},
+		},
+		factory: func(config transformers.Config) Transformer { return Adapt(&transformers.Convert{Config: config}) },
+	},
+}
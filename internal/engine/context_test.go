@@ -0,0 +1,100 @@
+package engine_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/codeis4fun/data-treatment-interpreter/internal/engine"
+	"github.com/codeis4fun/data-treatment-interpreter/internal/transformers"
+)
+
+// counter is a context-aware transformer that cancels its context after a number of calls
+type counter struct {
+	calls  *int
+	after  int
+	cancel context.CancelFunc
+}
+
+func (c counter) Transform(ctx context.Context) (transformers.Results, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	*c.calls++
+	if *c.calls == c.after {
+		c.cancel()
+	}
+	return transformers.Results{*c.calls}, nil
+}
+
+func TestExecuteAllContextStopsBetweenIterations(t *testing.T) {
+	jsonData := []byte(`{"items":[{},{},{},{},{},{},{},{},{},{}]}`)
+
+	tests := []struct {
+		name   string
+		script string
+		opts   []engine.Option
+	}{
+		{name: "fail fast", script: `SET items.#.n = count(items.#)`},
+		{name: "continue on error", script: `SET items.#.n = count(items.#)`, opts: []engine.Option{engine.WithErrorPolicy(engine.ContinueOnError)}},
+		{name: "on error skip", script: `SET items.#.n = count(items.#) ON ERROR SKIP`},
+		{name: "document model", script: `SET items.#.n = count(items.#)`, opts: []engine.Option{engine.WithDocumentModel()}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			calls := 0
+			e := engine.NewEngine(tt.opts...)
+			e.Register(engine.Signature{
+				Name:    "count",
+				Params:  []engine.Param{{Name: "value", Kind: engine.FieldParam, Type: "any"}},
+				Outputs: []string{"count"},
+			}, func(config transformers.Config) engine.Transformer {
+				return counter{calls: &calls, after: 3, cancel: cancel}
+			})
+
+			output, err := e.ExecuteAllContext(ctx, parse(t, tt.script), jsonData)
+			if !errors.Is(err, context.Canceled) {
+				t.Fatalf("Expected context.Canceled, got %v", err)
+			}
+			if calls != 3 {
+				t.Errorf("Expected 3 calls, got %d", calls)
+			}
+			if string(output) != string(jsonData) {
+				t.Errorf("Expected the original document %s, got %s", jsonData, output)
+			}
+		})
+	}
+}
+
+func TestExecuteAllContextWithExpiredDeadline(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), -time.Second)
+	defer cancel()
+
+	_, err := engine.NewEngine().ExecuteAllContext(ctx, parse(t, `SET name = uppercase(name)`), []byte(`{"name":"john"}`))
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected context.DeadlineExceeded, got %v", err)
+	}
+}
+
+func TestAdaptedTransformerDoesNotRunAfterCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	transformer := engine.Adapt(&transformers.Uppercase{Config: transformers.Config{Args: []string{"name"}, Json: []byte(`{"name":"john"}`)}})
+	if _, err := transformer.Transform(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+
+	results, err := transformer.Transform(context.Background())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if results[0] != "JOHN" {
+		t.Errorf("Expected JOHN, got %v", results[0])
+	}
+}
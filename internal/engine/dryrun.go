@@ -0,0 +1,90 @@
+package engine
+
+import (
+	"context"
+	"errors"
+
+	"github.com/codeis4fun/data-treatment-interpreter/internal/diff"
+	"github.com/codeis4fun/data-treatment-interpreter/internal/parser"
+)
+
+// Change lists the paths a single statement created, modified or deleted
+type Change struct {
+	Statement int             `json:"statement"` // 1-based index of the statement, 0 for the cleanup of temporary variables
+	Program   *parser.Program `json:"program,omitempty"`
+	Patch     diff.Patch      `json:"patch"`
+	Error     string          `json:"error,omitempty"` // Why the statement was skipped under the ContinueOnError policy
+}
+
+// DryRun executes the programs like ExecuteAll and reports, statement by statement, the changes
+// each one made as a JSON Patch. The input is never modified; the transformed document is
+// returned alongside the changes so callers can compare it with the input as a whole. Under the
+// ContinueOnError policy, failing statements are reported with an empty patch and their error,
+// and the errors are returned together once every statement has run
+func (e *Engine) DryRun(programs []*parser.Program, jsonData []byte, opts ...ExecOption) ([]Change, []byte, error) {
+	return e.DryRunContext(context.Background(), programs, jsonData, opts...)
+}
+
+// DryRunContext is DryRun with a context. Like Plan.ExecuteContext, it applies the limits of the
+// engine and stops once the context is done, returning the changes made so far with the error
+func (e *Engine) DryRunContext(ctx context.Context, programs []*parser.Program, jsonData []byte, opts ...ExecOption) ([]Change, []byte, error) {
+	plan, err := e.Compile(programs)
+	if err != nil {
+		return nil, nil, err
+	}
+	params, err := newExecution(opts).values(programs)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := e.limits.checkDocument(jsonData, false); err != nil {
+		return nil, nil, err
+	}
+
+	ctx, cancel := e.limits.withTimeout(ctx)
+	defer cancel()
+
+	// Changes are computed between the bytes before and after each statement, so the document is always kept as bytes
+	var changes []Change
+	var errs []error
+	state := withEnvironment(&bytesState{data: jsonData}, plan.lets, nil)
+	for _, statement := range plan.statements {
+		if ctx.Err() != nil {
+			return changes, nil, context.Cause(ctx)
+		}
+
+		before := state.bytes()
+		mark := state.mark()
+		err := e.run(ctx, statement, state, params)
+		if err != nil {
+			if ctx.Err() != nil {
+				err = context.Cause(ctx)
+			}
+			fatal := ctx.Err() != nil || isLimitError(err)
+			err = &StatementError{Statement: statement.index, Program: statement.program, Err: err}
+			if e.errorPolicy == FailFast || statement.program.OnError == parser.ErrorFail || fatal {
+				return changes, nil, err
+			}
+			state.rollback(mark)
+			errs = append(errs, err)
+		}
+		change := Change{Statement: statement.index, Program: statement.program, Patch: diff.Compare(before, state.bytes())}
+		if err != nil {
+			change.Error = err.Error()
+		}
+		changes = append(changes, change)
+	}
+
+	// The deletion of temporary variables is reported as its own step
+	before := state.bytes()
+	if err := plan.deleteTemporaries(state); err != nil {
+		return changes, nil, err
+	}
+	if patch := diff.Compare(before, state.bytes()); len(patch) > 0 {
+		changes = append(changes, Change{Patch: patch})
+	}
+	if err := e.limits.checkDocument(state.bytes(), true); err != nil {
+		return changes, nil, err
+	}
+
+	return changes, state.bytes(), errors.Join(errs...)
+}
@@ -0,0 +1,125 @@
+package engine_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/codeis4fun/data-treatment-interpreter/internal/engine"
+	"github.com/codeis4fun/data-treatment-interpreter/internal/parser"
+)
+
+func TestDryRun(t *testing.T) {
+	jsonData := []byte(`{"name": "john", "surname": "doe"}`)
+
+	programs, err := parser.Parse(`SET _fullName = concatenate(' ', name, surname)
+SET fullName = uppercase(_fullName)
+SET name = uppercase(name)`)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	e := engine.NewEngine()
+	changes, output, err := e.DryRun(programs, jsonData)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expectedOutput, err := e.ExecuteAll(programs, jsonData)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if string(output) != string(expectedOutput) {
+		t.Errorf("Expected %s, got %s", expectedOutput, output)
+	}
+
+	expected := []struct {
+		statement int
+		patch     string
+	}{
+		{statement: 1, patch: `[{"op":"add","path":"/_fullName","value":"john doe"}]`},
+		{statement: 2, patch: `[{"op":"add","path":"/fullName","value":"JOHN DOE"}]`},
+		{statement: 3, patch: `[{"op":"replace","path":"/name","value":"JOHN","oldValue":"john"}]`},
+		{statement: 0, patch: `[{"op":"remove","path":"/_fullName","oldValue":"john doe"}]`},
+	}
+
+	if len(changes) != len(expected) {
+		t.Fatalf("Expected %d changes, got %d", len(expected), len(changes))
+	}
+	for i, change := range changes {
+		patch, _ := json.Marshal(change.Patch)
+		if change.Statement != expected[i].statement || string(patch) != expected[i].patch {
+			t.Errorf("Expected statement %d with %s, got statement %d with %s", expected[i].statement, expected[i].patch, change.Statement, patch)
+		}
+	}
+
+	if string(jsonData) != `{"name": "john", "surname": "doe"}` {
+		t.Errorf("Expected the input to be left untouched, got %s", jsonData)
+	}
+}
+
+func TestDryRunWithIterations(t *testing.T) {
+	jsonData := []byte(`{"friends":[{"name":"Alice"},{"name":"bob"}]}`)
+
+	programs := []*parser.Program{
+		{Variables: []string{"friends.#.name"}, Transformer: "uppercase", Args: []string{"friends.#.name"}},
+	}
+
+	changes, _, err := engine.NewEngine().DryRun(programs, jsonData)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	patch, _ := json.Marshal(changes[0].Patch)
+	expected := `[{"op":"replace","path":"/friends/0/name","value":"ALICE","oldValue":"Alice"},{"op":"replace","path":"/friends/1/name","value":"BOB","oldValue":"bob"}]`
+	if string(patch) != expected {
+		t.Errorf("Expected %s, got %s", expected, patch)
+	}
+}
+
+func TestDryRunWithError(t *testing.T) {
+	jsonData := []byte(`{"name": "john"}`)
+
+	programs := []*parser.Program{
+		{Variables: []string{"name"}, Transformer: "uppercase", Args: []string{"name"}},
+		{Variables: []string{"surname"}, Transformer: "uppercase", Args: []string{"surname"}},
+	}
+
+	changes, _, err := engine.NewEngine().DryRun(programs, jsonData)
+	if err == nil {
+		t.Fatalf("Expected error, got nil")
+	}
+	if len(changes) != 1 {
+		t.Errorf("Expected the changes made before the error, got %d", len(changes))
+	}
+}
+
+func TestDryRunWithContinueOnError(t *testing.T) {
+	jsonData := []byte(`{"name": "john"}`)
+
+	programs := []*parser.Program{
+		{Variables: []string{"surname"}, Transformer: "uppercase", Args: []string{"surname"}},
+		{Variables: []string{"name"}, Transformer: "uppercase", Args: []string{"name"}},
+	}
+
+	e := engine.NewEngine(engine.WithErrorPolicy(engine.ContinueOnError))
+	changes, output, err := e.DryRun(programs, jsonData)
+	expectedOutput, expectedErr := e.ExecuteAll(programs, jsonData)
+	if err == nil || expectedErr == nil || err.Error() != expectedErr.Error() {
+		t.Fatalf("Expected error %v, got %v", expectedErr, err)
+	}
+	if string(output) != string(expectedOutput) {
+		t.Errorf("Expected %s, got %s", expectedOutput, output)
+	}
+
+	if len(changes) != 2 {
+		t.Fatalf("Expected 2 changes, got %d", len(changes))
+	}
+	expected := "statement 1: argument 'surname' not found in JSON"
+	if changes[0].Error != expected || len(changes[0].Patch) != 0 {
+		t.Errorf("Expected statement 1 to be skipped with %s, got %s and %v", expected, changes[0].Error, changes[0].Patch)
+	}
+	patch, _ := json.Marshal(changes[1].Patch)
+	if string(patch) != `[{"op":"replace","path":"/name","value":"JOHN","oldValue":"john"}]` || changes[1].Error != "" {
+		t.Errorf("Expected statement 2 to uppercase the name, got %s", patch)
+	}
+}
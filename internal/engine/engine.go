@@ -1,148 +1,280 @@
 package engine
 
 import (
+	"context"
 	"fmt"
-	"strconv"
-	"strings"
+	"sort"
+	"time"
 
 	"github.com/codeis4fun/data-treatment-interpreter/internal/parser"
 	"github.com/codeis4fun/data-treatment-interpreter/internal/transformers"
 	"github.com/tidwall/gjson"
-	"github.com/tidwall/sjson"
 )
 
+// Transformer applies a transformation. Transformers that can take long (large inputs, external
+// calls) should return ctx.Err() once the context is done
 type Transformer interface {
+	Transform(ctx context.Context) (transformers.Results, error)
+}
+
+// SimpleTransformer is a transformer whose Transform takes no context, like the ones in the transformers package
+type SimpleTransformer interface {
 	Transform() (transformers.Results, error)
 }
 
-type transformerConfig func(config transformers.Config) Transformer
+// Adapt turns a SimpleTransformer into a Transformer that does not run once the context is done
+func Adapt(transformer SimpleTransformer) Transformer {
+	return adapter{transformer}
+}
+
+// adapter is the Transformer returned by Adapt
+type adapter struct {
+	transformer SimpleTransformer
+}
+
+// Transform runs the adapted transformer unless the context is already done
+func (a adapter) Transform(ctx context.Context) (transformers.Results, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return a.transformer.Transform()
+}
+
+// Factory builds a transformer from the arguments of a program and the JSON it applies to
+type Factory func(config transformers.Config) Transformer
+
+// registration pairs a transformer factory with the signature used to check scripts
+type registration struct {
+	signature Signature
+	factory   Factory
+}
 
 // Engine struct that manages transformers
 type Engine struct {
-	transformers map[string]transformerConfig
+	transformers  map[string]registration
+	tracer        Tracer
+	errorPolicy   ErrorPolicy
+	missingPolicy MissingPolicy
+	documentModel bool
+	limits        Limits
+	allowed       map[string]bool // Transformers scripts can use, nil when all are allowed
+	tempPrefix    string          // Prefix of the temporary variables deleted from the output, empty to keep them all
+	clock         func() time.Time
 }
 
-// NewEngine initializes the engine
-func NewEngine() *Engine {
-	transformers := map[string]transformerConfig{
-		"uppercase":   func(config transformers.Config) Transformer { return &transformers.Uppercase{Config: config} },
-		"concatenate": func(config transformers.Config) Transformer { return &transformers.Concatenate{Config: config} },
-		"bmi":         func(config transformers.Config) Transformer { return &transformers.BMI{Config: config} },
-		"split":       func(config transformers.Config) Transformer { return &transformers.Split{Config: config} },
-	}
-	return &Engine{
-		transformers: transformers,
+// Option configures an Engine
+type Option func(*Engine)
+
+// WithTracer reports every application of a transformer to the tracer
+func WithTracer(tracer Tracer) Option {
+	return func(e *Engine) {
+		e.tracer = tracer
 	}
 }
 
-// Execute applies the transformations defined in the Program struct to the input JSON
-func (e *Engine) Execute(program *parser.Program, jsonData []byte) ([]byte, error) {
-	// Check if the command starts with an iteration keyword
-	if strings.Contains(program.Variables[0], "#") {
-		return e.executeIteration(program, jsonData)
+// WithErrorPolicy sets what ExecuteAll does when a statement without an ON ERROR clause fails
+func WithErrorPolicy(policy ErrorPolicy) Option {
+	return func(e *Engine) {
+		e.errorPolicy = policy
 	}
-	return e.executeSet(program, jsonData)
 }
 
-func (e *Engine) executeSet(program *parser.Program, jsonData []byte) ([]byte, error) {
-	// Create the appropriate transformer based on the program
-	transformerFunc, ok := e.transformers[program.Transformer]
-	if !ok {
-		return jsonData, fmt.Errorf("transformer '%s' not found", program.Transformer)
+// NewEngine initializes the engine with the built-in transformers
+func NewEngine(opts ...Option) *Engine {
+	e := &Engine{
+		transformers: map[string]registration{},
+		tempPrefix:   "_",
 	}
-	transformer := transformerFunc(transformers.Config{Args: program.Args, Json: jsonData})
-
-	// Apply the transformation, get multiple outputs
-	transformedValues, err := transformer.Transform()
-	if err != nil {
-		return nil, err
+	for _, builtin := range builtins {
+		e.Register(builtin.signature, builtin.factory)
 	}
+	for _, opt := range opts {
+		opt(e)
+	}
+	return e
+}
 
-	// Ensure the number of output values matches the number of variables in the program
-	if len(transformedValues) != len(program.Variables) {
-		return nil, fmt.Errorf("number of output values does not match the number of variables returned by transformer")
+// Register adds a transformer to the engine, replacing any transformer with the same name
+func (e *Engine) Register(signature Signature, factory Factory) {
+	e.transformers[signature.Name] = registration{
+		signature: signature,
+		factory:   factory,
 	}
+}
 
-	// Update the JSON with transformed values
-	for i, value := range transformedValues {
-		jsonData, err = sjson.SetBytes(jsonData, program.Variables[i], value)
-		if err != nil {
-			return nil, err
+// Signatures returns the signatures of all registered (and allowed) transformers sorted by name
+func (e *Engine) Signatures() []Signature {
+	signatures := make([]Signature, 0, len(e.transformers))
+	for _, registration := range e.transformers {
+		if !e.isAllowed(registration.signature.Name) {
+			continue
 		}
+		signatures = append(signatures, registration.signature)
 	}
+	sort.Slice(signatures, func(i, j int) bool { return signatures[i].Name < signatures[j].Name })
+	return signatures
+}
 
-	return jsonData, nil
+// optionalSignatures are the signatures of the transformers only registered by options, whose Requires names them
+var optionalSignatures = []Signature{encryptSignature, decryptSignature, bmiPercentileSignature}
+
+// Reference returns the signatures of every transformer sorted by name for the transformer reference: those of
+// every engine, and those only registered by options such as WithKeyring, whose Requires says so
+func Reference() []Signature {
+	signatures := append(NewEngine().Signatures(), optionalSignatures...)
+	sort.Slice(signatures, func(i, j int) bool { return signatures[i].Name < signatures[j].Name })
+	return signatures
+}
+
+// Execute applies the transformations defined in the Program struct to the input JSON
+func (e *Engine) Execute(program *parser.Program, jsonData []byte, opts ...ExecOption) ([]byte, error) {
+	return e.ExecuteContext(context.Background(), program, jsonData, opts...)
 }
 
-// Execute the iteration command
-func (e *Engine) executeIteration(program *parser.Program, jsonData []byte) ([]byte, error) {
-	// Gets the index of the placeholder in the variable path (e.g., "friends.#.first" -> 8)
-	variable := program.Variables[0]
-	placeholderIndex := strings.Index(variable, "#")
+// ExecuteContext is Execute with a context: a '#' statement stops between array elements once the context is done
+func (e *Engine) ExecuteContext(ctx context.Context, program *parser.Program, jsonData []byte, opts ...ExecOption) ([]byte, error) {
+	// Reject programs that do not match the transformer signature before touching the JSON
+	if err := e.vetProgram(program); err != nil {
+		return jsonData, err
+	}
+	programs := []*parser.Program{program}
+	x := newExecution(opts)
+	params, err := x.values(programs)
+	if err != nil {
+		return jsonData, err
+	}
+	if err := e.limits.checkDocument(jsonData, false); err != nil {
+		return jsonData, err
+	}
 
-	// Extract the array field to iterate over (e.g., "friends.#.first" -> "friends")
-	arrayField := variable[:placeholderIndex-1]
-	array := gjson.GetBytes(jsonData, arrayField)
+	ctx, cancel := e.limits.withTimeout(ctx)
+	defer cancel()
 
-	// Check if the field is a valid array
-	if !array.IsArray() {
-		return nil, fmt.Errorf("field '%s' is not an array", arrayField)
+	saved, err := x.saveEnvironment(programs)
+	if err != nil {
+		return jsonData, err
+	}
+	state := withEnvironment(&bytesState{data: jsonData}, letNames(programs), x.env)
+	if err := e.run(ctx, e.compile(1, program, transformers.NewPatterns()), state, params); err != nil {
+		if ctx.Err() != nil {
+			err = context.Cause(ctx)
+		}
+		x.restoreEnvironment(saved)
+		return jsonData, err
+	}
+	if err := e.limits.checkDocument(state.bytes(), true); err != nil {
+		x.restoreEnvironment(saved)
+		return jsonData, err
 	}
+	return state.bytes(), nil
+}
 
-	// Iterate over each element in the array
-	for i := range array.Array() {
-		// Replace `#` in the variable path with the current index (e.g., "friends.#.first" -> "friends.0.first")
-		variable := strings.Replace(variable, "#", strconv.Itoa(i), 1)
+// step identifies a single application of a transformer: the statement and, for '#' statements, the array index
+type step struct {
+	statement int
+	iteration int // -1 for statements without '#'
+}
 
-		// Apply the transformation to the current element
-		transformerFunc, ok := e.transformers[program.Transformer]
-		if !ok {
-			return jsonData, fmt.Errorf("transformer '%s' not found", program.Transformer)
+// apply runs the transformer of a statement with the given arguments and writes its outputs to the given
+// variables. A failed application is rolled back before its ON ERROR clause is applied
+func (e *Engine) apply(ctx context.Context, s *statement, step step, args, variables []string, state state, params map[string]gjson.Result) error {
+	// Look every argument up once; the values are shared by the transformer and the tracer
+	config := transformers.Config{Args: args, Json: state.json(), Values: make([]gjson.Result, len(args)), Now: e.clock, Patterns: s.patterns}
+	for i, arg := range args {
+		switch {
+		case isLiteral(arg):
+			config.Values[i] = transformers.Literal(arg)
+		case isParameter(arg):
+			config.Values[i] = params[arg[1:]]
+		default:
+			config.Values[i] = state.get(arg)
 		}
-		transformer := transformerFunc(transformers.Config{
-			Args: []string{variable}, // Pass the current field to the transformer
-			Json: jsonData,
-		})
-
-		// Get the transformed result (which should be for the current item only)
-		transformedValues, err := transformer.Transform()
-		if err != nil {
-			return nil, err
+	}
+
+	// Optional arguments (field?) turn the application into a no-op, or a null write, when the field is missing
+	for i, arg := range s.args {
+		if arg.optional && (!config.Values[i].Exists() || config.Values[i].Type == gjson.Null) {
+			return e.skip(s, step, config, variables, state)
 		}
+	}
+
+	start := time.Now()
+	transformer := s.factory(config)
+
+	// Apply the transformation, get multiple outputs
+	mark := state.mark()
+	transformedValues, err := transformer.Transform(ctx)
+
+	// Ensure the number of output values matches the number of variables in the program
+	if err == nil && len(transformedValues) != len(variables) {
+		err = fmt.Errorf("number of output values does not match the number of variables returned by transformer")
+	}
+	if err == nil {
+		err = e.limits.checkResults(transformedValues)
+	}
 
-		// Update the JSON for the current array element
-		for j, value := range transformedValues {
-			// Use the transformed value for the current array element
-			jsonData, err = sjson.SetBytes(jsonData, strings.Replace(program.Variables[j], "#", strconv.Itoa(i), 1), value)
-			if err != nil {
-				return nil, err
+	// Update the JSON with transformed values
+	if err == nil {
+		for i, value := range transformedValues {
+			if err = state.set(variables[i], value); err != nil {
+				break
 			}
 		}
 	}
 
-	return jsonData, nil
-}
-
-// Execute multiple transformations in sequence
-func (e *Engine) ExecuteAll(programs []*parser.Program, jsonData []byte) ([]byte, error) {
-	var err error
-	for _, program := range programs {
-		// Execute each program (command) in sequence
-		jsonData, err = e.Execute(program, jsonData)
-		if err != nil {
-			return nil, err
+	if e.tracer != nil {
+		e.tracer.Trace(newEvent(s, step, config, variables, transformedValues, err, time.Since(start)))
+	}
+	if err != nil {
+		state.rollback(mark)
+		// ON ERROR clauses never hide a cancellation or an exceeded limit
+		if ctx.Err() != nil {
+			return context.Cause(ctx)
+		}
+		if isLimitError(err) {
+			return err
 		}
+		return recoverError(s.program, state, variables, err)
 	}
-	// delete temporary variables from JSON which start with _prefix
-	for _, program := range programs {
-		for _, variable := range program.Variables {
-			if strings.HasPrefix(variable, "_") {
-				jsonData, err = sjson.DeleteBytes(jsonData, variable)
-				if err != nil {
-					return nil, err
-				}
+	return nil
+}
+
+// skip handles an application whose optional arguments are missing according to the missing policy. REDACT
+// statements never write, so a redacted field that is missing stays missing
+func (e *Engine) skip(s *statement, step step, config transformers.Config, variables []string, state state) error {
+	var results transformers.Results
+	if e.missingPolicy == NullMissing && !s.program.Redact {
+		mark := state.mark()
+		for _, variable := range variables {
+			if err := state.set(variable, nil); err != nil {
+				state.rollback(mark)
+				return recoverError(s.program, state, variables, err)
 			}
+			results = append(results, nil)
 		}
 	}
-	return jsonData, nil
+
+	if e.tracer != nil {
+		event := newEvent(s, step, config, variables, results, nil, 0)
+		event.Skipped = true
+		e.tracer.Trace(event)
+	}
+	return nil
+}
+
+// Execute multiple transformations in sequence. The input is never modified: when the script
+// fails under the FailFast policy, the original document is returned along with the error.
+// Scripts applied to many documents should be compiled once with Compile instead
+func (e *Engine) ExecuteAll(programs []*parser.Program, jsonData []byte, opts ...ExecOption) ([]byte, error) {
+	return e.ExecuteAllContext(context.Background(), programs, jsonData, opts...)
+}
+
+// ExecuteAllContext is ExecuteAll with a context, see Plan.ExecuteContext
+func (e *Engine) ExecuteAllContext(ctx context.Context, programs []*parser.Program, jsonData []byte, opts ...ExecOption) ([]byte, error) {
+	// Reject the whole script before processing any JSON if a statement does not match its signature
+	plan, err := e.Compile(programs)
+	if err != nil {
+		return jsonData, err
+	}
+	return plan.ExecuteContext(ctx, jsonData, opts...)
 }
@@ -7,6 +7,7 @@ import (
 	"github.com/codeis4fun/data-treatment-interpreter/internal/engine"
 	"github.com/codeis4fun/data-treatment-interpreter/internal/lexer"
 	"github.com/codeis4fun/data-treatment-interpreter/internal/parser"
+	"github.com/codeis4fun/data-treatment-interpreter/internal/transformers"
 )
 
 func TestEngineExecute(t *testing.T) {
@@ -410,3 +411,59 @@ func TestEngineWithIterationsButInexistentTransformer(t *testing.T) {
 		t.Errorf("Expected %s, got %s", expected, string(modifiedJSON))
 	}
 }
+
+func TestEngineWithGrowthReference(t *testing.T) {
+	// A made-up chart in the CDC layout: the median BMI of a 24 month old boy is 16
+	reference, err := transformers.ParseGrowthReference(strings.NewReader("Sex,Agemos,L,M,S\n1,24,1,16,0.1\n2,24,1,15,0.1\n"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	program := &parser.Program{
+		Variables:   []string{"zscore", "percentile"},
+		Transformer: "bmipercentile",
+		Args:        []string{"bmi", "age", "sex"},
+	}
+	jsonData := []byte(`{"bmi":16,"age":24,"sex":"male"}`)
+
+	_, err = engine.NewEngine().Execute(program, jsonData)
+	if expected := "transformer 'bmipercentile' not found: it is only registered by engine.WithGrowthReference (--growth-reference)"; err == nil || err.Error() != expected {
+		t.Fatalf("Expected error %q without a growth reference, got %v", expected, err)
+	}
+
+	output, err := engine.NewEngine(engine.WithGrowthReference(reference)).Execute(program, jsonData)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := `{"bmi":16,"age":24,"sex":"male","zscore":0,"percentile":50}`
+	if string(output) != expected {
+		t.Errorf("Expected %s, got %s", expected, output)
+	}
+}
+
+func TestEngineWithKeyring(t *testing.T) {
+	// Made-up keys: the script encrypts with the current key and re-encrypts data from before the rotation
+	keyring, err := transformers.ParseKeyring(strings.NewReader(`{"old":"MDEyMzQ1Njc4OWFiY2RlZg==","new":"ZmVkY2JhOTg3NjU0MzIxMA=="}`))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	programs, err := parser.Parse("SET address = encrypt(address, 'old')\nSET _address = decrypt(address, 'old')\nSET address = encrypt(_address, 'new')\nSET copy = decrypt(address)")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	jsonData := []byte(`{"address":{"city":"Springfield"}}`)
+
+	if _, err := engine.NewEngine().ExecuteAll(programs, jsonData); err == nil {
+		t.Fatalf("Expected encrypt to be unknown without a keyring")
+	}
+
+	for _, opts := range [][]engine.Option{{}, {engine.WithDocumentModel()}} {
+		output, err := engine.NewEngine(append(opts, engine.WithKeyring(keyring))...).ExecuteAll(programs, jsonData)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		address := string(output)
+		if !strings.HasPrefix(address, `{"address":"enc:v1:new:`) || !strings.HasSuffix(address, `","copy":{"city":"Springfield"}}`) {
+			t.Errorf("Expected the address encrypted with the new key and its decrypted copy, got %s", output)
+		}
+	}
+}
@@ -0,0 +1,237 @@
+package engine
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/codeis4fun/data-treatment-interpreter/internal/document"
+	"github.com/codeis4fun/data-treatment-interpreter/internal/parser"
+	"github.com/tidwall/gjson"
+)
+
+// WithTempPrefix sets the prefix of the temporary variables ExecuteAll deletes from the output
+// ("_" by default). An empty prefix disables the rule, so fields such as _id are always kept;
+// LET variables never reach the output whatever the prefix
+func WithTempPrefix(prefix string) Option {
+	return func(e *Engine) {
+		e.tempPrefix = prefix
+	}
+}
+
+// isTemporary reports whether a variable is deleted from the output at the end of an execution
+func (e *Engine) isTemporary(variable string) bool {
+	return e.tempPrefix != "" && strings.HasPrefix(variable, e.tempPrefix)
+}
+
+// letNames returns the variables assigned by the LET statements of a script
+func letNames(programs []*parser.Program) map[string]bool {
+	names := map[string]bool{}
+	for _, program := range programs {
+		if program.Let {
+			for _, variable := range program.Variables {
+				names[variable] = true
+			}
+		}
+	}
+	return names
+}
+
+// isName reports whether a LET variable is a plain name: letters, digits and underscores
+func isName(variable string) bool {
+	if variable == "" {
+		return false
+	}
+	for _, r := range variable {
+		if r != '_' && !('a' <= r && r <= 'z') && !('A' <= r && r <= 'Z') && !('0' <= r && r <= '9') {
+			return false
+		}
+	}
+	return true
+}
+
+// root returns the first segment of a path (e.g. "address.city" -> "address") and the rest of it
+func root(path string) (string, string) {
+	name, rest, _ := strings.Cut(path, ".")
+	return name, rest
+}
+
+// Environment holds LET variables across executions, for callers that run a script one statement
+// at a time, such as the REPL. Pass it to every execution with WithEnvironment
+type Environment struct {
+	values map[string]gjson.Result
+}
+
+// NewEnvironment creates an empty environment
+func NewEnvironment() *Environment {
+	return &Environment{values: map[string]gjson.Result{}}
+}
+
+// WithEnvironment makes an execution read and assign LET variables in the environment, so the variables
+// assigned by one execution can be read by the next ones. A failed execution leaves the environment as it was
+func WithEnvironment(env *Environment) ExecOption {
+	return func(x *execution) {
+		x.env = env
+	}
+}
+
+// Get returns the value of a LET variable, or of a path inside it (e.g. "place.city")
+func (env *Environment) Get(path string) gjson.Result {
+	name, rest := root(path)
+	if rest == "" {
+		return env.values[name]
+	}
+	return gjson.Get(env.values[name].Raw, rest)
+}
+
+// Names returns the names of the variables, sorted
+func (env *Environment) Names() []string {
+	names := make([]string, 0, len(env.values))
+	for name := range env.values {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// Clone returns a copy of the environment, e.g. to restore it when statements are undone
+func (env *Environment) Clone() *Environment {
+	return &Environment{values: maps.Clone(env.values)}
+}
+
+// saveEnvironment checks the programs against the environment of the execution, if any, and returns a
+// copy of it to restore when the execution fails
+func (x *execution) saveEnvironment(programs []*parser.Program) (*Environment, error) {
+	if x.env == nil {
+		return nil, nil
+	}
+	if err := x.env.checkAssignments(programs); err != nil {
+		return nil, err
+	}
+	return x.env.Clone(), nil
+}
+
+// restoreEnvironment returns the environment of a failed execution to the copy taken before it ran
+func (x *execution) restoreEnvironment(saved *Environment) {
+	if x.env != nil {
+		x.env.values = saved.values
+	}
+}
+
+// checkAssignments rejects SET statements writing a variable of the environment, as vet does for
+// names assigned by LET earlier in the same script
+func (env *Environment) checkAssignments(programs []*parser.Program) error {
+	var errs []error
+	for i, program := range programs {
+		if program.Let {
+			continue
+		}
+		for _, variable := range program.Variables {
+			if name, _ := root(variable); env.values[name].Exists() {
+				errs = append(errs, fmt.Errorf("statement %d: variable '%s' is declared with LET and cannot be assigned with SET", i+1, variable))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// binding is the value a LET variable had before a write, kept so the write can be rolled back
+type binding struct {
+	name   string
+	value  gjson.Result
+	exists bool
+}
+
+// envState is a state that keeps the LET variables of a script in an environment next to the document.
+// Paths rooted at a LET variable (e.g. "tmp" or "tmp.city") are read from the environment, every
+// other path goes to the document
+type envState struct {
+	state
+	lets   map[string]bool
+	values map[string]gjson.Result
+	undo   []binding
+}
+
+// envMark is a mark of an envState: one for the document and one for the environment
+type envMark struct {
+	document any
+	undo     int
+}
+
+// withEnvironment adds an environment for the LET variables of a script to a state, when it has any.
+// The variables of a given environment are read and assigned in place
+func withEnvironment(s state, lets map[string]bool, env *Environment) state {
+	if env == nil {
+		if len(lets) == 0 {
+			return s
+		}
+		env = NewEnvironment()
+	} else if len(env.values) > 0 {
+		lets = maps.Clone(lets)
+		for name := range env.values {
+			lets[name] = true
+		}
+	}
+	return &envState{state: s, lets: lets, values: env.values}
+}
+
+func (s *envState) get(path string) gjson.Result {
+	if name, _ := root(path); !s.lets[name] {
+		return s.state.get(path)
+	}
+	return (&Environment{values: s.values}).Get(path)
+}
+
+func (s *envState) set(path string, value any) error {
+	if name, _ := root(path); !s.lets[name] {
+		return s.state.set(path, value)
+	}
+	raw, err := document.Encode(value)
+	if err != nil {
+		return err
+	}
+	return s.setRaw(path, raw)
+}
+
+func (s *envState) setRaw(path string, raw []byte) error {
+	if name, rest := root(path); !s.lets[name] {
+		return s.state.setRaw(path, raw)
+	} else if rest != "" {
+		return fmt.Errorf("cannot assign '%s': LET variables are assigned as a whole", path)
+	}
+	previous, exists := s.values[path]
+	s.undo = append(s.undo, binding{name: path, value: previous, exists: exists})
+	s.values[path] = gjson.ParseBytes(raw)
+	return nil
+}
+
+func (s *envState) delete(path string) error {
+	if name, _ := root(path); s.lets[name] {
+		return fmt.Errorf("cannot delete '%s': it is a LET variable", path)
+	}
+	return s.state.delete(path)
+}
+
+func (s *envState) mark() any {
+	return envMark{document: s.state.mark(), undo: len(s.undo)}
+}
+
+func (s *envState) rollback(mark any) {
+	m := mark.(envMark)
+	s.state.rollback(m.document)
+	for i := len(s.undo) - 1; i >= m.undo; i-- {
+		if b := s.undo[i]; b.exists {
+			s.values[b.name] = b.value
+		} else {
+			delete(s.values, b.name)
+		}
+	}
+	s.undo = s.undo[:m.undo]
+}
+
+func (s *envState) commit() {
+	s.state.commit()
+	s.undo = s.undo[:0]
+}
@@ -0,0 +1,165 @@
+package engine_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/codeis4fun/data-treatment-interpreter/internal/engine"
+)
+
+func TestLet(t *testing.T) {
+	jsonData := []byte(`{"_id":"1","name":"john","surname":"doe","address":{"city":"lisbon"},"friends":[{"name":"alice"},{"name":"bob"}]}`)
+	script := `LET full = concatenate(' ', name, surname)
+LET place = coalesce(home, address)
+SET greeting = concatenate(', ', name, full)
+SET city = uppercase(place.city)
+SET friends.#.greeting = concatenate(' from ', friends.#.name, full)
+LET full = uppercase(full)
+SET shout = concatenate('-', full, surname)`
+
+	for _, opts := range [][]engine.Option{nil, {engine.WithDocumentModel()}} {
+		output, err := engine.NewEngine(opts...).ExecuteAll(parse(t, script), jsonData)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		expected := `{"_id":"1","name":"john","surname":"doe","address":{"city":"lisbon"},"friends":[{"name":"alice","greeting":"alice from john doe"},{"name":"bob","greeting":"bob from john doe"}],"greeting":"john, john doe","city":"LISBON","shout":"JOHN DOE-doe"}`
+		if string(output) != expected {
+			t.Errorf("Expected %s, got %s", expected, output)
+		}
+	}
+}
+
+func TestLetOnError(t *testing.T) {
+	script := `LET label = uppercase(missing) ON ERROR DEFAULT 'n/a'
+SET label_copy = uppercase(label)`
+
+	output, err := engine.NewEngine().ExecuteAll(parse(t, script), []byte(`{}`))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := `{"label_copy":"N/A"}`
+	if string(output) != expected {
+		t.Errorf("Expected %s, got %s", expected, output)
+	}
+}
+
+func TestLetIsRolledBack(t *testing.T) {
+	// The second LET fails and is rolled back, so the variable keeps the value of the first one
+	script := `LET label = uppercase(name)
+LET label = uppercase(missing)
+SET result = concatenate('-', label, name)`
+
+	output, err := engine.NewEngine(engine.WithErrorPolicy(engine.ContinueOnError)).ExecuteAll(parse(t, script), []byte(`{"name":"john","age":30}`))
+	if err == nil {
+		t.Fatalf("Expected error, got nil")
+	}
+
+	expected := `{"name":"john","age":30,"result":"JOHN-john"}`
+	if string(output) != expected {
+		t.Errorf("Expected %s, got %s", expected, output)
+	}
+}
+
+func TestLetVet(t *testing.T) {
+	tests := []struct {
+		script   string
+		expected string
+	}{
+		{script: `LET a.b = uppercase(name)`, expected: "statement 1: LET variable 'a.b' must be a plain name (letters, digits and underscores)"},
+		{script: `LET items.# = uppercase(items.#)`, expected: "statement 1: LET variable 'items.#' must be a plain name (letters, digits and underscores)"},
+		{script: "LET tmp = uppercase(name)\nSET tmp.x = uppercase(name)", expected: "statement 2: variable 'tmp.x' is declared with LET and cannot be assigned with SET"},
+	}
+
+	for _, tt := range tests {
+		err := engine.NewEngine().Vet(parse(t, tt.script))
+		if err == nil {
+			t.Fatalf("Expected error, got nil")
+		}
+		if err.Error() != tt.expected {
+			t.Errorf("Expected %s, got %s", tt.expected, err)
+		}
+	}
+}
+
+func TestTempPrefix(t *testing.T) {
+	jsonData := []byte(`{"_id":"abc","name":"john"}`)
+	script := `SET _id = uppercase(_id)
+SET tmp_name = uppercase(name)`
+
+	tests := []struct {
+		prefix   string
+		expected string
+	}{
+		{prefix: "_", expected: `{"name":"john","tmp_name":"JOHN"}`},
+		{prefix: "", expected: `{"_id":"ABC","name":"john","tmp_name":"JOHN"}`},
+		{prefix: "tmp_", expected: `{"_id":"ABC","name":"john"}`},
+	}
+
+	for _, tt := range tests {
+		output, err := engine.NewEngine(engine.WithTempPrefix(tt.prefix)).ExecuteAll(parse(t, script), jsonData)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if string(output) != tt.expected {
+			t.Errorf("%s: expected %s, got %s", strings.TrimSpace(tt.prefix), tt.expected, output)
+		}
+	}
+}
+
+func TestEnvironmentAcrossExecutions(t *testing.T) {
+	e := engine.NewEngine()
+	env := engine.NewEnvironment()
+	jsonData := []byte(`{"name":"john","surname":"doe"}`)
+
+	programs := parse(t, "LET full = concatenate(' ', name, surname)\nSET fullName = uppercase(full)")
+	output, err := e.Execute(programs[0], jsonData, engine.WithEnvironment(env))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if string(output) != string(jsonData) {
+		t.Errorf("Expected LET to leave the document untouched, got %s", output)
+	}
+
+	// The next execution reads the variable assigned by the previous one
+	output, err = e.Execute(programs[1], output, engine.WithEnvironment(env))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := `{"name":"john","surname":"doe","fullName":"JOHN DOE"}`
+	if string(output) != expected {
+		t.Errorf("Expected %s, got %s", expected, output)
+	}
+
+	// A failed execution leaves the environment as it was
+	plan, err := e.Compile(parse(t, "LET full = uppercase(full)\nSET missing = uppercase(missing)"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, err := plan.Execute(output, engine.WithEnvironment(env)); err == nil {
+		t.Fatalf("Expected error, but got nil")
+	}
+	if value := env.Get("full").String(); value != "john doe" {
+		t.Errorf("Expected full to be john doe, got %s", value)
+	}
+
+	// Variables of the environment cannot be assigned with SET
+	_, err = e.Execute(parse(t, "SET full = uppercase(name)")[0], output, engine.WithEnvironment(env))
+	expectedErr := "statement 1: variable 'full' is declared with LET and cannot be assigned with SET"
+	if err == nil || err.Error() != expectedErr {
+		t.Errorf("Expected %s, got %v", expectedErr, err)
+	}
+
+	// A clone is not affected by later assignments
+	clone := env.Clone()
+	if _, err := e.Execute(parse(t, "LET other = uppercase(name)")[0], output, engine.WithEnvironment(env)); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if names := strings.Join(env.Names(), ","); names != "full,other" {
+		t.Errorf("Expected full,other, got %s", names)
+	}
+	if names := strings.Join(clone.Names(), ","); names != "full" {
+		t.Errorf("Expected full, got %s", names)
+	}
+}
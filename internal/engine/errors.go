@@ -0,0 +1,70 @@
+package engine
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/codeis4fun/data-treatment-interpreter/internal/parser"
+)
+
+// ErrorPolicy decides what ExecuteAll does when a statement without an ON ERROR clause fails
+type ErrorPolicy int
+
+const (
+	// FailFast stops at the first failing statement and returns the original document untouched
+	FailFast ErrorPolicy = iota
+	// ContinueOnError skips failing statements, returning the partially transformed document
+	// together with every error that occurred
+	ContinueOnError
+)
+
+// StatementError is returned by ExecuteAll when a statement fails
+type StatementError struct {
+	Statement int // 1-based index of the statement in the script
+	Program   *parser.Program
+	Err       error
+}
+
+// Error returns the error message prefixed by the statement that failed
+func (e *StatementError) Error() string {
+	return fmt.Sprintf("statement %d: %v", e.Statement, e.Err)
+}
+
+// Unwrap returns the underlying error
+func (e *StatementError) Unwrap() error {
+	return e.Err
+}
+
+// recoverError applies the ON ERROR clause of a program to a failed application: SKIP leaves the
+// document as it was, DEFAULT writes the default literal to the given variables. Without a clause
+// (or with ON ERROR FAIL) the error is returned
+func recoverError(program *parser.Program, state state, variables []string, err error) error {
+	switch program.OnError {
+	case parser.ErrorSkip:
+		return nil
+	case parser.ErrorDefault:
+		value, err := literalJSON(program.Default)
+		if err != nil {
+			return err
+		}
+		for _, variable := range variables {
+			if err := state.setRaw(variable, value); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return err
+}
+
+// literalJSON converts a literal as written in a script ('text' or a number) to raw JSON
+func literalJSON(literal string) ([]byte, error) {
+	if isLiteral(literal) {
+		return json.Marshal(strings.Trim(literal, "'"))
+	}
+	if !json.Valid([]byte(literal)) {
+		return nil, fmt.Errorf("invalid literal %s", literal)
+	}
+	return []byte(literal), nil
+}
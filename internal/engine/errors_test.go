@@ -0,0 +1,127 @@
+package engine_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/codeis4fun/data-treatment-interpreter/internal/engine"
+	"github.com/codeis4fun/data-treatment-interpreter/internal/parser"
+)
+
+func parse(t *testing.T, input string) []*parser.Program {
+	t.Helper()
+	programs, err := parser.Parse(input)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	return programs
+}
+
+func TestFailFastReturnsOriginalDocument(t *testing.T) {
+	jsonData := []byte(`{"name":"john","friends":[{"name":"Alice"},{"age":30}]}`)
+	programs := parse(t, `SET name = uppercase(name)
+SET friends.#.name = uppercase(friends.#.name)`)
+
+	output, err := engine.NewEngine().ExecuteAll(programs, jsonData)
+	if err == nil {
+		t.Fatalf("Expected error, got nil")
+	}
+
+	var statementError *engine.StatementError
+	if !errors.As(err, &statementError) || statementError.Statement != 2 {
+		t.Errorf("Expected a StatementError for statement 2, got %v", err)
+	}
+
+	expected := `{"name":"john","friends":[{"name":"Alice"},{"age":30}]}`
+	if string(output) != expected {
+		t.Errorf("Expected the original document %s, got %s", expected, output)
+	}
+	if string(jsonData) != expected {
+		t.Errorf("Expected the input to be left untouched, got %s", jsonData)
+	}
+}
+
+func TestContinueOnError(t *testing.T) {
+	jsonData := []byte(`{"name":"john","friends":[{"name":"Alice"},{"age":30}]}`)
+	programs := parse(t, `SET friends.#.name = uppercase(friends.#.name)
+SET name = uppercase(name)
+SET surname = uppercase(surname)`)
+
+	e := engine.NewEngine(engine.WithErrorPolicy(engine.ContinueOnError))
+	output, err := e.ExecuteAll(programs, jsonData)
+	if err == nil {
+		t.Fatalf("Expected error, got nil")
+	}
+
+	// The failing '#' statement is rolled back as a whole, so Alice is not uppercased
+	expected := `{"name":"JOHN","friends":[{"name":"Alice"},{"age":30}]}`
+	if string(output) != expected {
+		t.Errorf("Expected %s, got %s", expected, output)
+	}
+
+	for _, want := range []string{"statement 1: argument 'friends.1.name' not found in JSON", "statement 3: argument 'surname' not found in JSON"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Expected error to contain %q, got %q", want, err.Error())
+		}
+	}
+}
+
+func TestOnErrorClauses(t *testing.T) {
+	jsonData := []byte(`{"name":"john","friends":[{"name":"Alice"},{"age":30}]}`)
+
+	tests := []struct {
+		name     string
+		script   string
+		expected string
+		err      string
+	}{
+		{
+			name:     "skip",
+			script:   "SET surname = uppercase(surname) ON ERROR SKIP\nSET name = uppercase(name)",
+			expected: `{"name":"JOHN","friends":[{"name":"Alice"},{"age":30}]}`,
+		},
+		{
+			name:     "default string",
+			script:   "SET surname = uppercase(surname) ON ERROR DEFAULT 'n/a'",
+			expected: `{"name":"john","friends":[{"name":"Alice"},{"age":30}],"surname":"n/a"}`,
+		},
+		{
+			name:     "default number per array element",
+			script:   "SET friends.#.name = uppercase(friends.#.name) ON ERROR DEFAULT 0",
+			expected: `{"name":"john","friends":[{"name":"ALICE"},{"age":30,"name":0}]}`,
+		},
+		{
+			name:     "skip when the field is not an array",
+			script:   "SET name.# = uppercase(name.#) ON ERROR SKIP",
+			expected: `{"name":"john","friends":[{"name":"Alice"},{"age":30}]}`,
+		},
+		{
+			name:   "fail overrides the engine policy",
+			script: "SET surname = uppercase(surname) ON ERROR FAIL\nSET name = uppercase(name)",
+			err:    "statement 1:",
+		},
+	}
+
+	e := engine.NewEngine(engine.WithErrorPolicy(engine.ContinueOnError))
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output, err := e.ExecuteAll(parse(t, tt.script), jsonData)
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("Expected error containing %q, got %v", tt.err, err)
+				}
+				if string(output) != string(jsonData) {
+					t.Errorf("Expected the original document, got %s", output)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if string(output) != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, output)
+			}
+		})
+	}
+}
@@ -0,0 +1,74 @@
+package engine_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/codeis4fun/data-treatment-interpreter/internal/engine"
+	"github.com/codeis4fun/data-treatment-interpreter/internal/parser"
+	"github.com/codeis4fun/data-treatment-interpreter/internal/transformers"
+)
+
+// TestSignatureExamples runs every documented example so the generated reference never drifts from the engine.
+// Examples are run under both document models, which must produce the same output
+func TestSignatureExamples(t *testing.T) {
+	// A made-up key and chart for the transformers registered by options
+	keyring, err := transformers.NewKeyring(map[string][]byte{"2026-01": []byte("0123456789abcdef")})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	reference, err := transformers.ParseGrowthReference(strings.NewReader("Sex,Agemos,L,M,S\n1,24,1,16,0.1\n2,24,1,15,0.1\n"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	opts := []engine.Option{engine.WithKeyring(keyring), engine.WithGrowthReference(reference)}
+
+	t.Run("bytes", func(t *testing.T) { testSignatureExamples(t, opts...) })
+	t.Run("document", func(t *testing.T) { testSignatureExamples(t, append(opts, engine.WithDocumentModel())...) })
+}
+
+func testSignatureExamples(t *testing.T, opts ...engine.Option) {
+	for _, signature := range engine.Reference() {
+		if len(signature.Examples) == 0 {
+			t.Errorf("Transformer '%s' has no examples", signature.Name)
+		}
+
+		for _, example := range signature.Examples {
+			t.Run(signature.Name+"/"+example.Script, func(t *testing.T) {
+				programs, err := parser.Parse(example.Script)
+				if err != nil {
+					t.Fatalf("Unexpected parse error: %v", err)
+				}
+
+				// Examples reading the clock are run with it stopped at the documented time
+				e := engine.NewEngine(opts...)
+				if example.Now != "" {
+					now, err := time.Parse(time.RFC3339, example.Now)
+					if err != nil {
+						t.Fatalf("Unexpected error: %v", err)
+					}
+					e = engine.NewEngine(append(opts, engine.WithClock(func() time.Time { return now }))...)
+				}
+
+				output, err := e.ExecuteAll(programs, []byte(example.Input))
+				if example.Error != "" {
+					if err == nil {
+						t.Fatalf("Expected error containing %q, got output %s", example.Error, output)
+					}
+					if !strings.Contains(err.Error(), example.Error) {
+						t.Errorf("Expected error containing %q, got %q", example.Error, err.Error())
+					}
+					return
+				}
+
+				if err != nil {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+				if string(output) != example.Output {
+					t.Errorf("Expected %s, got %s", example.Output, string(output))
+				}
+			})
+		}
+	}
+}
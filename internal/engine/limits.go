@@ -0,0 +1,158 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/codeis4fun/data-treatment-interpreter/internal/transformers"
+)
+
+// Limits bounds the resources a script can use, so scripts written by untrusted users cannot
+// exhaust the engine. Zero values mean no limit
+type Limits struct {
+	MaxStatements   int           // Statements in a script
+	MaxIterations   int           // Array elements a single '#' statement iterates over
+	MaxOutputSize   int           // Bytes of the output document
+	MaxDepth        int           // Nesting depth of objects and arrays in the input and output documents
+	MaxStringLength int           // Bytes of any string produced by a transformer
+	Timeout         time.Duration // Wall-clock time of a whole execution
+}
+
+// Errors wrapped by LimitError, one per limit, to be matched with errors.Is
+var (
+	ErrTooManyStatements = errors.New("too many statements")
+	ErrTooManyIterations = errors.New("too many iterations")
+	ErrOutputTooLarge    = errors.New("output document too large")
+	ErrTooDeep           = errors.New("document nested too deeply")
+	ErrStringTooLong     = errors.New("string too long")
+	ErrTimeout           = errors.New("execution timed out")
+)
+
+// ErrTransformerNotAllowed is returned for scripts using a transformer left out by WithAllowedTransformers
+var ErrTransformerNotAllowed = errors.New("transformer not allowed")
+
+// LimitError is returned when a script exceeds one of the limits of the engine. Limit errors always
+// stop the script: neither ON ERROR clauses nor the ContinueOnError policy recover from them
+type LimitError struct {
+	Err    error // One of the Err* variables of this package
+	Limit  int64 // The configured limit
+	Actual int64 // The value that exceeded it, when known
+}
+
+// Error describes the limit that was exceeded
+func (e *LimitError) Error() string {
+	if errors.Is(e.Err, ErrTimeout) {
+		return fmt.Sprintf("%v: limit is %s", e.Err, time.Duration(e.Limit))
+	}
+	if e.Actual > 0 {
+		return fmt.Sprintf("%v: %d exceeds the limit of %d", e.Err, e.Actual, e.Limit)
+	}
+	return fmt.Sprintf("%v: limit is %d", e.Err, e.Limit)
+}
+
+// Unwrap returns the error of the limit that was exceeded
+func (e *LimitError) Unwrap() error {
+	return e.Err
+}
+
+// WithLimits bounds the resources used by the scripts the engine runs
+func WithLimits(limits Limits) Option {
+	return func(e *Engine) {
+		e.limits = limits
+	}
+}
+
+// WithAllowedTransformers restricts the engine to the named transformers. Scripts using any other
+// transformer are rejected before they run, and Signatures only lists the allowed ones
+func WithAllowedTransformers(names ...string) Option {
+	return func(e *Engine) {
+		e.allowed = map[string]bool{}
+		for _, name := range names {
+			e.allowed[name] = true
+		}
+	}
+}
+
+// isAllowed reports whether a transformer can be used by scripts
+func (e *Engine) isAllowed(name string) bool {
+	return e.allowed == nil || e.allowed[name]
+}
+
+// withTimeout bounds a context by the Timeout limit. When the limit expires, context.Cause returns a LimitError
+func (l Limits) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if l.Timeout <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeoutCause(ctx, l.Timeout, &LimitError{Err: ErrTimeout, Limit: int64(l.Timeout)})
+}
+
+// isLimitError reports whether an error is a LimitError
+func isLimitError(err error) bool {
+	var limitError *LimitError
+	return errors.As(err, &limitError)
+}
+
+// checkStatements checks the number of statements of a script
+func (l Limits) checkStatements(statements int) error {
+	if l.MaxStatements > 0 && statements > l.MaxStatements {
+		return &LimitError{Err: ErrTooManyStatements, Limit: int64(l.MaxStatements), Actual: int64(statements)}
+	}
+	return nil
+}
+
+// checkIterations checks the length of the array a '#' statement iterates over
+func (l Limits) checkIterations(length int) error {
+	if l.MaxIterations > 0 && length > l.MaxIterations {
+		return &LimitError{Err: ErrTooManyIterations, Limit: int64(l.MaxIterations), Actual: int64(length)}
+	}
+	return nil
+}
+
+// checkResults checks the length of the strings produced by a transformer
+func (l Limits) checkResults(results transformers.Results) error {
+	if l.MaxStringLength <= 0 {
+		return nil
+	}
+	for _, result := range results {
+		if s, ok := result.(string); ok && len(s) > l.MaxStringLength {
+			return &LimitError{Err: ErrStringTooLong, Limit: int64(l.MaxStringLength), Actual: int64(len(s))}
+		}
+	}
+	return nil
+}
+
+// checkDocument checks the size and nesting depth of a document
+func (l Limits) checkDocument(jsonData []byte, output bool) error {
+	if output && l.MaxOutputSize > 0 && len(jsonData) > l.MaxOutputSize {
+		return &LimitError{Err: ErrOutputTooLarge, Limit: int64(l.MaxOutputSize), Actual: int64(len(jsonData))}
+	}
+	if l.MaxDepth > 0 {
+		if depth := depth(jsonData); depth > l.MaxDepth {
+			return &LimitError{Err: ErrTooDeep, Limit: int64(l.MaxDepth), Actual: int64(depth)}
+		}
+	}
+	return nil
+}
+
+// depth returns the deepest nesting of objects and arrays in a JSON document, ignoring brackets inside strings
+func depth(jsonData []byte) int {
+	deepest, current := 0, 0
+	inString := false
+	for i := 0; i < len(jsonData); i++ {
+		switch c := jsonData[i]; {
+		case inString && c == '\\':
+			i++
+		case c == '"':
+			inString = !inString
+		case inString:
+		case c == '{' || c == '[':
+			current++
+			deepest = max(deepest, current)
+		case c == '}' || c == ']':
+			current--
+		}
+	}
+	return deepest
+}
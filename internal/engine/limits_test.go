@@ -0,0 +1,156 @@
+package engine_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/codeis4fun/data-treatment-interpreter/internal/engine"
+	"github.com/codeis4fun/data-treatment-interpreter/internal/transformers"
+)
+
+func TestLimits(t *testing.T) {
+	jsonData := []byte(`{"name":"john","surname":"doe","items":[{"a":"x"},{"a":"y"},{"a":"z"}]}`)
+
+	tests := []struct {
+		name     string
+		limits   engine.Limits
+		script   string
+		opts     []engine.Option
+		expected error
+	}{
+		{
+			name:     "statements",
+			limits:   engine.Limits{MaxStatements: 1},
+			script:   "SET a = uppercase(name)\nSET b = uppercase(surname)",
+			expected: engine.ErrTooManyStatements,
+		},
+		{
+			name:     "iterations",
+			limits:   engine.Limits{MaxIterations: 2},
+			script:   "SET items.#.a = uppercase(items.#.a)",
+			expected: engine.ErrTooManyIterations,
+		},
+		{
+			name:     "output size",
+			limits:   engine.Limits{MaxOutputSize: 80},
+			script:   "SET fullName = concatenate(' ', name, surname)",
+			expected: engine.ErrOutputTooLarge,
+		},
+		{
+			name:     "depth",
+			limits:   engine.Limits{MaxDepth: 2},
+			script:   "SET a.b.c = uppercase(name)",
+			expected: engine.ErrTooDeep,
+		},
+		{
+			name:     "string length",
+			limits:   engine.Limits{MaxStringLength: 7},
+			script:   "SET fullName = concatenate(' ', name, surname)",
+			expected: engine.ErrStringTooLong,
+		},
+		{
+			name:     "string length with ON ERROR and ContinueOnError",
+			limits:   engine.Limits{MaxStringLength: 7},
+			script:   "SET fullName = concatenate(' ', name, surname) ON ERROR SKIP",
+			opts:     []engine.Option{engine.WithErrorPolicy(engine.ContinueOnError)},
+			expected: engine.ErrStringTooLong,
+		},
+		{
+			name:     "string length with the document model",
+			limits:   engine.Limits{MaxStringLength: 7},
+			script:   "SET fullName = concatenate(' ', name, surname)",
+			opts:     []engine.Option{engine.WithDocumentModel()},
+			expected: engine.ErrStringTooLong,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := engine.NewEngine(append(tt.opts, engine.WithLimits(tt.limits))...)
+			output, err := e.ExecuteAll(parse(t, tt.script), jsonData)
+			if !errors.Is(err, tt.expected) {
+				t.Fatalf("Expected %v, got %v", tt.expected, err)
+			}
+
+			var limitError *engine.LimitError
+			if !errors.As(err, &limitError) {
+				t.Errorf("Expected a LimitError, got %T", err)
+			}
+			if string(output) != string(jsonData) {
+				t.Errorf("Expected the original document %s, got %s", jsonData, output)
+			}
+
+			// Dry runs are bound by the same limits
+			if _, _, err := e.DryRun(parse(t, tt.script), jsonData); !errors.Is(err, tt.expected) {
+				t.Errorf("Expected %v from the dry run, got %v", tt.expected, err)
+			}
+		})
+	}
+}
+
+func TestLimitsWithinBounds(t *testing.T) {
+	e := engine.NewEngine(engine.WithLimits(engine.Limits{
+		MaxStatements:   2,
+		MaxIterations:   3,
+		MaxOutputSize:   1000,
+		MaxDepth:        3,
+		MaxStringLength: 8,
+		Timeout:         time.Minute,
+	}))
+
+	_, err := e.ExecuteAll(parse(t, "SET fullName = concatenate(' ', name, surname)\nSET items.#.a = uppercase(items.#.a)"), []byte(`{"name":"john","surname":"doe","items":[{"a":"x"},{"a":"y"},{"a":"z"}]}`))
+	if err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
+
+// sleeper is a transformer that takes longer than the timeout of the test
+type sleeper struct{}
+
+func (sleeper) Transform(ctx context.Context) (transformers.Results, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-time.After(time.Second):
+		return transformers.Results{"done"}, nil
+	}
+}
+
+func TestTimeoutLimit(t *testing.T) {
+	e := engine.NewEngine(engine.WithLimits(engine.Limits{Timeout: 10 * time.Millisecond}))
+	e.Register(engine.Signature{Name: "sleep", Outputs: []string{"result"}}, func(transformers.Config) engine.Transformer { return sleeper{} })
+
+	_, err := e.ExecuteAll(parse(t, "SET a = sleep() ON ERROR SKIP"), []byte(`{}`))
+	if !errors.Is(err, engine.ErrTimeout) {
+		t.Errorf("Expected %v, got %v", engine.ErrTimeout, err)
+	}
+
+	_, _, err = e.DryRun(parse(t, "SET a = sleep() ON ERROR SKIP"), []byte(`{}`))
+	if !errors.Is(err, engine.ErrTimeout) {
+		t.Errorf("Expected %v from the dry run, got %v", engine.ErrTimeout, err)
+	}
+}
+
+func TestAllowedTransformers(t *testing.T) {
+	e := engine.NewEngine(engine.WithAllowedTransformers("uppercase", "concatenate"))
+
+	if _, err := e.ExecuteAll(parse(t, "SET a = uppercase(name)"), []byte(`{"name":"john"}`)); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+
+	_, err := e.ExecuteAll(parse(t, "SET a, b = split(name, '/')"), []byte(`{"name":"john/doe"}`))
+	if !errors.Is(err, engine.ErrTransformerNotAllowed) {
+		t.Errorf("Expected %v, got %v", engine.ErrTransformerNotAllowed, err)
+	}
+
+	var names []string
+	for _, signature := range e.Signatures() {
+		names = append(names, signature.Name)
+	}
+	if !reflect.DeepEqual(names, []string{"concatenate", "uppercase"}) {
+		t.Errorf("Expected [concatenate uppercase], got %v", names)
+	}
+}
@@ -0,0 +1,18 @@
+package engine
+
+// MissingPolicy decides what a statement does when one of its optional arguments (field?) is missing
+type MissingPolicy int
+
+const (
+	// SkipMissing leaves the variables of the statement untouched
+	SkipMissing MissingPolicy = iota
+	// NullMissing writes null to the variables of the statement
+	NullMissing
+)
+
+// WithMissingPolicy sets what a statement does when one of its optional arguments is missing or null
+func WithMissingPolicy(policy MissingPolicy) Option {
+	return func(e *Engine) {
+		e.missingPolicy = policy
+	}
+}
@@ -0,0 +1,90 @@
+package engine_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/codeis4fun/data-treatment-interpreter/internal/engine"
+)
+
+func TestOptionalArguments(t *testing.T) {
+	jsonData := []byte(`{"name":"john","nickname":null,"friends":[{"name":"alice","nick":"al"},{"name":"bob"}]}`)
+	script := `SET middleName = uppercase(middleName?)
+SET nickname = uppercase(nickname?)
+SET friends.#.nick = uppercase(friends.#.nick?)
+SET friends.#.fullName = concatenate(' ', friends.#.name, friends.#.nick?)`
+
+	tests := []struct {
+		name     string
+		policy   engine.MissingPolicy
+		expected string
+	}{
+		{
+			name:     "skip",
+			policy:   engine.SkipMissing,
+			expected: `{"name":"john","nickname":null,"friends":[{"name":"alice","nick":"AL","fullName":"alice AL"},{"name":"bob"}]}`,
+		},
+		{
+			name:     "null",
+			policy:   engine.NullMissing,
+			expected: `{"name":"john","nickname":null,"friends":[{"name":"alice","nick":"AL","fullName":"alice AL"},{"name":"bob","nick":null,"fullName":null}],"middleName":null}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output, err := engine.NewEngine(engine.WithMissingPolicy(tt.policy)).ExecuteAll(parse(t, script), jsonData)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if string(output) != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, output)
+			}
+		})
+	}
+}
+
+func TestRedactSkipsMissingFields(t *testing.T) {
+	jsonData := []byte(`{"name":"john","friends":[{"name":"alice","ssn":"123-45-6789"},{"name":"bob"}]}`)
+	script := `REDACT ssn
+REDACT friends.#.ssn`
+	expected := `{"name":"john","friends":[{"name":"alice","ssn":"[REDACTED]"},{"name":"bob"}]}`
+
+	for _, policy := range []engine.MissingPolicy{engine.SkipMissing, engine.NullMissing} {
+		output, err := engine.NewEngine(engine.WithMissingPolicy(policy)).ExecuteAll(parse(t, script), jsonData)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if string(output) != expected {
+			t.Errorf("Expected %s, got %s", expected, output)
+		}
+	}
+}
+
+func TestOptionalArgumentsAreTraced(t *testing.T) {
+	recorder := &engine.Recorder{}
+	e := engine.NewEngine(engine.WithTracer(recorder))
+	if _, err := e.ExecuteAll(parse(t, `SET name = uppercase(middleName?)`), []byte(`{"name":"john"}`)); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	events := recorder.Events()
+	if len(events) != 1 || !events[0].Skipped {
+		t.Fatalf("Expected 1 skipped event, got %v", events)
+	}
+	if events[0].Args[0].Name != "middleName" {
+		t.Errorf("Expected argument middleName, got %s", events[0].Args[0].Name)
+	}
+}
+
+func TestOptionalVariableIsRejected(t *testing.T) {
+	err := engine.NewEngine().Vet(parse(t, `SET name? = uppercase(name)`))
+	if err == nil {
+		t.Fatalf("Expected error, got nil")
+	}
+
+	expected := "variable 'name?' cannot be optional"
+	if !strings.Contains(err.Error(), expected) {
+		t.Errorf("Expected error containing %q, got %q", expected, err.Error())
+	}
+}
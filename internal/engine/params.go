@@ -0,0 +1,73 @@
+package engine
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/codeis4fun/data-treatment-interpreter/internal/parser"
+	"github.com/tidwall/gjson"
+)
+
+// ExecOption configures a single execution of a script
+type ExecOption func(*execution)
+
+// execution holds the settings of a single execution of a script
+type execution struct {
+	params map[string]any
+	env    *Environment // LET variables kept across executions, nil when they only last for one
+}
+
+// WithParams supplies the values of the $parameters of a script, keyed by name without the '$'.
+// Values are encoded as JSON, so pass json.Number for numbers that must not go through a float64
+func WithParams(params map[string]any) ExecOption {
+	return func(x *execution) {
+		x.params = params
+	}
+}
+
+// newExecution applies the options of an execution
+func newExecution(opts []ExecOption) *execution {
+	x := &execution{}
+	for _, opt := range opts {
+		opt(x)
+	}
+	return x
+}
+
+// values encodes the parameters referenced by the programs as JSON values, reporting every
+// statement that references a parameter that was not supplied
+func (x *execution) values(programs []*parser.Program) (map[string]gjson.Result, error) {
+	var errs []error
+	values := map[string]gjson.Result{}
+	for i, program := range programs {
+		for _, arg := range program.Args {
+			if !isParameter(arg) {
+				continue
+			}
+			name := arg[1:]
+			if _, ok := values[name]; ok {
+				continue
+			}
+
+			value, ok := x.params[name]
+			if !ok {
+				errs = append(errs, fmt.Errorf("statement %d: parameter '%s' is not declared", i+1, arg))
+				continue
+			}
+			raw, err := json.Marshal(value)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("statement %d: parameter '%s': %w", i+1, arg, err))
+				continue
+			}
+			values[name] = gjson.ParseBytes(raw)
+		}
+	}
+	return values, errors.Join(errs...)
+}
+
+// isParameter reports whether an argument is a $parameter reference
+func isParameter(arg string) bool {
+	return strings.HasPrefix(arg, "$")
+}
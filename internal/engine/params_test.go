@@ -0,0 +1,95 @@
+package engine_test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/codeis4fun/data-treatment-interpreter/internal/engine"
+)
+
+func TestParams(t *testing.T) {
+	jsonData := []byte(`{"id":"42","items":[{"sku":"a"},{"sku":"b"}]}`)
+	script := `SET key = concatenate($separator, $tenant, id)
+SET items.#.key = concatenate('/', $country, items.#.sku)
+SET limit = coalesce(limit, $limit)
+SET region = uppercase($country)`
+
+	params := map[string]any{"tenant": "acme", "separator": ":", "country": "pt", "limit": 10}
+
+	for _, opts := range [][]engine.Option{nil, {engine.WithDocumentModel()}} {
+		output, err := engine.NewEngine(opts...).ExecuteAll(parse(t, script), jsonData, engine.WithParams(params))
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		expected := `{"id":"42","items":[{"sku":"a","key":"pt/a"},{"sku":"b","key":"pt/b"}],"key":"acme:42","limit":10,"region":"PT"}`
+		if string(output) != expected {
+			t.Errorf("Expected %s, got %s", expected, output)
+		}
+	}
+}
+
+func TestParamsWithLargeNumbers(t *testing.T) {
+	params := map[string]any{"tenant": json.Number("12345678901234567890"), "shard": json.Number("9007199254740993")}
+	script := "SET tenant = coalesce(tenant, $tenant)\nSET shard = coalesce(shard, $shard)"
+
+	for _, opts := range [][]engine.Option{nil, {engine.WithDocumentModel()}} {
+		output, err := engine.NewEngine(opts...).ExecuteAll(parse(t, script), []byte(`{}`), engine.WithParams(params))
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		expected := `{"tenant":12345678901234567890,"shard":9007199254740993}`
+		if string(output) != expected {
+			t.Errorf("Expected %s, got %s", expected, output)
+		}
+	}
+}
+
+func TestPlanWithDifferentParams(t *testing.T) {
+	plan, err := engine.NewEngine().Compile(parse(t, `SET tenant = uppercase($tenant)`))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	for _, tenant := range []string{"acme", "globex"} {
+		output, err := plan.Execute([]byte(`{}`), engine.WithParams(map[string]any{"tenant": tenant}))
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		expected := `{"tenant":"` + strings.ToUpper(tenant) + `"}`
+		if string(output) != expected {
+			t.Errorf("Expected %s, got %s", expected, output)
+		}
+	}
+}
+
+func TestUndeclaredParams(t *testing.T) {
+	programs := parse(t, `SET a = uppercase($tenant)
+SET b = concatenate($separator, a, $tenant)`)
+	e := engine.NewEngine()
+
+	err := e.Vet(programs, engine.WithParams(map[string]any{"tenant": "acme"}))
+	if err == nil {
+		t.Fatalf("Expected error, got nil")
+	}
+	expected := "statement 2: parameter '$separator' is not declared"
+	if err.Error() != expected {
+		t.Errorf("Expected %q, got %q", expected, err.Error())
+	}
+
+	jsonData := []byte(`{}`)
+	output, err := e.ExecuteAll(programs, jsonData)
+	if err == nil {
+		t.Fatalf("Expected error, got nil")
+	}
+	for _, want := range []string{"statement 1: parameter '$tenant' is not declared", "statement 2: parameter '$separator' is not declared"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Expected error containing %q, got %q", want, err.Error())
+		}
+	}
+	if string(output) != string(jsonData) {
+		t.Errorf("Expected the original document, got %s", output)
+	}
+}
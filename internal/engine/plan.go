@@ -0,0 +1,246 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/codeis4fun/data-treatment-interpreter/internal/parser"
+	"github.com/codeis4fun/data-treatment-interpreter/internal/transformers"
+	"github.com/tidwall/gjson"
+)
+
+// Plan is a compiled script: every statement has its transformer resolved and its paths split
+// around the '#' placeholder, so nothing is looked up or scanned again when it runs. A Plan is
+// immutable and safe to execute concurrently, so it can be compiled once and reused for every record
+type Plan struct {
+	engine      *Engine
+	statements  []*statement
+	temporaries []string        // Temporary variables deleted at the end of Execute
+	lets        map[string]bool // Variables assigned by LET statements, kept out of the document
+	patterns    *transformers.Patterns
+}
+
+// statement is a compiled program
+type statement struct {
+	index      int // 1-based index of the statement in the script
+	program    *parser.Program
+	factory    Factory
+	patterns   *transformers.Patterns // Regular expressions of the script, shared by its statements
+	arrayField string                 // Array iterated over by '#' statements, empty otherwise
+	args       []path
+	variables  []path
+
+	// Paths of statements without '#', which are the same on every run
+	argNames      []string
+	variableNames []string
+}
+
+// path is an argument or variable of a statement
+type path struct {
+	name     string // As passed to transformers: literals keep their quotes, optional fields lose their '?'
+	optional bool
+	secret   bool // $parameters and the arguments of secret parameters are redacted from traces
+	hash     int  // Position of the '#' placeholder, -1 when there is none (or for literals and parameters)
+}
+
+// newPath splits an argument or variable as written in the script
+func newPath(arg string) path {
+	if isLiteral(arg) || isParameter(arg) {
+		return path{name: arg, hash: -1}
+	}
+	p := path{name: strings.TrimSuffix(arg, "?"), optional: strings.HasSuffix(arg, "?")}
+	p.hash = strings.Index(p.name, "#")
+	return p
+}
+
+// at returns the path for an element of the array iterated over (e.g. "friends.#.first" -> "friends.0.first").
+// Without an index the path is returned as written
+func (p path) at(index string) string {
+	if p.hash < 0 || index == "" {
+		return p.name
+	}
+	return p.name[:p.hash] + index + p.name[p.hash+1:]
+}
+
+// Compile checks the programs against the registered signatures and compiles them into a Plan.
+// Parameters are checked when the plan is executed, so one plan can run with different parameters
+func (e *Engine) Compile(programs []*parser.Program) (*Plan, error) {
+	if err := e.limits.checkStatements(len(programs)); err != nil {
+		return nil, err
+	}
+	if err := e.vet(programs); err != nil {
+		return nil, err
+	}
+
+	plan := &Plan{engine: e, statements: make([]*statement, 0, len(programs)), lets: letNames(programs), patterns: transformers.NewPatterns()}
+	for i, program := range programs {
+		plan.statements = append(plan.statements, e.compile(i+1, program, plan.patterns))
+		for _, variable := range program.Variables {
+			if !program.Let && e.isTemporary(variable) {
+				plan.temporaries = append(plan.temporaries, variable)
+			}
+		}
+	}
+	return plan, nil
+}
+
+// compile resolves the transformer and paths of a program that has already been vetted, and compiles its
+// literal regular expressions into the patterns of the script
+func (e *Engine) compile(index int, program *parser.Program, patterns *transformers.Patterns) *statement {
+	registration := e.transformers[program.Transformer]
+	s := &statement{
+		index:    index,
+		program:  program,
+		factory:  registration.factory,
+		patterns: patterns,
+	}
+	for i, arg := range program.Args {
+		p := newPath(arg)
+		param, _ := registration.signature.param(i)
+		p.secret = param.Secret || isParameter(arg)
+		s.args = append(s.args, p)
+		if param.Type == "regex" && isLiteral(arg) {
+			patterns.Add(strings.Trim(arg, "'")) // Vetted already, so it compiles
+		}
+	}
+	for _, variable := range program.Variables {
+		s.variables = append(s.variables, newPath(variable))
+	}
+
+	// Statements whose first variable contains '#' iterate over the array before it (e.g. "friends.#.first" -> "friends")
+	if hash := s.variables[0].hash; hash > 0 {
+		s.arrayField = s.variables[0].name[:hash-1]
+	} else {
+		s.argNames = names(s.args, "")
+		s.variableNames = names(s.variables, "")
+	}
+	return s
+}
+
+// Execute applies the plan to a JSON document like ExecuteAll. The input is never modified: when the
+// script fails under the FailFast policy, the original document is returned along with the error
+func (p *Plan) Execute(jsonData []byte, opts ...ExecOption) ([]byte, error) {
+	return p.ExecuteContext(context.Background(), jsonData, opts...)
+}
+
+// ExecuteContext is Execute with a context. The context is checked before each statement and, in '#'
+// statements, before each array element. Once it is done, or once a limit of the engine is exceeded,
+// the original document is returned together with the error, whatever the error policy and ON ERROR
+// clauses say
+func (p *Plan) ExecuteContext(ctx context.Context, jsonData []byte, opts ...ExecOption) ([]byte, error) {
+	x := newExecution(opts)
+	params, err := x.values(p.programs())
+	if err != nil {
+		return jsonData, err
+	}
+
+	limits := p.engine.limits
+	if err := limits.checkDocument(jsonData, false); err != nil {
+		return jsonData, err
+	}
+
+	ctx, cancel := limits.withTimeout(ctx)
+	defer cancel()
+
+	saved, err := x.saveEnvironment(p.programs())
+	if err != nil {
+		return jsonData, err
+	}
+	fail := func(err error) ([]byte, error) {
+		x.restoreEnvironment(saved)
+		return jsonData, err
+	}
+
+	var errs []error
+	state := withEnvironment(p.engine.newState(jsonData), p.lets, x.env)
+	for _, statement := range p.statements {
+		if ctx.Err() != nil {
+			return fail(context.Cause(ctx))
+		}
+
+		// A failing statement is rolled back as a whole, so '#' statements never leave a half-updated array behind
+		mark := state.mark()
+		if err := p.engine.run(ctx, statement, state, params); err != nil {
+			if ctx.Err() != nil {
+				err = context.Cause(ctx)
+			}
+			fatal := ctx.Err() != nil || isLimitError(err)
+			err = &StatementError{Statement: statement.index, Program: statement.program, Err: err}
+			if p.engine.errorPolicy == FailFast || statement.program.OnError == parser.ErrorFail || fatal {
+				return fail(err)
+			}
+			state.rollback(mark)
+			errs = append(errs, err)
+		}
+		state.commit()
+	}
+
+	if err := p.deleteTemporaries(state); err != nil {
+		return fail(err)
+	}
+	output := state.bytes()
+	if err := limits.checkDocument(output, true); err != nil {
+		return fail(err)
+	}
+	return output, errors.Join(errs...)
+}
+
+// programs returns the programs the plan was compiled from
+func (p *Plan) programs() []*parser.Program {
+	programs := make([]*parser.Program, len(p.statements))
+	for i, statement := range p.statements {
+		programs[i] = statement.program
+	}
+	return programs
+}
+
+// deleteTemporaries deletes the temporary variables (those starting with the temporary prefix) from the document
+func (p *Plan) deleteTemporaries(state state) error {
+	for _, variable := range p.temporaries {
+		if err := state.delete(variable); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// run applies a compiled statement, once or once per element of the array it iterates over
+func (e *Engine) run(ctx context.Context, s *statement, state state, params map[string]gjson.Result) error {
+	if s.arrayField == "" {
+		return e.apply(ctx, s, step{statement: s.index, iteration: -1}, s.argNames, s.variableNames, state, params)
+	}
+
+	array := state.get(s.arrayField)
+	if !array.IsArray() {
+		return recoverError(s.program, state, nil, fmt.Errorf("field '%s' is not an array", s.arrayField))
+	}
+
+	// The array can be rewritten by each iteration, but its length is fixed by the one in the input
+	length := int(array.Get("#").Int())
+	if err := e.limits.checkIterations(length); err != nil {
+		return err
+	}
+	for i := 0; i < length; i++ {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		index := strconv.Itoa(i)
+		if err := e.apply(ctx, s, step{statement: s.index, iteration: i}, names(s.args, index), names(s.variables, index), state, params); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// names returns the paths of an application, replacing '#' with the index of the element when iterating
+func names(paths []path, index string) []string {
+	names := make([]string, len(paths))
+	for i, p := range paths {
+		names[i] = p.at(index)
+	}
+	return names
+}
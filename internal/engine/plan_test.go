@@ -0,0 +1,113 @@
+package engine_test
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/codeis4fun/data-treatment-interpreter/internal/engine"
+)
+
+func TestCompile(t *testing.T) {
+	e := engine.NewEngine()
+	plan, err := e.Compile(parse(t, `SET _fullName = concatenate(' ', name, surname)
+SET fullName = uppercase(_fullName)
+SET friends.#.name = uppercase(friends.#.name)`))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{input: `{"name":"john","surname":"doe","friends":[{"name":"alice"}]}`, expected: `{"name":"john","surname":"doe","friends":[{"name":"ALICE"}],"fullName":"JOHN DOE"}`},
+		{input: `{"name":"jane","surname":"roe","friends":[]}`, expected: `{"name":"jane","surname":"roe","friends":[],"fullName":"JANE ROE"}`},
+	}
+
+	for _, tt := range tests {
+		output, err := plan.Execute([]byte(tt.input))
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if string(output) != tt.expected {
+			t.Errorf("Expected %s, got %s", tt.expected, output)
+		}
+	}
+}
+
+func TestCompileRejectsInvalidScript(t *testing.T) {
+	_, err := engine.NewEngine().Compile(parse(t, `SET name = camelcase(name)`))
+	if err == nil {
+		t.Fatalf("Expected error, got nil")
+	}
+
+	expected := "statement 1: transformer 'camelcase' not found"
+	if !strings.Contains(err.Error(), expected) {
+		t.Errorf("Expected error containing %q, got %q", expected, err.Error())
+	}
+}
+
+func TestPlanIsSafeForConcurrentUse(t *testing.T) {
+	plan, err := engine.NewEngine(engine.WithTracer(&engine.Recorder{})).Compile(parse(t, `SET friends.#.name = uppercase(friends.#.name)
+SET fullName = concatenate(' ', name, surname)`))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			input := fmt.Sprintf(`{"name":"john%d","surname":"doe","friends":[{"name":"alice%d"}]}`, i, i)
+			expected := fmt.Sprintf(`{"name":"john%d","surname":"doe","friends":[{"name":"ALICE%d"}],"fullName":"john%d doe"}`, i, i, i)
+
+			output, err := plan.Execute([]byte(input))
+			if err != nil {
+				t.Errorf("Unexpected error: %v", err)
+				return
+			}
+			if string(output) != expected {
+				t.Errorf("Expected %s, got %s", expected, output)
+			}
+		}(i)
+	}
+	wg.Wait()
+}
+
+func TestPlanWithPatterns(t *testing.T) {
+	plan, err := engine.NewEngine().Compile(parse(t, `SET isJohn = match(name, '^john')
+SET masked = regexreplace(name, $pattern, '#')`))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// Literal patterns are compiled with the plan, patterns from parameters on first use; both are shared by concurrent executions
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			pattern := fmt.Sprintf("%d+", i%5)
+			input := fmt.Sprintf(`{"name":"john%d%d"}`, i%5, i%5)
+			expected := fmt.Sprintf(`{"name":"john%d%d","isJohn":true,"masked":"john#"}`, i%5, i%5)
+
+			output, err := plan.Execute([]byte(input), engine.WithParams(map[string]any{"pattern": pattern}))
+			if err != nil {
+				t.Errorf("Unexpected error: %v", err)
+				return
+			}
+			if string(output) != expected {
+				t.Errorf("Expected %s, got %s", expected, output)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	_, err = plan.Execute([]byte(`{"name":"john"}`), engine.WithParams(map[string]any{"pattern": "("}))
+	if err == nil || !strings.Contains(err.Error(), "invalid regular expression '('") {
+		t.Errorf("Expected an invalid regular expression error, got %v", err)
+	}
+}
@@ -0,0 +1,232 @@
+package engine
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/codeis4fun/data-treatment-interpreter/internal/parser"
+	"github.com/codeis4fun/data-treatment-interpreter/internal/transformers"
+)
+
+// ParamKind describes how an argument must be written in a script
+type ParamKind string
+
+const (
+	FieldParam   ParamKind = "field"   // A path in the input JSON (e.g. friends.0.name)
+	LiteralParam ParamKind = "literal" // A quoted string (e.g. ' ')
+	AnyParam     ParamKind = "any"     // Either a field or a literal
+)
+
+// Param describes a single parameter of a transformer
+type Param struct {
+	Name     string    `json:"name"`
+	Kind     ParamKind `json:"kind"`
+	Type     string    `json:"type"`               // Expected JSON type of the value: string, number, integer, bool or any; regex for literal regular expressions
+	Optional bool      `json:"optional,omitempty"` // Optional parameters may only be followed by other optional parameters
+	Secret   bool      `json:"secret,omitempty"`   // Secret values, such as keys, are redacted from traces
+}
+
+// Example shows a transformer applied to a sample document. Examples are executed as tests,
+// so Output must match the engine byte for byte, or Error must be contained in the returned error
+type Example struct {
+	Script string `json:"script"`
+	Input  string `json:"input"`
+	Output string `json:"output,omitempty"`
+	Error  string `json:"error,omitempty"`
+	Now    string `json:"now,omitempty"`  // Time returned by the clock while the example runs, in RFC 3339
+	Note   string `json:"note,omitempty"` // What the output depends on besides the input, such as the chart of the example
+}
+
+// Signature describes the parameters and outputs of a transformer
+type Signature struct {
+	Name            string    `json:"name"`
+	Description     string    `json:"description"`
+	Params          []Param   `json:"params"`
+	Variadic        bool      `json:"variadic,omitempty"`        // The last parameter may be repeated
+	Outputs         []string  `json:"outputs"`                   // Names of the values returned by the transformer, in order
+	VariadicOutputs bool      `json:"variadicOutputs,omitempty"` // The number of outputs depends on the data (e.g. split)
+	Examples        []Example `json:"examples,omitempty"`
+	Requires        string    `json:"requires,omitempty"` // The option registering a transformer that engines lack by default (e.g. "engine.WithKeyring (--keyring)")
+}
+
+// MinArgs returns the minimum number of arguments accepted by the transformer
+func (s Signature) MinArgs() int {
+	count := 0
+	for _, param := range s.Params {
+		if !param.Optional {
+			count++
+		}
+	}
+	return count
+}
+
+// MaxArgs returns the maximum number of arguments accepted by the transformer, or -1 when unbounded
+func (s Signature) MaxArgs() int {
+	if s.Variadic {
+		return -1
+	}
+	return len(s.Params)
+}
+
+// param returns the parameter that describes the argument at the given position
+func (s Signature) param(i int) (Param, bool) {
+	if i < len(s.Params) {
+		return s.Params[i], true
+	}
+	if s.Variadic && len(s.Params) > 0 {
+		return s.Params[len(s.Params)-1], true
+	}
+	return Param{}, false
+}
+
+// String renders the signature in script syntax (e.g. "bmi, isHealthy = bmi(weight, height)")
+func (s Signature) String() string {
+	var params []string
+	for i, param := range s.Params {
+		name := param.Name
+		if param.Kind == LiteralParam {
+			name = "'" + name + "'"
+		}
+		if s.Variadic && i == len(s.Params)-1 {
+			name += "..."
+		}
+		if param.Optional {
+			name = "[" + name + "]"
+		}
+		params = append(params, name)
+	}
+	outputs := strings.Join(s.Outputs, ", ")
+	if s.VariadicOutputs {
+		outputs += "..."
+	}
+	return fmt.Sprintf("%s = %s(%s)", outputs, s.Name, strings.Join(params, ", "))
+}
+
+// check validates the arity, argument kinds, literal types and number of outputs of a program against the signature
+func (s Signature) check(program *parser.Program) error {
+	args := len(program.Args)
+	if args < s.MinArgs() || (s.MaxArgs() >= 0 && args > s.MaxArgs()) {
+		return fmt.Errorf("transformer '%s' expects %s, got %d", s.Name, s.arity(), args)
+	}
+
+	for i, arg := range program.Args {
+		param, _ := s.param(i)
+		literal := isLiteral(arg)
+		if param.Kind == FieldParam && literal {
+			return fmt.Errorf("argument %d of '%s' (%s) must be a field, got literal %s", i+1, s.Name, param.Name, arg)
+		}
+		if param.Kind == LiteralParam && !literal && !isParameter(arg) {
+			return fmt.Errorf("argument %d of '%s' (%s) must be a literal, got field '%s'", i+1, s.Name, param.Name, arg)
+		}
+		if literal {
+			if err := checkLiteral(param.Type, strings.Trim(arg, "'")); err != nil {
+				return fmt.Errorf("argument %d of '%s' (%s) %w", i+1, s.Name, param.Name, err)
+			}
+		}
+	}
+
+	outputs := len(program.Variables)
+	if !s.VariadicOutputs && outputs != len(s.Outputs) {
+		return fmt.Errorf("transformer '%s' returns %d output(s) (%s), got %d variable(s)", s.Name, len(s.Outputs), strings.Join(s.Outputs, ", "), outputs)
+	}
+	return nil
+}
+
+// checkLiteral checks the text of a literal against the type of its parameter, reading it as the transformers do
+func checkLiteral(typ, text string) error {
+	switch typ {
+	case "number":
+		if _, err := transformers.ParseDecimal(text); err != nil {
+			return fmt.Errorf("must be a number, got '%s'", text)
+		}
+	case "integer":
+		if _, err := strconv.Atoi(strings.TrimSpace(text)); err != nil {
+			return fmt.Errorf("must be an integer, got '%s'", text)
+		}
+	case "bool":
+		if text != "true" && text != "false" {
+			return fmt.Errorf("must be true or false, got '%s'", text)
+		}
+	case "regex":
+		if _, err := transformers.Pattern(text); err != nil {
+			return fmt.Errorf("is not a valid regular expression: %v", errors.Unwrap(err))
+		}
+	}
+	return nil
+}
+
+// arity describes the accepted number of arguments for error messages
+func (s Signature) arity() string {
+	least, most := s.MinArgs(), s.MaxArgs()
+	switch {
+	case most < 0:
+		return fmt.Sprintf("at least %d argument(s)", least)
+	case least == most:
+		return fmt.Sprintf("exactly %d argument(s)", least)
+	default:
+		return fmt.Sprintf("between %d and %d arguments", least, most)
+	}
+}
+
+// isLiteral reports whether an argument is a quoted string rather than a field path
+func isLiteral(arg string) bool {
+	return strings.HasPrefix(arg, "'")
+}
+
+// Vet checks every program against the registered transformer signatures without touching any JSON,
+// and reports the $parameters that are referenced but not supplied with WithParams
+func (e *Engine) Vet(programs []*parser.Program, opts ...ExecOption) error {
+	_, err := newExecution(opts).values(programs)
+	return errors.Join(e.vet(programs), err)
+}
+
+// vet checks every program against the registered transformer signatures
+func (e *Engine) vet(programs []*parser.Program) error {
+	var errs []error
+	lets := letNames(programs)
+	for i, program := range programs {
+		if err := e.vetProgram(program); err != nil {
+			errs = append(errs, fmt.Errorf("statement %d: %w", i+1, err))
+		}
+		if program.Let {
+			continue
+		}
+		// A name assigned by LET refers to the environment throughout the script, so SET cannot write it
+		for _, variable := range program.Variables {
+			if name, _ := root(variable); lets[name] {
+				errs = append(errs, fmt.Errorf("statement %d: variable '%s' is declared with LET and cannot be assigned with SET", i+1, variable))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// vetProgram checks a single program against the signature of its transformer
+func (e *Engine) vetProgram(program *parser.Program) error {
+	registration, ok := e.transformers[program.Transformer]
+	if !ok {
+		for _, signature := range optionalSignatures {
+			if signature.Name == program.Transformer {
+				return fmt.Errorf("transformer '%s' not found: it is only registered by %s", program.Transformer, signature.Requires)
+			}
+		}
+		return fmt.Errorf("transformer '%s' not found", program.Transformer)
+	}
+	if !e.isAllowed(program.Transformer) {
+		return fmt.Errorf("%w: '%s'", ErrTransformerNotAllowed, program.Transformer)
+	}
+	if len(program.Variables) == 0 {
+		return fmt.Errorf("transformer '%s' has no variables to assign", program.Transformer)
+	}
+	for _, variable := range program.Variables {
+		if strings.HasSuffix(variable, "?") {
+			return fmt.Errorf("variable '%s' cannot be optional", variable)
+		}
+		if program.Let && !isName(variable) {
+			return fmt.Errorf("LET variable '%s' must be a plain name (letters, digits and underscores)", variable)
+		}
+	}
+	return registration.signature.check(program)
+}
@@ -0,0 +1,220 @@
+package engine_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/codeis4fun/data-treatment-interpreter/internal/engine"
+	"github.com/codeis4fun/data-treatment-interpreter/internal/parser"
+	"github.com/codeis4fun/data-treatment-interpreter/internal/transformers"
+)
+
+func TestVet(t *testing.T) {
+	tests := []struct {
+		name    string
+		program *parser.Program
+		err     string
+	}{
+		{
+			name:    "valid program",
+			program: &parser.Program{Variables: []string{"name"}, Transformer: "uppercase", Args: []string{"name"}},
+		},
+		{
+			name:    "variadic arguments",
+			program: &parser.Program{Variables: []string{"name"}, Transformer: "concatenate", Args: []string{"' '", "a", "b", "c"}},
+		},
+		{
+			name:    "variable number of outputs",
+			program: &parser.Program{Variables: []string{"a", "b", "c"}, Transformer: "split", Args: []string{"place", "'/'"}},
+		},
+		{
+			name:    "unknown transformer",
+			program: &parser.Program{Variables: []string{"name"}, Transformer: "nonExistent", Args: []string{"name"}},
+			err:     "transformer 'nonExistent' not found",
+		},
+		{
+			name:    "too many arguments",
+			program: &parser.Program{Variables: []string{"name"}, Transformer: "uppercase", Args: []string{"name", "surname"}},
+			err:     "transformer 'uppercase' expects exactly 1 argument(s), got 2",
+		},
+		{
+			name:    "too few arguments",
+			program: &parser.Program{Variables: []string{"name"}, Transformer: "concatenate", Args: []string{"' '", "name"}},
+			err:     "transformer 'concatenate' expects at least 3 argument(s), got 2",
+		},
+		{
+			name:    "literal instead of field",
+			program: &parser.Program{Variables: []string{"name"}, Transformer: "uppercase", Args: []string{"'john'"}},
+			err:     "argument 1 of 'uppercase' (value) must be a field, got literal 'john'",
+		},
+		{
+			name:    "field instead of literal",
+			program: &parser.Program{Variables: []string{"a", "b"}, Transformer: "split", Args: []string{"place", "separator"}},
+			err:     "argument 2 of 'split' (separator) must be a literal, got field 'separator'",
+		},
+		{
+			name:    "literals of the parameter types",
+			program: &parser.Program{Variables: []string{"price"}, Transformer: "clamp", Args: []string{"price", "'0'", "' 99.5 '"}},
+		},
+		{
+			name:    "literal that is not an integer",
+			program: &parser.Program{Variables: []string{"y"}, Transformer: "repeat", Args: []string{"name", "'abc'"}},
+			err:     "argument 2 of 'repeat' (count) must be an integer, got 'abc'",
+		},
+		{
+			name:    "decimal literal for an integer",
+			program: &parser.Program{Variables: []string{"price"}, Transformer: "round", Args: []string{"price", "'1.5'"}},
+			err:     "argument 2 of 'round' (digits) must be an integer, got '1.5'",
+		},
+		{
+			name:    "literal that is not a number",
+			program: &parser.Program{Variables: []string{"price"}, Transformer: "clamp", Args: []string{"price", "'zero'", "'100'"}},
+			err:     "argument 2 of 'clamp' (lower) must be a number, got 'zero'",
+		},
+		{
+			name:    "wrong number of outputs",
+			program: &parser.Program{Variables: []string{"bmi"}, Transformer: "bmi", Args: []string{"weight", "height"}},
+			err:     "transformer 'bmi' returns 2 output(s) (bmi, isHealthy), got 1 variable(s)",
+		},
+	}
+
+	e := engine.NewEngine()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := e.Vet([]*parser.Program{tt.program})
+			if tt.err == "" {
+				if err != nil {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatalf("Expected error %q, got nil", tt.err)
+			}
+			if !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("Expected error to contain %q, got %q", tt.err, err.Error())
+			}
+		})
+	}
+}
+
+func TestVetReportsEveryStatement(t *testing.T) {
+	programs := []*parser.Program{
+		{Variables: []string{"a"}, Transformer: "uppercase", Args: []string{"a", "b"}},
+		{Variables: []string{"b"}, Transformer: "uppercase", Args: []string{"b"}},
+		{Variables: []string{"c"}, Transformer: "nonExistent", Args: []string{"c"}},
+	}
+
+	err := engine.NewEngine().Vet(programs)
+	if err == nil {
+		t.Fatalf("Expected error, got nil")
+	}
+
+	for _, expected := range []string{"statement 1:", "statement 3:"} {
+		if !strings.Contains(err.Error(), expected) {
+			t.Errorf("Expected error to contain %q, got %q", expected, err.Error())
+		}
+	}
+	if strings.Contains(err.Error(), "statement 2:") {
+		t.Errorf("Expected statement 2 to be valid, got %q", err.Error())
+	}
+}
+
+func TestVetBoolLiterals(t *testing.T) {
+	e := engine.NewEngine()
+	e.Register(engine.Signature{
+		Name:    "shout",
+		Params:  []engine.Param{{Name: "value", Kind: engine.FieldParam, Type: "string"}, {Name: "loud", Kind: engine.LiteralParam, Type: "bool"}},
+		Outputs: []string{"result"},
+	}, func(config transformers.Config) engine.Transformer {
+		return engine.Adapt(&transformers.Uppercase{Config: config})
+	})
+
+	tests := []struct {
+		literal string
+		err     string
+	}{
+		{literal: "'true'"},
+		{literal: "'false'"},
+		{literal: "'yes'", err: "argument 2 of 'shout' (loud) must be true or false, got 'yes'"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.literal, func(t *testing.T) {
+			err := e.Vet([]*parser.Program{{Variables: []string{"name"}, Transformer: "shout", Args: []string{"name", tt.literal}}})
+			if tt.err == "" {
+				if err != nil {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("Expected error to contain %q, got %v", tt.err, err)
+			}
+		})
+	}
+}
+
+func TestExecuteAllRejectsScriptBeforeProcessing(t *testing.T) {
+	jsonData := []byte(`{"name": "john"}`)
+
+	// The first statement is valid, but the second one must stop the script before it runs
+	programs := []*parser.Program{
+		{Variables: []string{"name"}, Transformer: "uppercase", Args: []string{"name"}},
+		{Variables: []string{"bmi"}, Transformer: "bmi", Args: []string{"weight"}},
+	}
+
+	_, err := engine.NewEngine().ExecuteAll(programs, jsonData)
+	if err == nil {
+		t.Fatalf("Expected error, got nil")
+	}
+	if !strings.Contains(err.Error(), "statement 2:") {
+		t.Errorf("Expected error for statement 2, got %q", err.Error())
+	}
+}
+
+func TestRegister(t *testing.T) {
+	e := engine.NewEngine()
+	e.Register(engine.Signature{
+		Name:    "shout",
+		Params:  []engine.Param{{Name: "value", Kind: engine.FieldParam, Type: "string"}},
+		Outputs: []string{"result"},
+	}, func(config transformers.Config) engine.Transformer {
+		return engine.Adapt(&transformers.Uppercase{Config: config})
+	})
+
+	program := &parser.Program{Variables: []string{"name"}, Transformer: "shout", Args: []string{"name"}}
+	modifiedJSON, err := e.Execute(program, []byte(`{"name":"john"}`))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := `{"name":"JOHN"}`
+	if string(modifiedJSON) != expected {
+		t.Errorf("Expected %s, got %s", expected, string(modifiedJSON))
+	}
+
+	signatures := e.Signatures()
+	for i := 1; i < len(signatures); i++ {
+		if signatures[i-1].Name > signatures[i].Name {
+			t.Errorf("Expected signatures sorted by name, got %s before %s", signatures[i-1].Name, signatures[i].Name)
+		}
+	}
+}
+
+func TestSignatureString(t *testing.T) {
+	signatures := map[string]string{}
+	for _, signature := range engine.NewEngine().Signatures() {
+		signatures[signature.Name] = signature.String()
+	}
+
+	expected := map[string]string{
+		"bmi":         "bmi, isHealthy = bmi(weight, height, ['weightUnit'], ['heightUnit'], ['digits'])",
+		"concatenate": "result = concatenate('separator', first, rest...)",
+		"split":       "part... = split(value, 'separator')",
+	}
+	for name, want := range expected {
+		if signatures[name] != want {
+			t.Errorf("Expected %q, got %q", want, signatures[name])
+		}
+	}
+}
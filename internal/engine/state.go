@@ -0,0 +1,92 @@
+package engine
+
+import (
+	"github.com/codeis4fun/data-treatment-interpreter/internal/document"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+// WithDocumentModel makes plans parse each document once into an in-memory tree that statements
+// read and update in place, serialising it once at the end, instead of copying the whole document
+// on every write. This pays off on large documents and wide arrays. Under this model transformers
+// get no Config.Json and must read their arguments through Config.Value or Config.Field
+func WithDocumentModel() Option {
+	return func(e *Engine) {
+		e.documentModel = true
+	}
+}
+
+// state is the document a plan reads and writes while it runs
+type state interface {
+	get(path string) gjson.Result
+	set(path string, value any) error
+	setRaw(path string, raw []byte) error
+	delete(path string) error
+	bytes() []byte
+	json() []byte // The document passed to transformers as Config.Json, nil when it is not kept as bytes
+	mark() any    // A point that rollback can return to
+	rollback(mark any)
+	commit() // Forgets the marks taken so far
+}
+
+// newState wraps a document according to the document model of the engine
+func (e *Engine) newState(jsonData []byte) state {
+	if e.documentModel {
+		return &treeState{document: document.Parse(jsonData)}
+	}
+	return &bytesState{data: jsonData}
+}
+
+// bytesState keeps the document as JSON bytes, copied by every write
+type bytesState struct {
+	data []byte
+}
+
+func (s *bytesState) get(path string) gjson.Result {
+	return gjson.GetBytes(s.data, path)
+}
+
+func (s *bytesState) set(path string, value any) error {
+	data, err := sjson.SetBytes(s.data, path, value)
+	if err == nil {
+		s.data = data
+	}
+	return err
+}
+
+func (s *bytesState) setRaw(path string, raw []byte) error {
+	data, err := sjson.SetRawBytes(s.data, path, raw)
+	if err == nil {
+		s.data = data
+	}
+	return err
+}
+
+func (s *bytesState) delete(path string) error {
+	data, err := sjson.DeleteBytes(s.data, path)
+	if err == nil {
+		s.data = data
+	}
+	return err
+}
+
+func (s *bytesState) bytes() []byte     { return s.data }
+func (s *bytesState) json() []byte      { return s.data }
+func (s *bytesState) mark() any         { return s.data }
+func (s *bytesState) rollback(mark any) { s.data = mark.([]byte) }
+func (s *bytesState) commit()           {}
+
+// treeState keeps the document as a document.Document updated in place
+type treeState struct {
+	document *document.Document
+}
+
+func (s *treeState) get(path string) gjson.Result         { return s.document.Get(path) }
+func (s *treeState) set(path string, value any) error     { return s.document.Set(path, value) }
+func (s *treeState) setRaw(path string, raw []byte) error { return s.document.SetRaw(path, raw) }
+func (s *treeState) delete(path string) error             { return s.document.Delete(path) }
+func (s *treeState) bytes() []byte                        { return s.document.Bytes() }
+func (s *treeState) json() []byte                         { return nil }
+func (s *treeState) mark() any                            { return s.document.Mark() }
+func (s *treeState) rollback(mark any)                    { s.document.Rollback(mark.(int)) }
+func (s *treeState) commit()                              { s.document.Commit() }
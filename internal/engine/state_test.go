@@ -0,0 +1,32 @@
+package engine_test
+
+import (
+	"testing"
+
+	"github.com/codeis4fun/data-treatment-interpreter/internal/engine"
+)
+
+func TestDocumentModelMatchesBytes(t *testing.T) {
+	jsonData := []byte(`{"name":"john","surname":"doe","nickname":null,"friends":[{"name":"alice"},{"name":"bob","nick":"bobby"}],"place":"Lisbon/Portugal"}`)
+	script := `SET _fullName = concatenate(' ', name, surname)
+SET fullName = uppercase(_fullName)
+SET city, country = split(place, '/')
+SET friends.#.name = uppercase(friends.#.name)
+SET friends.#.nick = uppercase(friends.#.nick?)
+SET friends.#.display = coalesce(friends.#.nick, friends.#.name)
+SET missing = uppercase(middleName) ON ERROR DEFAULT 'n/a'
+SET friends.#.age = uppercase(friends.#.age) ON ERROR DEFAULT 0
+SET broken = split(place, '-')`
+
+	for _, policy := range []engine.ErrorPolicy{engine.FailFast, engine.ContinueOnError} {
+		expected, expectedErr := engine.NewEngine(engine.WithErrorPolicy(policy)).ExecuteAll(parse(t, script), jsonData)
+		actual, err := engine.NewEngine(engine.WithErrorPolicy(policy), engine.WithDocumentModel()).ExecuteAll(parse(t, script), jsonData)
+
+		if (err == nil) != (expectedErr == nil) || (err != nil && err.Error() != expectedErr.Error()) {
+			t.Errorf("Expected error %v, got %v", expectedErr, err)
+		}
+		if string(actual) != string(expected) {
+			t.Errorf("Expected %s, got %s", expected, actual)
+		}
+	}
+}
@@ -0,0 +1,152 @@
+package engine
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/codeis4fun/data-treatment-interpreter/internal/parser"
+	"github.com/codeis4fun/data-treatment-interpreter/internal/transformers"
+)
+
+// Tracer receives an Event every time the engine applies a transformer
+type Tracer interface {
+	Trace(event Event)
+}
+
+// redacted replaces secret values, and secret literals in the script, in traces
+const redacted = "<redacted>"
+
+// Argument is an argument of a transformer together with the value it resolved to
+type Argument struct {
+	Name     string          `json:"name"`               // The field path or literal as written in the script
+	Value    json.RawMessage `json:"value,omitempty"`    // Empty when the field does not exist in the JSON or is redacted
+	Redacted bool            `json:"redacted,omitempty"` // The value of a $parameter or a secret parameter, such as a key
+}
+
+// Output is a path written by a transformer together with the value written to it
+type Output struct {
+	Path  string `json:"path"`
+	Value any    `json:"value"`
+}
+
+// Event describes a single application of a transformer
+type Event struct {
+	Statement int             `json:"statement"` // 1-based index of the statement in the script
+	Iteration int             `json:"iteration"` // Array index for '#' statements, -1 otherwise
+	Program   *parser.Program `json:"program"`
+	Args      []Argument      `json:"args"`
+	Outputs   []Output        `json:"outputs,omitempty"`
+	Elapsed   time.Duration   `json:"elapsed"` // In nanoseconds when encoded as JSON
+	Error     string          `json:"error,omitempty"`
+	Skipped   bool            `json:"skipped,omitempty"` // An optional argument was missing, so the transformer did not run
+}
+
+// newEvent builds the event for an application of a transformer from the configuration it was given. The values
+// of $parameters and secret parameters are redacted, and so are secret literals wherever the script shows them
+func newEvent(s *statement, step step, config transformers.Config, variables []string, results transformers.Results, err error, elapsed time.Duration) Event {
+	event := Event{
+		Statement: step.statement,
+		Iteration: step.iteration,
+		Program:   s.program,
+		Elapsed:   elapsed,
+	}
+
+	for i, arg := range config.Args {
+		argument := Argument{Name: arg}
+		switch value := config.Value(i); {
+		case s.args[i].secret:
+			argument.Redacted = true
+			if isLiteral(arg) {
+				argument.Name = redacted
+				event.Program = redactArg(event.Program, i)
+			}
+		case value.Exists():
+			argument.Value = json.RawMessage(value.Raw)
+		}
+		event.Args = append(event.Args, argument)
+	}
+
+	if err != nil {
+		event.Error = err.Error()
+		return event
+	}
+	for i, result := range results {
+		event.Outputs = append(event.Outputs, Output{Path: variables[i], Value: result})
+	}
+	return event
+}
+
+// redactArg returns a copy of the program whose argument at index i is redacted
+func redactArg(program *parser.Program, i int) *parser.Program {
+	copied := *program
+	copied.Args = slices.Clone(program.Args)
+	copied.Args[i] = redacted
+	return &copied
+}
+
+// String renders the event as a readable trace entry
+func (e Event) String() string {
+	var b strings.Builder
+
+	position := fmt.Sprintf("#%d", e.Statement)
+	if e.Iteration >= 0 {
+		position += fmt.Sprintf("[%d]", e.Iteration)
+	}
+	fmt.Fprintf(&b, "%s %s = %s(%s) (%s)\n", position, strings.Join(e.Program.Variables, ", "), e.Program.Transformer, strings.Join(e.Program.Args, ", "), e.Elapsed)
+
+	for _, arg := range e.Args {
+		if arg.Redacted {
+			fmt.Fprintf(&b, "    %s = %s\n", arg.Name, redacted)
+		} else if arg.Value == nil {
+			fmt.Fprintf(&b, "    %s = <missing>\n", arg.Name)
+		} else {
+			fmt.Fprintf(&b, "    %s = %s\n", arg.Name, arg.Value)
+		}
+	}
+	for _, output := range e.Outputs {
+		value, _ := json.Marshal(output.Value)
+		fmt.Fprintf(&b, "    -> %s = %s\n", output.Path, value)
+	}
+	if e.Skipped {
+		b.WriteString("    skipped: optional argument missing\n")
+	}
+	if e.Error != "" {
+		fmt.Fprintf(&b, "    error: %s\n", e.Error)
+	}
+	return b.String()
+}
+
+// Recorder is a Tracer that keeps every event in memory
+type Recorder struct {
+	mu     sync.Mutex
+	events []Event
+}
+
+// Trace records the event
+func (r *Recorder) Trace(event Event) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, event)
+}
+
+// Events returns the recorded events in the order they happened
+func (r *Recorder) Events() []Event {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Event(nil), r.events...)
+}
+
+// WriterTracer is a Tracer that writes each event as a readable trace entry as soon as it happens
+type WriterTracer struct {
+	W io.Writer
+}
+
+// Trace writes the event
+func (w WriterTracer) Trace(event Event) {
+	io.WriteString(w.W, event.String())
+}
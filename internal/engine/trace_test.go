@@ -0,0 +1,116 @@
+package engine_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/codeis4fun/data-treatment-interpreter/internal/engine"
+	"github.com/codeis4fun/data-treatment-interpreter/internal/parser"
+)
+
+func TestTracer(t *testing.T) {
+	jsonData := []byte(`{"name":"john","surname":"doe","colors":["red","blue"]}`)
+
+	programs, err := parser.Parse(`SET fullName = concatenate(' ', name, surname)
+SET colors.# = uppercase(colors.#)`)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	recorder := &engine.Recorder{}
+	e := engine.NewEngine(engine.WithTracer(recorder))
+	if _, err := e.ExecuteAll(programs, jsonData); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	events := recorder.Events()
+	if len(events) != 3 {
+		t.Fatalf("Expected 3 events, got %d", len(events))
+	}
+
+	expected := []struct {
+		statement, iteration int
+		args                 string
+		outputs              string
+	}{
+		{statement: 1, iteration: -1, args: `[{"name":"' '","value":" "},{"name":"name","value":"john"},{"name":"surname","value":"doe"}]`, outputs: `[{"path":"fullName","value":"john doe"}]`},
+		{statement: 2, iteration: 0, args: `[{"name":"colors.0","value":"red"}]`, outputs: `[{"path":"colors.0","value":"RED"}]`},
+		{statement: 2, iteration: 1, args: `[{"name":"colors.1","value":"blue"}]`, outputs: `[{"path":"colors.1","value":"BLUE"}]`},
+	}
+
+	for i, event := range events {
+		args, _ := json.Marshal(event.Args)
+		outputs, _ := json.Marshal(event.Outputs)
+		if event.Statement != expected[i].statement || event.Iteration != expected[i].iteration {
+			t.Errorf("Event %d: expected statement %d iteration %d, got %d %d", i, expected[i].statement, expected[i].iteration, event.Statement, event.Iteration)
+		}
+		if string(args) != expected[i].args {
+			t.Errorf("Event %d: expected args %s, got %s", i, expected[i].args, args)
+		}
+		if string(outputs) != expected[i].outputs {
+			t.Errorf("Event %d: expected outputs %s, got %s", i, expected[i].outputs, outputs)
+		}
+	}
+}
+
+func TestTracerWithError(t *testing.T) {
+	program := &parser.Program{Variables: []string{"name"}, Transformer: "uppercase", Args: []string{"middleName"}}
+
+	var b bytes.Buffer
+	e := engine.NewEngine(engine.WithTracer(engine.WriterTracer{W: &b}))
+	if _, err := e.Execute(program, []byte(`{"name":"john"}`)); err == nil {
+		t.Fatalf("Expected error, got nil")
+	}
+
+	for _, want := range []string{
+		"#1 name = uppercase(middleName)",
+		"    middleName = <missing>\n",
+		"    error: argument 'middleName' not found in JSON\n",
+	} {
+		if !strings.Contains(b.String(), want) {
+			t.Errorf("Expected trace to contain %q, got %q", want, b.String())
+		}
+	}
+}
+
+func TestTracerRedactsSecrets(t *testing.T) {
+	programs, err := parser.Parse(`SET emailId = hmac(email, $key)
+SET userId = pseudonymize(email, 'TOPSECRET', 'usr_')`)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	jsonData := []byte(`{"email":"john.doe@example.com"}`)
+	params := engine.WithParams(map[string]any{"key": "TOPSECRET"})
+	recorder := &engine.Recorder{}
+	if _, err := engine.NewEngine(engine.WithTracer(recorder)).ExecuteAll(programs, jsonData, params); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	var b bytes.Buffer
+	if _, err := engine.NewEngine(engine.WithTracer(engine.WriterTracer{W: &b})).ExecuteAll(programs, jsonData, params); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := []string{
+		`[{"name":"email","value":"john.doe@example.com"},{"name":"$key","redacted":true}]`,
+		`[{"name":"email","value":"john.doe@example.com"},{"name":"\u003credacted\u003e","redacted":true},{"name":"'usr_'","value":"usr_"}]`,
+	}
+	events := recorder.Events()
+	for i, event := range events {
+		args, _ := json.Marshal(event.Args)
+		if string(args) != expected[i] {
+			t.Errorf("Event %d: expected args %s, got %s", i, expected[i], args)
+		}
+		if encoded, _ := json.Marshal(event); bytes.Contains(encoded, []byte("TOPSECRET")) {
+			t.Errorf("Event %d: expected the key to be redacted, got %s", i, encoded)
+		}
+	}
+	if strings.Contains(b.String(), "TOPSECRET") {
+		t.Errorf("Expected the key to be redacted from the trace, got %q", b.String())
+	}
+	if !strings.Contains(b.String(), "userId = pseudonymize(email, <redacted>, 'usr_')") {
+		t.Errorf("Expected the trace to show the redacted statement, got %q", b.String())
+	}
+}
@@ -14,6 +14,8 @@ type TokenType string
 const (
 	IDENTIFIER TokenType = "IDENTIFIER"
 	STRING     TokenType = "STRING"
+	NUMBER     TokenType = "NUMBER"
+	PARAMETER  TokenType = "PARAMETER" // $name, a value supplied when the script runs
 	OPERATOR   TokenType = "OPERATOR"
 	LPAREN     TokenType = "LPAREN"
 	RPAREN     TokenType = "RPAREN"
@@ -45,8 +47,14 @@ type Lexer struct {
 
 type stateFn func(*Lexer) stateFn
 
+// keywords start statements. The words of ON ERROR clauses are identifiers, recognised by the parser after a
+// transformer call, so fields may still be named ON, ERROR, SKIP, DEFAULT or FAIL
 var keywords = map[string]TokenType{
-	"SET": KEYWORD,
+	"SET":     KEYWORD,
+	"LET":     KEYWORD,
+	"DEF":     KEYWORD,
+	"INCLUDE": KEYWORD,
+	"REDACT":  KEYWORD,
 }
 
 // Symbols table to handle operators and punctuation
@@ -124,6 +132,15 @@ func (l *Lexer) backup() {
 	l.pos -= l.width
 }
 
+// peek returns the next rune in the input without consuming it
+func (l *Lexer) peek() rune {
+	if l.pos >= len(l.input) {
+		return -1
+	}
+	r, _ := utf8.DecodeRuneInString(l.input[l.pos:])
+	return r
+}
+
 // lexString scans string literals (enclosed in single quotes)
 func lexString(l *Lexer) stateFn {
 	for {
@@ -157,6 +174,11 @@ func lexText(l *Lexer) stateFn {
 		case unicode.IsLetter(r) || r == '_' || r == '#': // Allow '#' and '_' as part of identifiers
 			l.backup()
 			return lexIdentifierOrKeyword
+		case r == '$':
+			return lexParameter
+		case unicode.IsDigit(r) || (r == '-' && unicode.IsDigit(l.peek())): // Numbers may be negative
+			l.backup()
+			return lexNumber
 		case symbols[r] != "": // symbols[r] returns the token type for the rune
 			l.emit(symbols[r])
 		case r == -1:
@@ -196,6 +218,11 @@ func lexIdentifierOrKeyword(l *Lexer) stateFn {
 	}
 	l.backup() // We've scanned one character too far; back up
 
+	// A trailing '?' marks an optional field (e.g. middleName?)
+	if l.peek() == '?' {
+		l.next()
+	}
+
 	// Extract the scanned word
 	word := l.input[l.start:l.pos]
 
@@ -209,3 +236,34 @@ func lexIdentifierOrKeyword(l *Lexer) stateFn {
 	l.emit(IDENTIFIER)
 	return lexText
 }
+
+// lexParameter scans parameter references: '$' followed by letters, digits and underscores
+func lexParameter(l *Lexer) stateFn {
+	for r := l.peek(); unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_'; r = l.peek() {
+		l.next()
+	}
+	if l.pos-l.start == 1 {
+		l.emitError("expected parameter name after '$'")
+		return nil
+	}
+	l.emit(PARAMETER)
+	return lexText
+}
+
+// lexNumber scans number literals: an optional minus sign, digits and an optional fraction
+func lexNumber(l *Lexer) stateFn {
+	if l.peek() == '-' {
+		l.next()
+	}
+	for unicode.IsDigit(l.peek()) {
+		l.next()
+	}
+	if l.peek() == '.' {
+		l.next()
+		for unicode.IsDigit(l.peek()) {
+			l.next()
+		}
+	}
+	l.emit(NUMBER)
+	return lexText
+}
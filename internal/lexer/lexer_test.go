@@ -200,3 +200,100 @@ func TestLexerWithArgumentInTransformerButUnfinishedString(t *testing.T) {
 		}
 	}
 }
+
+func TestLexerWithOnErrorClause(t *testing.T) {
+	input := `SET age = t(a) ON ERROR DEFAULT -1.5`
+	r := strings.NewReader(input)
+	l := lexer.NewLexer(r)
+
+	expectedTokens := []lexer.Token{
+		{Type: lexer.KEYWORD, Literal: "SET", Line: 1, Pos: 0},
+		{Type: lexer.IDENTIFIER, Literal: "age", Line: 1, Pos: 4},
+		{Type: lexer.OPERATOR, Literal: "=", Line: 1, Pos: 8},
+		{Type: lexer.IDENTIFIER, Literal: "t", Line: 1, Pos: 10},
+		{Type: lexer.LPAREN, Literal: "(", Line: 1, Pos: 11},
+		{Type: lexer.IDENTIFIER, Literal: "a", Line: 1, Pos: 12},
+		{Type: lexer.RPAREN, Literal: ")", Line: 1, Pos: 13},
+		{Type: lexer.IDENTIFIER, Literal: "ON", Line: 1, Pos: 15},
+		{Type: lexer.IDENTIFIER, Literal: "ERROR", Line: 1, Pos: 18},
+		{Type: lexer.IDENTIFIER, Literal: "DEFAULT", Line: 1, Pos: 24},
+		{Type: lexer.NUMBER, Literal: "-1.5", Line: 1, Pos: 32},
+		{Type: lexer.EOL, Literal: "\n", Line: 1, Pos: 36},
+		{Type: lexer.EOF, Literal: "", Line: 2, Pos: 37},
+	}
+
+	for _, expectedToken := range expectedTokens {
+		actualToken := l.NextToken()
+		if actualToken != expectedToken {
+			t.Errorf("Expected token %v, got %v", expectedToken, actualToken)
+		}
+	}
+}
+
+func TestLexerWithOptionalField(t *testing.T) {
+	input := `SET name = uppercase(middleName?)`
+	r := strings.NewReader(input)
+	l := lexer.NewLexer(r)
+
+	expectedTokens := []lexer.Token{
+		{Type: lexer.KEYWORD, Literal: "SET", Line: 1, Pos: 0},
+		{Type: lexer.IDENTIFIER, Literal: "name", Line: 1, Pos: 4},
+		{Type: lexer.OPERATOR, Literal: "=", Line: 1, Pos: 9},
+		{Type: lexer.IDENTIFIER, Literal: "uppercase", Line: 1, Pos: 11},
+		{Type: lexer.LPAREN, Literal: "(", Line: 1, Pos: 20},
+		{Type: lexer.IDENTIFIER, Literal: "middleName?", Line: 1, Pos: 21},
+		{Type: lexer.RPAREN, Literal: ")", Line: 1, Pos: 32},
+		{Type: lexer.EOL, Literal: "\n", Line: 1, Pos: 33},
+		{Type: lexer.EOF, Literal: "", Line: 2, Pos: 34},
+	}
+
+	for _, expectedToken := range expectedTokens {
+		actualToken := l.NextToken()
+		if actualToken != expectedToken {
+			t.Errorf("Expected token %v, got %v", expectedToken, actualToken)
+		}
+	}
+}
+
+func TestLexerWithParameter(t *testing.T) {
+	input := `SET id = t($tenant_id)`
+	r := strings.NewReader(input)
+	l := lexer.NewLexer(r)
+
+	expectedTokens := []lexer.Token{
+		{Type: lexer.KEYWORD, Literal: "SET", Line: 1, Pos: 0},
+		{Type: lexer.IDENTIFIER, Literal: "id", Line: 1, Pos: 4},
+		{Type: lexer.OPERATOR, Literal: "=", Line: 1, Pos: 7},
+		{Type: lexer.IDENTIFIER, Literal: "t", Line: 1, Pos: 9},
+		{Type: lexer.LPAREN, Literal: "(", Line: 1, Pos: 10},
+		{Type: lexer.PARAMETER, Literal: "$tenant_id", Line: 1, Pos: 11},
+		{Type: lexer.RPAREN, Literal: ")", Line: 1, Pos: 21},
+		{Type: lexer.EOL, Literal: "\n", Line: 1, Pos: 22},
+		{Type: lexer.EOF, Literal: "", Line: 2, Pos: 23},
+	}
+
+	for _, expectedToken := range expectedTokens {
+		actualToken := l.NextToken()
+		if actualToken != expectedToken {
+			t.Errorf("Expected token %v, got %v", expectedToken, actualToken)
+		}
+	}
+}
+
+func TestLexerWithEmptyParameter(t *testing.T) {
+	r := strings.NewReader(`SET id = t($)`)
+	l := lexer.NewLexer(r)
+
+	for {
+		token := l.NextToken()
+		if token.Type == lexer.ERROR {
+			if token.Literal != "expected parameter name after '$'" {
+				t.Errorf("Unexpected error message: %s", token.Literal)
+			}
+			return
+		}
+		if token.Type == lexer.EOF {
+			t.Fatalf("Expected an ERROR token")
+		}
+	}
+}
@@ -0,0 +1,279 @@
+package lineage
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/codeis4fun/data-treatment-interpreter/internal/engine"
+	"github.com/codeis4fun/data-treatment-interpreter/internal/parser"
+)
+
+// Derivation records that a statement wrote a field from the values of other fields
+type Derivation struct {
+	Field       string   `json:"field"`
+	Statement   int      `json:"statement"` // 1-based index of the statement in the script
+	Transformer string   `json:"transformer"`
+	Inputs      []string `json:"inputs"`        // Fields read by the statement; literals and parameters are not included
+	Let         bool     `json:"let,omitempty"` // The field is a LET variable, kept out of the document
+}
+
+// Graph is the lineage of the fields of a document: which fields each field was derived from.
+// Fields are matched by their exact path, so writing "address" does not derive "address.city"
+type Graph struct {
+	mu          sync.Mutex
+	derivations []Derivation
+	tempPrefix  string // Prefix of the temporary variables, empty when only LET variables are temporary
+}
+
+// NewGraph creates an empty graph, to be passed to engine.WithTracer. The prefix of temporary
+// variables must be the one given to the engine with engine.WithTempPrefix ("_" by default)
+func NewGraph(tempPrefix string) *Graph {
+	return &Graph{tempPrefix: tempPrefix}
+}
+
+// FromPrograms builds the lineage of a script without running it, with the prefix of its temporary
+// variables. Fields written by '#' statements keep the placeholder in their paths (e.g. friends.#.name)
+func FromPrograms(programs []*parser.Program, tempPrefix string) *Graph {
+	g := NewGraph(tempPrefix)
+	for i, program := range programs {
+		g.add(i+1, program.Transformer, program.Let, program.Args, program.Variables)
+	}
+	return g
+}
+
+// Trace records the fields read and written by a transformer application, so a Graph
+// can be passed to engine.WithTracer to capture the lineage of a single execution
+func (g *Graph) Trace(event engine.Event) {
+	if event.Error != "" {
+		return
+	}
+
+	args := make([]string, 0, len(event.Args))
+	for _, arg := range event.Args {
+		// Redacted arguments are secret literals or $parameters, never fields
+		if arg.Redacted {
+			continue
+		}
+		args = append(args, arg.Name)
+	}
+	outputs := make([]string, 0, len(event.Outputs))
+	for _, output := range event.Outputs {
+		outputs = append(outputs, output.Path)
+	}
+	g.add(event.Statement, event.Program.Transformer, event.Program.Let, args, outputs)
+}
+
+// add records one derivation per output, ignoring literal arguments, $parameters and the '?' marker of optional fields
+func (g *Graph) add(statement int, transformer string, let bool, args, outputs []string) {
+	inputs := []string{}
+	for _, arg := range args {
+		if strings.HasPrefix(arg, "'") || strings.HasPrefix(arg, "$") {
+			continue
+		}
+		if arg = strings.TrimSuffix(arg, "?"); !slices.Contains(inputs, arg) {
+			inputs = append(inputs, arg)
+		}
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	for _, output := range outputs {
+		g.derivations = append(g.derivations, Derivation{
+			Field:       output,
+			Statement:   statement,
+			Transformer: transformer,
+			Inputs:      inputs,
+			Let:         let,
+		})
+	}
+}
+
+// Derivations returns every recorded derivation in the order the statements ran
+func (g *Graph) Derivations() []Derivation {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return append([]Derivation(nil), g.derivations...)
+}
+
+// Node is a field together with the fields it was derived from
+type Node struct {
+	Field       string  `json:"field"`
+	Statement   int     `json:"statement,omitempty"` // 0 for input fields
+	Transformer string  `json:"transformer,omitempty"`
+	Inputs      []*Node `json:"inputs,omitempty"`
+}
+
+// Lineage returns the derivation tree of a field as it stands at the end of the script
+func (g *Graph) Lineage(field string) *Node {
+	derivations := g.Derivations()
+	return lineage(derivations, field, math.MaxInt)
+}
+
+// lineage resolves a field to the last statement before the given one that wrote it. Each input
+// is resolved against the statements that ran before the one that read it, so overwritten fields
+// (SET name = uppercase(name)) are followed back to their previous value
+func lineage(derivations []Derivation, field string, before int) *Node {
+	node := &Node{Field: field}
+
+	var last *Derivation
+	for i := range derivations {
+		derivation := &derivations[i]
+		if derivation.Field == field && derivation.Statement < before && (last == nil || derivation.Statement >= last.Statement) {
+			last = derivation
+		}
+	}
+	if last == nil {
+		return node
+	}
+
+	node.Statement = last.Statement
+	node.Transformer = last.Transformer
+	for _, input := range last.Inputs {
+		node.Inputs = append(node.Inputs, lineage(derivations, input, last.Statement))
+	}
+	return node
+}
+
+// Sources returns the input fields a field was ultimately derived from
+func (g *Graph) Sources(field string) []string {
+	var sources []string
+	var walk func(node *Node)
+	walk = func(node *Node) {
+		if node.Statement == 0 {
+			if !slices.Contains(sources, node.Field) {
+				sources = append(sources, node.Field)
+			}
+			return
+		}
+		for _, input := range node.Inputs {
+			walk(input)
+		}
+	}
+
+	root := g.Lineage(field)
+	if root.Statement == 0 {
+		return nil
+	}
+	walk(root)
+	sort.Strings(sources)
+	return sources
+}
+
+// String renders the derivation tree, e.g. "fullName <- _tempName <- firstName, lastName"
+func (n *Node) String() string {
+	if len(n.Inputs) == 0 {
+		return n.Field
+	}
+
+	inputs := make([]string, 0, len(n.Inputs))
+	for _, input := range n.Inputs {
+		rendered := input.String()
+		if len(n.Inputs) > 1 && len(input.Inputs) > 0 {
+			rendered = "(" + rendered + ")"
+		}
+		inputs = append(inputs, rendered)
+	}
+	return n.Field + " <- " + strings.Join(inputs, ", ")
+}
+
+// Field describes a node of the exported graph
+type Field struct {
+	Name      string `json:"name"`
+	Input     bool   `json:"input"`               // Read by the script without being written before
+	Temporary bool   `json:"temporary,omitempty"` // Deleted from the output by ExecuteAll, or a LET variable that never reaches it
+}
+
+// Fields returns every field that appears in the graph, sorted by name
+func (g *Graph) Fields() []Field {
+	derivations := g.Derivations()
+
+	firstWrite := map[string]int{}
+	firstRead := map[string]int{}
+	lets := map[string]bool{}
+	for _, derivation := range derivations {
+		if derivation.Let {
+			lets[derivation.Field] = true
+		}
+		if _, ok := firstWrite[derivation.Field]; !ok {
+			firstWrite[derivation.Field] = derivation.Statement
+		}
+		for _, input := range derivation.Inputs {
+			if _, ok := firstRead[input]; !ok {
+				firstRead[input] = derivation.Statement
+			}
+		}
+	}
+
+	var names []string
+	for name := range firstWrite {
+		names = append(names, name)
+	}
+	for name := range firstRead {
+		if _, ok := firstWrite[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	fields := make([]Field, 0, len(names))
+	for _, name := range names {
+		written, isWritten := firstWrite[name]
+		read, isRead := firstRead[name]
+		fields = append(fields, Field{
+			Name:      name,
+			Input:     isRead && (!isWritten || read <= written),
+			Temporary: (g.tempPrefix != "" && strings.HasPrefix(name, g.tempPrefix)) || lets[name],
+		})
+	}
+	return fields
+}
+
+// MarshalJSON exports the fields and derivations of the graph
+func (g *Graph) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Fields      []Field      `json:"fields"`
+		Derivations []Derivation `json:"derivations"`
+	}{
+		Fields:      g.Fields(),
+		Derivations: g.Derivations(),
+	})
+}
+
+// DOT writes the graph in Graphviz DOT format. Input fields are drawn as boxes and
+// temporary variables with dashed outlines
+func (g *Graph) DOT(w io.Writer) error {
+	var b strings.Builder
+	b.WriteString("digraph lineage {\n")
+	b.WriteString("  rankdir=LR;\n")
+
+	for _, field := range g.Fields() {
+		var attributes []string
+		if field.Input {
+			attributes = append(attributes, "shape=box")
+		}
+		if field.Temporary {
+			attributes = append(attributes, "style=dashed")
+		}
+		fmt.Fprintf(&b, "  %q", field.Name)
+		if len(attributes) > 0 {
+			fmt.Fprintf(&b, " [%s]", strings.Join(attributes, ", "))
+		}
+		b.WriteString(";\n")
+	}
+
+	for _, derivation := range g.Derivations() {
+		for _, input := range derivation.Inputs {
+			fmt.Fprintf(&b, "  %q -> %q [label=%q];\n", input, derivation.Field, fmt.Sprintf("%d: %s", derivation.Statement, derivation.Transformer))
+		}
+	}
+
+	b.WriteString("}\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
@@ -0,0 +1,195 @@
+package lineage_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/codeis4fun/data-treatment-interpreter/internal/engine"
+	"github.com/codeis4fun/data-treatment-interpreter/internal/lineage"
+	"github.com/codeis4fun/data-treatment-interpreter/internal/parser"
+)
+
+const script = `SET _tempName = concatenate(' ', firstName, lastName)
+SET fullName = uppercase(_tempName)
+SET fullName = uppercase(fullName)
+SET friends.#.name = uppercase(friends.#.name)`
+
+func parse(t *testing.T) []*parser.Program {
+	t.Helper()
+	programs, err := parser.Parse(script)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	return programs
+}
+
+func TestStaticLineage(t *testing.T) {
+	g := lineage.FromPrograms(parse(t), "_")
+
+	expected := "fullName <- fullName <- _tempName <- firstName, lastName"
+	if actual := g.Lineage("fullName").String(); actual != expected {
+		t.Errorf("Expected %q, got %q", expected, actual)
+	}
+
+	sources := g.Sources("fullName")
+	if !reflect.DeepEqual(sources, []string{"firstName", "lastName"}) {
+		t.Errorf("Expected sources [firstName lastName], got %v", sources)
+	}
+
+	if sources := g.Sources("firstName"); sources != nil {
+		t.Errorf("Expected no sources for an input field, got %v", sources)
+	}
+
+	expected = "friends.#.name <- friends.#.name"
+	if actual := g.Lineage("friends.#.name").String(); actual != expected {
+		t.Errorf("Expected %q, got %q", expected, actual)
+	}
+}
+
+func TestDynamicLineage(t *testing.T) {
+	g := lineage.NewGraph("_")
+	e := engine.NewEngine(engine.WithTracer(g))
+
+	_, err := e.ExecuteAll(parse(t), []byte(`{"firstName":"john","lastName":"doe","friends":[{"name":"Alice"},{"name":"Bob"}]}`))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// Temporary variables are deleted from the output but remain in the lineage
+	expected := "fullName <- fullName <- _tempName <- firstName, lastName"
+	if actual := g.Lineage("fullName").String(); actual != expected {
+		t.Errorf("Expected %q, got %q", expected, actual)
+	}
+
+	expected = "friends.1.name <- friends.1.name"
+	if actual := g.Lineage("friends.1.name").String(); actual != expected {
+		t.Errorf("Expected %q, got %q", expected, actual)
+	}
+}
+
+func TestExportJSON(t *testing.T) {
+	g := lineage.FromPrograms(parse(t)[:2], "_")
+
+	actual, err := json.Marshal(g)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := `{"fields":[` +
+		`{"name":"_tempName","input":false,"temporary":true},` +
+		`{"name":"firstName","input":true},` +
+		`{"name":"fullName","input":false},` +
+		`{"name":"lastName","input":true}],` +
+		`"derivations":[` +
+		`{"field":"_tempName","statement":1,"transformer":"concatenate","inputs":["firstName","lastName"]},` +
+		`{"field":"fullName","statement":2,"transformer":"uppercase","inputs":["_tempName"]}]}`
+	if string(actual) != expected {
+		t.Errorf("Expected %s, got %s", expected, actual)
+	}
+}
+
+func TestExportDOT(t *testing.T) {
+	g := lineage.FromPrograms(parse(t)[:2], "_")
+
+	var b bytes.Buffer
+	if err := g.DOT(&b); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	for _, want := range []string{
+		"digraph lineage {",
+		`"_tempName" [style=dashed];`,
+		`"firstName" [shape=box];`,
+		`"firstName" -> "_tempName" [label="1: concatenate"];`,
+		`"_tempName" -> "fullName" [label="2: uppercase"];`,
+	} {
+		if !strings.Contains(b.String(), want) {
+			t.Errorf("Expected DOT to contain %q, got %s", want, b.String())
+		}
+	}
+}
+
+func TestLineageOfOptionalFields(t *testing.T) {
+	programs, err := parser.Parse(`SET displayName = concatenate(' ', firstName, middleName?)`)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := "displayName <- firstName, middleName"
+	if actual := lineage.FromPrograms(programs, "_").Lineage("displayName").String(); actual != expected {
+		t.Errorf("Expected %q, got %q", expected, actual)
+	}
+}
+
+func TestLineageOfRedactedArguments(t *testing.T) {
+	programs, err := parser.Parse("SET nameHash = hmac(name, 's3cret')\nSET idHash = hmac(id, $key)")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	g := lineage.NewGraph("_")
+	e := engine.NewEngine(engine.WithTracer(g))
+	if _, err := e.ExecuteAll(programs, []byte(`{"name":"john","id":"42"}`), engine.WithParams(map[string]any{"key": "0ther"})); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	for field, expected := range map[string][]string{"nameHash": {"name"}, "idHash": {"id"}} {
+		if sources := g.Sources(field); !reflect.DeepEqual(sources, expected) {
+			t.Errorf("Expected sources %v of %s, got %v", expected, field, sources)
+		}
+	}
+	for _, field := range g.Fields() {
+		if strings.Contains(field.Name, "redacted") || strings.HasPrefix(field.Name, "$") {
+			t.Errorf("Expected no field for a redacted argument, got %+v", field)
+		}
+	}
+}
+
+func TestLineageOfLetVariables(t *testing.T) {
+	programs, err := parser.Parse("LET full = concatenate(' ', firstName, lastName)\nSET fullName = uppercase(full)")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	g := lineage.FromPrograms(programs, "_")
+
+	expected := "fullName <- full <- firstName, lastName"
+	if actual := g.Lineage("fullName").String(); actual != expected {
+		t.Errorf("Expected %q, got %q", expected, actual)
+	}
+
+	for _, field := range g.Fields() {
+		if field.Temporary != (field.Name == "full") {
+			t.Errorf("Expected only full to be temporary, got %+v", field)
+		}
+	}
+}
+
+func TestLineageWithTempPrefix(t *testing.T) {
+	programs, err := parser.Parse("SET tmp_name = concatenate(' ', firstName, _lastName)\nSET fullName = uppercase(tmp_name)")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	static := lineage.FromPrograms(programs, "tmp_")
+	dynamic := lineage.NewGraph("tmp_")
+	e := engine.NewEngine(engine.WithTracer(dynamic), engine.WithTempPrefix("tmp_"))
+	if _, err := e.ExecuteAll(programs, []byte(`{"firstName":"john","_lastName":"doe"}`)); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	for _, g := range []*lineage.Graph{static, dynamic} {
+		for _, field := range g.Fields() {
+			if field.Temporary != (field.Name == "tmp_name") {
+				t.Errorf("Expected only tmp_name to be temporary, got %+v", field)
+			}
+		}
+	}
+
+	for _, field := range lineage.FromPrograms(programs, "").Fields() {
+		if field.Temporary {
+			t.Errorf("Expected no temporary fields without a prefix, got %+v", field)
+		}
+	}
+}
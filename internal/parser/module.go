@@ -0,0 +1,183 @@
+package parser
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/codeis4fun/data-treatment-interpreter/internal/lexer"
+)
+
+// macro is a transformer call declared with DEF, expanded by the parser wherever it is called
+type macro struct {
+	name        string
+	params      []string
+	transformer string
+	args        []string
+}
+
+// Macros holds the DEF macros declared so far, by name, so they can be shared by several parses
+type Macros map[string]*macro
+
+// NewMacros creates an empty set of macros
+func NewMacros() Macros {
+	return Macros{}
+}
+
+// Clone returns a copy of the macros, e.g. to restore them when declarations are undone
+func (m Macros) Clone() Macros {
+	return maps.Clone(m)
+}
+
+// ParseWithMacros parses a script like Parse, with the macros declared by earlier scripts. The macros
+// declared by the script are added to them, so a REPL can call on one line a macro declared on another
+func ParseWithMacros(input string, macros Macros) ([]*Program, error) {
+	p := NewParser(lexer.NewLexer(strings.NewReader(input)), input)
+	p.macros = macros
+	return p.RunAll()
+}
+
+// ParseFile parses a script file, expanding its INCLUDE statements. Included paths are resolved
+// relative to the file that includes them, and DEF macros of included files can be called by the
+// statements that follow the INCLUDE
+func ParseFile(path string) ([]*Program, error) {
+	return parseFile(path, nil, map[string]*macro{})
+}
+
+// parseFile parses a file included by the given chain of files, sharing the macros defined so far
+func parseFile(path string, files []string, macros map[string]*macro) ([]*Program, error) {
+	absolute, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+	if slices.Contains(files, absolute) {
+		chain := append(slices.Clone(files), absolute)
+		for i := range chain {
+			chain[i] = filepath.Base(chain[i])
+		}
+		return nil, fmt.Errorf("include cycle: %s", strings.Join(chain, " -> "))
+	}
+
+	content, err := os.ReadFile(absolute)
+	if err != nil {
+		return nil, err
+	}
+	input := string(content)
+	p := NewParser(lexer.NewLexer(strings.NewReader(input)), input)
+	p.dir = filepath.Dir(absolute)
+	p.files = append(slices.Clone(files), absolute)
+	p.macros = macros
+
+	programs, err := p.RunAll()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return programs, nil
+}
+
+// parseInclude parses a statement like: INCLUDE 'common/address.dts' and returns the programs of the file
+func (p *Parser) parseInclude() ([]*Program, error) {
+	p.nextToken() // Consume 'INCLUDE'
+
+	token := p.nextToken()
+	if token.Type != lexer.STRING {
+		return nil, p.errorWithContext(token, "expected file name after INCLUDE")
+	}
+	path := strings.Trim(token.Literal, "'")
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(p.dir, path)
+	}
+	return parseFile(path, p.files, p.macros)
+}
+
+// parseDef parses a macro declaration like: DEF fullname(first, last) = concatenate(' ', first, last)
+func (p *Parser) parseDef() error {
+	name := p.nextToken()
+	if name.Type != lexer.IDENTIFIER {
+		return p.errorWithContext(name, "expected macro name")
+	}
+	if err := p.isTransformer(name); err != nil {
+		return p.errorWithContext(name, "expected macro name to have only letters and digits, starting with a letter")
+	}
+	if err := p.expectSymbol(lexer.LPAREN); err != nil {
+		return err
+	}
+
+	m := &macro{name: name.Literal}
+	for {
+		token := p.nextToken()
+		if token.Type == lexer.RPAREN && len(m.params) == 0 {
+			break
+		}
+		if token.Type != lexer.IDENTIFIER || strings.ContainsAny(token.Literal, ".#?") {
+			return p.errorWithContext(token, "expected macro parameter name")
+		}
+		if slices.Contains(m.params, token.Literal) {
+			return p.errorWithContext(token, fmt.Sprintf("duplicate macro parameter '%s'", token.Literal))
+		}
+		m.params = append(m.params, token.Literal)
+
+		token = p.nextToken()
+		if token.Type == lexer.RPAREN {
+			break
+		}
+		if token.Type != lexer.COMMA {
+			return p.errorWithContext(token, "unexpected token in macro parameters")
+		}
+	}
+
+	token := p.nextToken()
+	if token.Type != lexer.OPERATOR || token.Literal != "=" {
+		return p.errorWithContext(token, fmt.Sprintf("expected operator '%s'", "="))
+	}
+
+	// Macros called in the body are expanded right away, so a macro can only call the ones defined before it
+	transformer, args, err := p.parseTransformer()
+	if err != nil {
+		return err
+	}
+	m.transformer, m.args = transformer, args
+	p.macros[m.name] = m
+	return nil
+}
+
+// expand replaces a call of the macro by the transformer call of its body. Parameters are replaced by the
+// arguments of the call, including at the start of paths: with first bound to address, first.city
+// becomes address.city
+func (m *macro) expand(args []string) (string, []string, error) {
+	if len(args) != len(m.params) {
+		return "", nil, fmt.Errorf("macro '%s' expects %d argument(s), got %d", m.name, len(m.params), len(args))
+	}
+
+	expanded := make([]string, 0, len(m.args))
+	for _, arg := range m.args {
+		if strings.HasPrefix(arg, "'") || strings.HasPrefix(arg, "$") {
+			expanded = append(expanded, arg)
+			continue
+		}
+
+		field, optional := strings.CutSuffix(arg, "?")
+		name, rest, isPath := strings.Cut(field, ".")
+		i := slices.Index(m.params, name)
+		if i < 0 {
+			expanded = append(expanded, arg)
+			continue
+		}
+
+		value := args[i]
+		if isPath {
+			if strings.HasPrefix(value, "'") || strings.HasPrefix(value, "$") {
+				return "", nil, fmt.Errorf("macro '%s' reads '%s', so argument %d must be a field, got %s", m.name, arg, i+1, value)
+			}
+			value, optional = strings.TrimSuffix(value, "?")+"."+rest, optional || strings.HasSuffix(value, "?")
+		}
+		if optional && !strings.HasSuffix(value, "?") && !strings.HasPrefix(value, "'") && !strings.HasPrefix(value, "$") {
+			value += "?"
+		}
+		expanded = append(expanded, value)
+	}
+	return m.transformer, expanded, nil
+}
@@ -0,0 +1,126 @@
+package parser_test
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/codeis4fun/data-treatment-interpreter/internal/parser"
+)
+
+// writeFiles writes script files under a temporary directory and returns the directory
+func writeFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+	return dir
+}
+
+func TestParseFileWithIncludes(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"main.dts":           "INCLUDE 'common/names.dts'\nSET displayName = fullname(firstName, lastName)\n",
+		"common/names.dts":   "INCLUDE 'helpers.dts'\nDEF fullname(first, last) = concatenate(' ', first, last)\nSET firstName = upper(firstName)\n",
+		"common/helpers.dts": "DEF upper(value) = uppercase(value)\n",
+	})
+
+	programs, err := parser.ParseFile(filepath.Join(dir, "main.dts"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expectedPrograms := []*parser.Program{
+		{Variables: []string{"firstName"}, Transformer: "uppercase", Args: []string{"firstName"}},
+		{Variables: []string{"displayName"}, Transformer: "concatenate", Args: []string{"' '", "firstName", "lastName"}},
+	}
+	if !reflect.DeepEqual(programs, expectedPrograms) {
+		t.Errorf("Expected programs to be %v, got %v", expectedPrograms, programs)
+	}
+}
+
+func TestParseFileWithIncludeCycle(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"a.dts": "INCLUDE 'b.dts'\n",
+		"b.dts": "SET name = uppercase(name)\nINCLUDE 'a.dts'\n",
+	})
+
+	_, err := parser.ParseFile(filepath.Join(dir, "a.dts"))
+	if err == nil {
+		t.Fatalf("Expected error, got nil")
+	}
+	if expected := "include cycle: a.dts -> b.dts -> a.dts"; !strings.Contains(err.Error(), expected) {
+		t.Errorf("Expected error to contain %q, got %q", expected, err)
+	}
+}
+
+func TestMacros(t *testing.T) {
+	tests := []struct {
+		name     string
+		script   string
+		expected *parser.Program
+	}{
+		{
+			name:     "literal and field arguments",
+			script:   "DEF label(prefix, value) = concatenate(': ', prefix, value)\nSET title = label('name', name)",
+			expected: &parser.Program{Variables: []string{"title"}, Transformer: "concatenate", Args: []string{"': '", "'name'", "name"}},
+		},
+		{
+			name:     "paths under a parameter",
+			script:   "DEF city(address) = uppercase(address.city)\nSET friends.#.city = city(friends.#.home) ON ERROR SKIP",
+			expected: &parser.Program{Variables: []string{"friends.#.city"}, Transformer: "uppercase", Args: []string{"friends.#.home.city"}, OnError: parser.ErrorSkip},
+		},
+		{
+			name:     "optional arguments",
+			script:   "DEF full(a, b) = concatenate(' ', a, b?)\nSET name = full(first, middle?)",
+			expected: &parser.Program{Variables: []string{"name"}, Transformer: "concatenate", Args: []string{"' '", "first", "middle?"}},
+		},
+		{
+			name:     "macro calling a macro",
+			script:   "DEF up(a) = uppercase(a)\nDEF shout(a) = up(a)\nLET loud = shout($greeting)",
+			expected: &parser.Program{Variables: []string{"loud"}, Transformer: "uppercase", Args: []string{"$greeting"}, Let: true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			programs, err := parser.Parse(tt.script)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if len(programs) != 1 || !reflect.DeepEqual(programs[0], tt.expected) {
+				t.Errorf("Expected programs to be [%v], got %v", tt.expected, programs)
+			}
+		})
+	}
+}
+
+func TestMacroErrors(t *testing.T) {
+	tests := []struct {
+		script   string
+		expected string
+	}{
+		{script: "DEF full(a, b) = concatenate(' ', a, b)\nSET name = full(first)", expected: "macro 'full' expects 2 argument(s), got 1 at line 2, position 11"},
+		{script: "DEF city(a) = uppercase(a.city)\nSET c = city('lisbon')", expected: "macro 'city' reads 'a.city', so argument 1 must be a field, got 'lisbon' at line 2, position 8"},
+		{script: "DEF full(a, a) = concatenate(' ', a, a)", expected: "duplicate macro parameter 'a' at line 1, position 12"},
+		{script: "DEF full(a.b) = uppercase(a)", expected: "expected macro parameter name at line 1, position 9"},
+	}
+
+	for _, tt := range tests {
+		_, err := parser.Parse(tt.script)
+		if err == nil {
+			t.Fatalf("Expected error, got nil")
+		}
+		if !strings.HasPrefix(err.Error(), tt.expected) {
+			t.Errorf("Expected error to start with %q, got %q", tt.expected, err)
+		}
+	}
+}
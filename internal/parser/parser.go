@@ -7,11 +7,24 @@ import (
 	"github.com/codeis4fun/data-treatment-interpreter/internal/lexer" // Replace with the actual import path of your lexer package
 )
 
+// ErrorAction is what happens when a statement fails, as declared by its ON ERROR clause
+type ErrorAction string
+
+const (
+	ErrorFail    ErrorAction = "FAIL"    // Stop the script, whatever the engine's error policy
+	ErrorSkip    ErrorAction = "SKIP"    // Leave the variables untouched and carry on
+	ErrorDefault ErrorAction = "DEFAULT" // Assign the default literal to the variables and carry on
+)
+
 // Program struct holds the parsed program information
 type Program struct {
-	Variables   []string // Variables being assigned
-	Transformer string   // The transformation function
-	Args        []string // Arguments to the transformation
+	Variables   []string    `json:"variables"`         // Variables being assigned
+	Transformer string      `json:"transformer"`       // The transformation function
+	Args        []string    `json:"args"`              // Arguments to the transformation
+	OnError     ErrorAction `json:"onError,omitempty"` // Empty when the statement follows the engine's error policy
+	Default     string      `json:"default,omitempty"` // Literal assigned by ON ERROR DEFAULT (e.g. 'n/a' or 0)
+	Let         bool        `json:"let,omitempty"`     // LET statements assign variables held by the engine instead of document fields
+	Redact      bool        `json:"redact,omitempty"`  // REDACT statements leave missing fields missing, whatever the engine's missing policy
 }
 
 // Parser struct, which wraps the lexer and consumes tokens
@@ -19,6 +32,9 @@ type Parser struct {
 	lexer  *lexer.Lexer
 	buffer []lexer.Token // Buffer to allow peeking tokens
 	input  string        // Store the input string for error reporting
+	dir    string        // Directory INCLUDE paths are resolved against, the working directory when empty
+	files  []string      // Files being included, outermost first, to detect cycles
+	macros map[string]*macro
 }
 
 // NewParser initializes a new parser with the given lexer and input
@@ -27,6 +43,7 @@ func NewParser(l *lexer.Lexer, input string) *Parser {
 		lexer:  l,
 		buffer: []lexer.Token{},
 		input:  input,
+		macros: map[string]*macro{},
 	}
 }
 
@@ -62,41 +79,81 @@ func (p *Parser) RunAll() ([]*Program, error) {
 
 	// Process multiple commands
 	for {
-		// Parse each program (command) individually
-		program, err := p.Run()
-		if err != nil {
-			return nil, err
+		// Skip blank lines between commands and stop once the input is exhausted
+		switch p.peekToken().Type {
+		case lexer.EOL:
+			p.nextToken()
+			continue
+		case lexer.EOF:
+			return programs, nil
 		}
 
-		if program != nil {
-			programs = append(programs, program)
-		}
+		// INCLUDE expands to the programs of another file
+		if token := p.peekToken(); token.Type == lexer.KEYWORD && token.Literal == "INCLUDE" {
+			included, err := p.parseInclude()
+			if err != nil {
+				return nil, err
+			}
+			programs = append(programs, included...)
+		} else {
+			// Parse each program (command) individually
+			program, err := p.Run()
+			if err != nil {
+				return nil, err
+			}
 
-		// Check for EOL token to see if there are more commands
-		token := p.peekToken()
+			if program != nil {
+				programs = append(programs, program)
+			}
+		}
 
-		if token.Type == lexer.EOL {
-			// Consume the EOL token and continue to check for the next token
+		// Consume the EOL token that ends the command, if any
+		if p.peekToken().Type == lexer.EOL {
 			p.nextToken()
-
-			// Peek again to see if the next token is EOF, meaning we've finished
-			if p.peekToken().Type == lexer.EOF {
-				return programs, nil
-			}
 		}
 	}
 }
 
-// parseProgram parses the input and returns a Program struct
+// Parse lexes and parses a whole script. INCLUDE paths are resolved against the working directory
+func Parse(input string) ([]*Program, error) {
+	l := lexer.NewLexer(strings.NewReader(input))
+	return NewParser(l, input).RunAll()
+}
+
+// parseProgram parses the input and returns a Program struct, or nil for macro declarations
 func (p *Parser) parseProgram() (*Program, error) {
-	// Expect 'SET' keyword
+	// Expect 'SET', 'LET', 'DEF' or 'REDACT' keyword
 	token := p.nextToken()
-	if token.Type != lexer.KEYWORD || token.Literal != "SET" {
-		return nil, p.errorWithContext(token, "expected 'SET' keyword")
+	if token.Type == lexer.KEYWORD && token.Literal == "DEF" {
+		return nil, p.parseDef()
+	}
+	if token.Type == lexer.KEYWORD && token.Literal == "REDACT" {
+		return p.parseRedact()
+	}
+	if token.Type != lexer.KEYWORD || (token.Literal != "SET" && token.Literal != "LET") {
+		return nil, p.errorWithContext(token, "expected 'SET', 'LET', 'DEF', 'INCLUDE' or 'REDACT' keyword")
 	}
 
 	// Parse the rest of the program (variables, transformer, args)
-	return p.parseAssignment()
+	program, err := p.parseAssignment()
+	if err != nil {
+		return nil, err
+	}
+	program.Let = token.Literal == "LET"
+	return program, nil
+}
+
+// parseRedact parses a statement like: REDACT friends.#.name, which is short for
+// SET friends.#.name = redact(friends.#.name?), so fields that are missing are left out
+func (p *Parser) parseRedact() (*Program, error) {
+	token := p.nextToken()
+	if err := p.isIdentifier(token); err != nil {
+		return nil, err
+	}
+	if strings.HasSuffix(token.Literal, "?") {
+		return nil, p.errorWithContext(token, "REDACT already skips missing fields, remove the '?'")
+	}
+	return &Program{Variables: []string{token.Literal}, Transformer: "redact", Args: []string{token.Literal + "?"}, Redact: true}, nil
 }
 
 // parseAssignment parses an assignment like: SET var1, var2 = transformer(arg1, arg2)
@@ -118,11 +175,50 @@ func (p *Parser) parseAssignment() (*Program, error) {
 		return nil, err
 	}
 
-	return &Program{
+	program := &Program{
 		Variables:   variables,
 		Transformer: transformer,
 		Args:        args,
-	}, nil
+	}
+
+	// Parse the optional ON ERROR clause, whose words are only keywords after the transformer call
+	if token := p.peekToken(); token.Type == lexer.IDENTIFIER && token.Literal == "ON" {
+		if err := p.parseOnError(program); err != nil {
+			return nil, err
+		}
+	}
+
+	return program, nil
+}
+
+// parseOnError parses a clause like: ON ERROR SKIP | ON ERROR DEFAULT 'value' | ON ERROR FAIL
+func (p *Parser) parseOnError(program *Program) error {
+	p.nextToken() // Consume 'ON'
+
+	token := p.nextToken()
+	if token.Type != lexer.IDENTIFIER || token.Literal != "ERROR" {
+		return p.errorWithContext(token, "expected 'ERROR' keyword")
+	}
+
+	token = p.nextToken()
+	if token.Type != lexer.IDENTIFIER {
+		return p.errorWithContext(token, "expected SKIP, DEFAULT or FAIL")
+	}
+
+	switch action := ErrorAction(token.Literal); action {
+	case ErrorSkip, ErrorFail:
+		program.OnError = action
+	case ErrorDefault:
+		value := p.nextToken()
+		if value.Type != lexer.STRING && value.Type != lexer.NUMBER {
+			return p.errorWithContext(value, "expected default value (string or number)")
+		}
+		program.OnError = action
+		program.Default = value.Literal
+	default:
+		return p.errorWithContext(token, "expected SKIP, DEFAULT or FAIL")
+	}
+	return nil
 }
 
 func (p *Parser) isIdentifier(token lexer.Token) error {
@@ -166,11 +262,11 @@ parseLoop:
 	return variables, nil
 }
 
-// check if transformer name has only alphabets
+// check if transformer name has only letters and digits, starting with a letter (e.g. sha256)
 func (p *Parser) isTransformer(token lexer.Token) error {
-	for _, r := range token.Literal {
-		if (r < 'a' || r > 'z') && (r < 'A' || r > 'Z') {
-			return p.errorWithContext(token, "transformer name should have only alphabets")
+	for i, r := range token.Literal {
+		if (r < 'a' || r > 'z') && (r < 'A' || r > 'Z') && (i == 0 || r < '0' || r > '9') {
+			return p.errorWithContext(token, "transformer name should have only letters and digits, starting with a letter")
 		}
 	}
 	return nil
@@ -184,9 +280,9 @@ func (p *Parser) parseTransformer() (string, []string, error) {
 		return "", nil, p.errorWithContext(transformer, "expected transformer name")
 	}
 
-	// Check if the transformer name has only alphabets
+	// Check if the transformer name has only letters and digits
 	if err := p.isTransformer(transformer); err != nil {
-		return "", nil, p.errorWithContext(transformer, "expected transformer name to have only alphabets")
+		return "", nil, p.errorWithContext(transformer, "expected transformer name to have only letters and digits, starting with a letter")
 	}
 
 	// Expect '(' to start argument list
@@ -202,8 +298,8 @@ parseLoop:
 		if nextToken.Type == lexer.RPAREN {
 			break
 		}
-		if nextToken.Type != lexer.IDENTIFIER && nextToken.Type != lexer.STRING {
-			return "", nil, p.errorWithContext(nextToken, "expected argument (field or string)")
+		if nextToken.Type != lexer.IDENTIFIER && nextToken.Type != lexer.STRING && nextToken.Type != lexer.PARAMETER {
+			return "", nil, p.errorWithContext(nextToken, "expected argument (field, string or $parameter)")
 		}
 		args = append(args, nextToken.Literal)
 
@@ -220,6 +316,15 @@ parseLoop:
 		}
 	}
 
+	// Calls of DEF macros are replaced by the body of the macro
+	if m, ok := p.macros[transformer.Literal]; ok {
+		name, expanded, err := m.expand(args)
+		if err != nil {
+			return "", nil, p.errorWithContext(transformer, err.Error())
+		}
+		return name, expanded, nil
+	}
+
 	return transformer.Literal, args, nil
 }
 
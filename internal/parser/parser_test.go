@@ -149,7 +149,7 @@ func TestParserWithoutSetKeyword(t *testing.T) {
 	}
 
 	var expectedError strings.Builder
-	expectedError.WriteString("expected 'SET' keyword at line 1, position 0")
+	expectedError.WriteString("expected 'SET', 'LET', 'DEF', 'INCLUDE' or 'REDACT' keyword at line 1, position 0")
 	expectedError.WriteString("\n")
 	expectedError.WriteString("a = t(b, c)")
 	expectedError.WriteString("\n")
@@ -228,3 +228,204 @@ func TestParserWithInvalidTransformerName(t *testing.T) {
 		t.Errorf("Expected error to be %q, got %q", expectedError.String(), err.Error())
 	}
 }
+
+func TestParseWithBlankLines(t *testing.T) {
+	input := `
+SET a = t(b, c)
+
+
+SET d = t(e, f)
+`
+
+	programs, err := parser.Parse(input)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expectedPrograms := []*parser.Program{
+		{Variables: []string{"a"}, Transformer: "t", Args: []string{"b", "c"}},
+		{Variables: []string{"d"}, Transformer: "t", Args: []string{"e", "f"}},
+	}
+
+	if !reflect.DeepEqual(programs, expectedPrograms) {
+		t.Errorf("Expected programs to be %v, got %v", expectedPrograms, programs)
+	}
+}
+
+func TestParseEmptyInput(t *testing.T) {
+	programs, err := parser.Parse("")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(programs) != 0 {
+		t.Errorf("Expected no programs, got %d", len(programs))
+	}
+}
+
+func TestParserWithOnErrorClause(t *testing.T) {
+	input := `SET a = t(b) ON ERROR SKIP
+SET c = t(d) ON ERROR DEFAULT 'n/a'
+SET e = t(f) ON ERROR DEFAULT 0
+SET g = t(h) ON ERROR FAIL`
+
+	programs, err := parser.Parse(input)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expectedPrograms := []*parser.Program{
+		{Variables: []string{"a"}, Transformer: "t", Args: []string{"b"}, OnError: parser.ErrorSkip},
+		{Variables: []string{"c"}, Transformer: "t", Args: []string{"d"}, OnError: parser.ErrorDefault, Default: "'n/a'"},
+		{Variables: []string{"e"}, Transformer: "t", Args: []string{"f"}, OnError: parser.ErrorDefault, Default: "0"},
+		{Variables: []string{"g"}, Transformer: "t", Args: []string{"h"}, OnError: parser.ErrorFail},
+	}
+
+	if !reflect.DeepEqual(programs, expectedPrograms) {
+		t.Errorf("Expected programs to be %v, got %v", expectedPrograms, programs)
+	}
+}
+
+func TestParserWithOnErrorWordsAsFields(t *testing.T) {
+	input := `SET x = uppercase(ERROR)
+SET FAIL = concatenate(' ', ON, SKIP, DEFAULT) ON ERROR DEFAULT 'n/a'`
+
+	programs, err := parser.Parse(input)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expectedPrograms := []*parser.Program{
+		{Variables: []string{"x"}, Transformer: "uppercase", Args: []string{"ERROR"}},
+		{Variables: []string{"FAIL"}, Transformer: "concatenate", Args: []string{"' '", "ON", "SKIP", "DEFAULT"}, OnError: parser.ErrorDefault, Default: "'n/a'"},
+	}
+
+	if !reflect.DeepEqual(programs, expectedPrograms) {
+		t.Errorf("Expected programs to be %v, got %v", expectedPrograms, programs)
+	}
+}
+
+func TestParserWithInvalidOnErrorClause(t *testing.T) {
+	input := "SET a = t(b) ON ERROR DEFAULT c"
+
+	_, err := parser.Parse(input)
+	if err == nil {
+		t.Fatalf("Expected error, but got nil")
+	}
+
+	var expectedError strings.Builder
+	expectedError.WriteString("expected default value (string or number) at line 1, position 30")
+	expectedError.WriteString("\n")
+	expectedError.WriteString("SET a = t(b) ON ERROR DEFAULT c")
+	expectedError.WriteString("\n")
+	expectedError.WriteString("                              ^")
+
+	if err.Error() != expectedError.String() {
+		t.Errorf("Expected error to be %q, got %q", expectedError.String(), err.Error())
+	}
+}
+
+func TestParserWithParameters(t *testing.T) {
+	programs, err := parser.Parse(`SET id = concatenate('-', $tenant, id)`)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expectedPrograms := []*parser.Program{
+		{Variables: []string{"id"}, Transformer: "concatenate", Args: []string{"'-'", "$tenant", "id"}},
+	}
+
+	if !reflect.DeepEqual(programs, expectedPrograms) {
+		t.Errorf("Expected programs to be %v, got %v", expectedPrograms, programs)
+	}
+}
+
+func TestParserWithLet(t *testing.T) {
+	programs, err := parser.Parse("LET full = concatenate(' ', name, surname)\nSET greeting = concatenate(' ', 'hello', full)")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expectedPrograms := []*parser.Program{
+		{Variables: []string{"full"}, Transformer: "concatenate", Args: []string{"' '", "name", "surname"}, Let: true},
+		{Variables: []string{"greeting"}, Transformer: "concatenate", Args: []string{"' '", "'hello'", "full"}},
+	}
+
+	if !reflect.DeepEqual(programs, expectedPrograms) {
+		t.Errorf("Expected programs to be %v, got %v", expectedPrograms, programs)
+	}
+}
+
+func TestParserWithDigitsInTransformerName(t *testing.T) {
+	programs, err := parser.Parse(`SET id = sha256(email)`)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expectedPrograms := []*parser.Program{
+		{Variables: []string{"id"}, Transformer: "sha256", Args: []string{"email"}},
+	}
+
+	if !reflect.DeepEqual(programs, expectedPrograms) {
+		t.Errorf("Expected programs to be %v, got %v", expectedPrograms, programs)
+	}
+
+	_, err = parser.Parse(`SET id = sha_256(email)`)
+	if err == nil {
+		t.Fatalf("Expected error, but got nil")
+	}
+	expected := "expected transformer name to have only letters and digits, starting with a letter at line 1, position 9"
+	if !strings.HasPrefix(err.Error(), expected) {
+		t.Errorf("Expected error to start with %q, got %q", expected, err.Error())
+	}
+}
+
+func TestParserWithRedact(t *testing.T) {
+	programs, err := parser.Parse("REDACT ssn\nREDACT friends.#.name")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expectedPrograms := []*parser.Program{
+		{Variables: []string{"ssn"}, Transformer: "redact", Args: []string{"ssn?"}, Redact: true},
+		{Variables: []string{"friends.#.name"}, Transformer: "redact", Args: []string{"friends.#.name?"}, Redact: true},
+	}
+
+	if !reflect.DeepEqual(programs, expectedPrograms) {
+		t.Errorf("Expected programs to be %v, got %v", expectedPrograms, programs)
+	}
+
+	_, err = parser.Parse("REDACT ssn?")
+	if err == nil {
+		t.Fatalf("Expected error, but got nil")
+	}
+	expected := "REDACT already skips missing fields, remove the '?' at line 1, position 7"
+	if !strings.HasPrefix(err.Error(), expected) {
+		t.Errorf("Expected error to start with %q, got %q", expected, err.Error())
+	}
+}
+
+func TestParseWithMacros(t *testing.T) {
+	macros := parser.NewMacros()
+	if _, err := parser.ParseWithMacros("DEF up(value) = uppercase(value)", macros); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	programs, err := parser.ParseWithMacros("SET name = up(name)", macros)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := []*parser.Program{{Variables: []string{"name"}, Transformer: "uppercase", Args: []string{"name"}}}
+	if !reflect.DeepEqual(programs, expected) {
+		t.Errorf("Expected programs to be %v, got %v", expected, programs)
+	}
+
+	// A clone taken before a declaration does not see it
+	clone := macros.Clone()
+	if _, err := parser.ParseWithMacros("DEF low(value) = lowercase(value)", macros); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if programs, err := parser.ParseWithMacros("SET name = low(name)", clone); err != nil || programs[0].Transformer != "low" {
+		t.Errorf("Expected low to be left as a transformer call, got %v, %v", programs, err)
+	}
+}
@@ -0,0 +1,346 @@
+package repl
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/codeis4fun/data-treatment-interpreter/internal/diff"
+	"github.com/codeis4fun/data-treatment-interpreter/internal/engine"
+	"github.com/codeis4fun/data-treatment-interpreter/internal/parser"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/pretty"
+)
+
+// errQuit is returned by Eval when the user asks to leave the REPL
+var errQuit = errors.New("quit")
+
+const prompt = "dts> "
+
+// commands lists the REPL commands with their help text
+var commands = map[string]string{
+	":help":  "show this help",
+	":show":  "print the document, or the value at a path (:show friends.0)",
+	":diff":  "toggle printing a diff instead of the document after each statement",
+	":undo":  "revert the last statement",
+	":reset": "restore the original document and forget all statements",
+	":save":  "write the accepted statements to a script file (:save rules.dts)",
+	":quit":  "leave the REPL",
+}
+
+// step records a statement that was applied, and the document, LET variables and macros before it ran
+type step struct {
+	statement string
+	before    []byte
+	env       *engine.Environment
+	macros    parser.Macros
+}
+
+// REPL keeps a JSON document in memory and applies statements to it one line at a time
+type REPL struct {
+	engine   *engine.Engine
+	original []byte
+	document []byte
+	env      *engine.Environment // LET variables, kept between lines like the document
+	macros   parser.Macros       // DEF macros declared by earlier lines
+	history  []step
+	out      io.Writer
+	color    bool // Colour the JSON output, only enabled on terminals
+	diff     bool // Print the changes made by each statement instead of the whole document
+}
+
+// New initializes a REPL over a copy of the input document
+func New(e *engine.Engine, input []byte, out io.Writer) (*REPL, error) {
+	if !gjson.ValidBytes(input) {
+		return nil, fmt.Errorf("input is not valid JSON")
+	}
+	return &REPL{
+		engine:   e,
+		original: append([]byte(nil), input...),
+		document: append([]byte(nil), input...),
+		env:      engine.NewEnvironment(),
+		macros:   parser.NewMacros(),
+		out:      out,
+	}, nil
+}
+
+// Document returns the current state of the document
+func (r *REPL) Document() []byte {
+	return r.document
+}
+
+// Statements returns the statements applied so far, in order
+func (r *REPL) Statements() []string {
+	statements := make([]string, 0, len(r.history))
+	for _, step := range r.history {
+		statements = append(statements, step.statement)
+	}
+	return statements
+}
+
+// Run reads lines from the input until it is exhausted or the user quits. When the input
+// is a terminal, the line editor supports tab completion and history
+func (r *REPL) Run(in io.Reader) error {
+	var reader lineReader
+	if f, ok := in.(*os.File); ok {
+		if terminal, err := newTerminal(f, r.out, r.Complete); err == nil {
+			reader = terminal
+			r.color = true
+		}
+	}
+	if reader == nil {
+		reader = &plainReader{scanner: bufio.NewScanner(in), out: r.out}
+	}
+	defer reader.Close()
+
+	fmt.Fprintln(r.out, "Type statements such as SET name = uppercase(name), or :help for commands.")
+	for {
+		line, err := reader.ReadLine(prompt)
+		if err == io.EOF {
+			fmt.Fprintln(r.out)
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		if err := r.Eval(line); err != nil {
+			if err == errQuit {
+				return nil
+			}
+			fmt.Fprintln(r.out, "Error:", err)
+		}
+	}
+}
+
+// Eval runs a single REPL command or DSL statement
+func (r *REPL) Eval(line string) error {
+	line = strings.TrimSpace(line)
+	if line == "" {
+		return nil
+	}
+	if strings.HasPrefix(line, ":") {
+		return r.command(line)
+	}
+	return r.statement(line)
+}
+
+// statement parses a line with the DSL parser and applies it to the document
+func (r *REPL) statement(line string) error {
+	// Macros declared by earlier lines can be called, and the ones the line declares are kept when it succeeds
+	macros := r.macros.Clone()
+	programs, err := parser.ParseWithMacros(line, macros)
+	if err != nil {
+		return err
+	}
+
+	// Temporary and LET variables are kept between lines so they can be used by later statements.
+	// The line runs on a copy of the LET variables, kept only when it succeeds
+	document := r.document
+	env := r.env.Clone()
+	for _, program := range programs {
+		document, err = r.engine.Execute(program, document, engine.WithEnvironment(env))
+		if err != nil {
+			return err
+		}
+	}
+
+	r.history = append(r.history, step{statement: line, before: r.document, env: r.env, macros: r.macros})
+	r.document = document
+	r.env = env
+	r.macros = macros
+	if r.diff {
+		return diff.Compare(r.history[len(r.history)-1].before, r.document).Format(r.out, r.color)
+	}
+	return r.show("")
+}
+
+// command runs a REPL command starting with ':'
+func (r *REPL) command(line string) error {
+	name, arg, _ := strings.Cut(line, " ")
+	arg = strings.TrimSpace(arg)
+
+	switch name {
+	case ":help":
+		names := make([]string, 0, len(commands))
+		for name := range commands {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			fmt.Fprintf(r.out, "  %-7s %s\n", name, commands[name])
+		}
+		return nil
+	case ":show":
+		return r.show(arg)
+	case ":diff":
+		r.diff = !r.diff
+		if r.diff {
+			fmt.Fprintln(r.out, "Printing a diff after each statement")
+		} else {
+			fmt.Fprintln(r.out, "Printing the document after each statement")
+		}
+		return nil
+	case ":undo":
+		if len(r.history) == 0 {
+			return fmt.Errorf("nothing to undo")
+		}
+		last := r.history[len(r.history)-1]
+		r.history = r.history[:len(r.history)-1]
+		r.document = last.before
+		r.env = last.env
+		r.macros = last.macros
+		fmt.Fprintf(r.out, "Undid: %s\n", last.statement)
+		return r.show("")
+	case ":reset":
+		r.history = nil
+		r.document = append([]byte(nil), r.original...)
+		r.env = engine.NewEnvironment()
+		r.macros = parser.NewMacros()
+		return r.show("")
+	case ":save":
+		if arg == "" {
+			return fmt.Errorf(":save requires a file name")
+		}
+		script := strings.Join(r.Statements(), "\n") + "\n"
+		if err := os.WriteFile(arg, []byte(script), 0o644); err != nil {
+			return err
+		}
+		fmt.Fprintf(r.out, "Saved %d statement(s) to %s\n", len(r.history), arg)
+		return nil
+	case ":quit", ":exit":
+		return errQuit
+	default:
+		return fmt.Errorf("unknown command '%s' (try :help)", name)
+	}
+}
+
+// show prints the whole document, or the value at the given path, which may be a LET variable
+func (r *REPL) show(path string) error {
+	raw := r.document
+	if path != "" {
+		value := gjson.GetBytes(r.document, path)
+		if name, _, _ := strings.Cut(path, "."); r.env.Get(name).Exists() {
+			value = r.env.Get(path)
+		}
+		if !value.Exists() {
+			return fmt.Errorf("path '%s' not found in JSON", path)
+		}
+		raw = []byte(value.Raw)
+	}
+	raw = pretty.Pretty(raw)
+	if r.color {
+		raw = pretty.Color(raw, nil)
+	}
+	_, err := r.out.Write(raw)
+	return err
+}
+
+// Complete returns the candidates that complete the last word of the line: commands at the start of the
+// line, transformer and macro names after '=', and field paths of the document and LET variables elsewhere
+func (r *REPL) Complete(line string) []string {
+	start := strings.LastIndexAny(line, " (,=") + 1
+	word := line[start:]
+	before := strings.TrimSpace(line[:start])
+
+	var candidates []string
+	switch {
+	case start == 0 && strings.HasPrefix(word, ":"):
+		for name := range commands {
+			candidates = append(candidates, name)
+		}
+	case strings.HasSuffix(before, "="):
+		for _, signature := range r.engine.Signatures() {
+			candidates = append(candidates, signature.Name)
+		}
+		for name := range r.macros {
+			candidates = append(candidates, name)
+		}
+	case start == 0:
+		candidates = []string{"SET", "LET", "DEF", "REDACT"}
+	default:
+		candidates = append(Paths(r.document), r.env.Names()...)
+	}
+
+	var matches []string
+	for _, candidate := range candidates {
+		if strings.HasPrefix(candidate, word) {
+			matches = append(matches, candidate)
+		}
+	}
+	sort.Strings(matches)
+	return matches
+}
+
+// Paths lists the field paths of a JSON document, using '#' for array elements
+func Paths(document []byte) []string {
+	seen := map[string]bool{}
+	collectPaths(gjson.ParseBytes(document), "", seen)
+
+	paths := make([]string, 0, len(seen))
+	for path := range seen {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// collectPaths walks a value and records the path of every nested field
+func collectPaths(value gjson.Result, prefix string, seen map[string]bool) {
+	join := func(key string) string {
+		if prefix == "" {
+			return key
+		}
+		return prefix + "." + key
+	}
+
+	switch {
+	case value.IsObject():
+		value.ForEach(func(key, child gjson.Result) bool {
+			path := join(key.String())
+			seen[path] = true
+			collectPaths(child, path, seen)
+			return true
+		})
+	case value.IsArray():
+		path := join("#")
+		value.ForEach(func(_, child gjson.Result) bool {
+			seen[path] = true
+			collectPaths(child, path, seen)
+			return true
+		})
+	}
+}
+
+// lineReader reads lines of input for the REPL
+type lineReader interface {
+	ReadLine(prompt string) (string, error)
+	Close() error
+}
+
+// plainReader reads lines without editing support, for pipes and unsupported terminals
+type plainReader struct {
+	scanner *bufio.Scanner
+	out     io.Writer
+}
+
+// ReadLine prints the prompt and reads the next line
+func (p *plainReader) ReadLine(prompt string) (string, error) {
+	fmt.Fprint(p.out, prompt)
+	if !p.scanner.Scan() {
+		if err := p.scanner.Err(); err != nil {
+			return "", err
+		}
+		return "", io.EOF
+	}
+	return p.scanner.Text(), nil
+}
+
+// Close does nothing for plain readers
+func (p *plainReader) Close() error {
+	return nil
+}
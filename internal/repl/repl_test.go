@@ -0,0 +1,253 @@
+package repl_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/codeis4fun/data-treatment-interpreter/internal/engine"
+	"github.com/codeis4fun/data-treatment-interpreter/internal/repl"
+)
+
+func newREPL(t *testing.T, input string) (*repl.REPL, *bytes.Buffer) {
+	t.Helper()
+	var out bytes.Buffer
+	r, err := repl.New(engine.NewEngine(), []byte(input), &out)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	return r, &out
+}
+
+func TestEvalStatement(t *testing.T) {
+	r, out := newREPL(t, `{"name":"john"}`)
+
+	if err := r.Eval("SET name = uppercase(name)"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := `{"name":"JOHN"}`
+	if string(r.Document()) != expected {
+		t.Errorf("Expected %s, got %s", expected, r.Document())
+	}
+	if !strings.Contains(out.String(), `"name": "JOHN"`) {
+		t.Errorf("Expected the document to be printed, got %q", out.String())
+	}
+}
+
+func TestEvalKeepsDocumentOnError(t *testing.T) {
+	r, _ := newREPL(t, `{"name":"john"}`)
+
+	if err := r.Eval("SET name = uppercase(surname)"); err == nil {
+		t.Fatalf("Expected error, got nil")
+	}
+	if err := r.Eval("SET name = "); err == nil {
+		t.Fatalf("Expected syntax error, got nil")
+	}
+
+	expected := `{"name":"john"}`
+	if string(r.Document()) != expected {
+		t.Errorf("Expected %s, got %s", expected, r.Document())
+	}
+	if len(r.Statements()) != 0 {
+		t.Errorf("Expected no statements, got %v", r.Statements())
+	}
+}
+
+func TestLetAcrossLines(t *testing.T) {
+	r, out := newREPL(t, `{"name":"john","surname":"doe"}`)
+
+	for _, line := range []string{"LET full = concatenate(' ', name, surname)", "SET fullName = uppercase(full)"} {
+		if err := r.Eval(line); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+	expected := `{"name":"john","surname":"doe","fullName":"JOHN DOE"}`
+	if string(r.Document()) != expected {
+		t.Errorf("Expected %s, got %s", expected, r.Document())
+	}
+
+	out.Reset()
+	if err := r.Eval(":show full"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if strings.TrimSpace(out.String()) != `"john doe"` {
+		t.Errorf("Expected the LET variable to be shown, got %q", out.String())
+	}
+
+	// Undoing the LET statement forgets the variable
+	for _, line := range []string{":undo", ":undo"} {
+		if err := r.Eval(line); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+	if err := r.Eval("SET fullName = uppercase(full)"); err == nil {
+		t.Errorf("Expected error after undoing the LET statement, got nil")
+	}
+}
+
+func TestMacrosAcrossLines(t *testing.T) {
+	r, _ := newREPL(t, `{"name":"john","surname":"doe"}`)
+
+	for _, line := range []string{"DEF up(value) = uppercase(value)", "SET name = up(name)"} {
+		if err := r.Eval(line); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+	expected := `{"name":"JOHN","surname":"doe"}`
+	if string(r.Document()) != expected {
+		t.Errorf("Expected %s, got %s", expected, r.Document())
+	}
+	if actual := r.Complete("SET surname = up"); !reflect.DeepEqual(actual, []string{"up", "uppercase"}) {
+		t.Errorf("Expected [up uppercase], got %v", actual)
+	}
+
+	// Undoing the DEF statement forgets the macro
+	for _, line := range []string{":undo", ":undo"} {
+		if err := r.Eval(line); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+	if err := r.Eval("SET surname = up(surname)"); err == nil {
+		t.Errorf("Expected error after undoing the DEF statement, got nil")
+	}
+}
+
+func TestUndoAndReset(t *testing.T) {
+	r, _ := newREPL(t, `{"name":"john","surname":"doe"}`)
+
+	for _, line := range []string{
+		"SET name = uppercase(name)",
+		"SET surname = uppercase(surname)",
+		":undo",
+	} {
+		if err := r.Eval(line); err != nil {
+			t.Fatalf("Unexpected error for %q: %v", line, err)
+		}
+	}
+
+	expected := `{"name":"JOHN","surname":"doe"}`
+	if string(r.Document()) != expected {
+		t.Errorf("Expected %s, got %s", expected, r.Document())
+	}
+
+	if err := r.Eval(":reset"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected = `{"name":"john","surname":"doe"}`
+	if string(r.Document()) != expected {
+		t.Errorf("Expected %s, got %s", expected, r.Document())
+	}
+
+	if err := r.Eval(":undo"); err == nil {
+		t.Errorf("Expected error when there is nothing to undo, got nil")
+	}
+}
+
+func TestShowPath(t *testing.T) {
+	r, out := newREPL(t, `{"friends":[{"name":"Alice"}]}`)
+
+	if err := r.Eval(":show friends.0.name"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if strings.TrimSpace(out.String()) != `"Alice"` {
+		t.Errorf("Expected %q, got %q", `"Alice"`, out.String())
+	}
+
+	if err := r.Eval(":show friends.1.name"); err == nil {
+		t.Errorf("Expected error for a missing path, got nil")
+	}
+}
+
+func TestSave(t *testing.T) {
+	r, _ := newREPL(t, `{"name":"john"}`)
+	path := filepath.Join(t.TempDir(), "rules.dts")
+
+	for _, line := range []string{
+		"SET name = uppercase(name)",
+		"SET surname = uppercase(name)",
+		":undo",
+		"SET _name = concatenate(' ', name, name)",
+		":save " + path,
+	} {
+		r.Eval(line)
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := "SET name = uppercase(name)\nSET _name = concatenate(' ', name, name)\n"
+	if string(content) != expected {
+		t.Errorf("Expected %q, got %q", expected, string(content))
+	}
+}
+
+func TestComplete(t *testing.T) {
+	r, _ := newREPL(t, `{"name":"john","friends":[{"name":"Alice","age":30}]}`)
+
+	tests := []struct {
+		line     string
+		expected []string
+	}{
+		{line: ":u", expected: []string{":undo"}},
+		{line: "S", expected: []string{"SET"}},
+		{line: "RE", expected: []string{"REDACT"}},
+		{line: "D", expected: []string{"DEF"}},
+		{line: "SET name = upp", expected: []string{"uppercase"}},
+		{line: "SET name=con", expected: []string{"concatenate", "contains", "convert"}},
+		{line: "SET name = uppercase(fr", expected: []string{"friends", "friends.#", "friends.#.age", "friends.#.name"}},
+		{line: "SET name = concatenate(' ', friends.#.n", expected: []string{"friends.#.name"}},
+		{line: "SET na", expected: []string{"name"}},
+	}
+
+	for _, tt := range tests {
+		actual := r.Complete(tt.line)
+		if !reflect.DeepEqual(actual, tt.expected) {
+			t.Errorf("Complete(%q): expected %v, got %v", tt.line, tt.expected, actual)
+		}
+	}
+}
+
+func TestRunWithPlainInput(t *testing.T) {
+	r, out := newREPL(t, `{"name":"john"}`)
+
+	input := strings.NewReader("SET name = uppercase(name)\n:unknown\n:quit\nSET name = uppercase(nothing)\n")
+	if err := r.Run(input); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if !strings.Contains(out.String(), "Error: unknown command ':unknown'") {
+		t.Errorf("Expected unknown command error, got %q", out.String())
+	}
+	if strings.Contains(out.String(), "nothing") {
+		t.Errorf("Expected the REPL to stop at :quit, got %q", out.String())
+	}
+
+	expected := `{"name":"JOHN"}`
+	if string(r.Document()) != expected {
+		t.Errorf("Expected %s, got %s", expected, r.Document())
+	}
+}
+
+func TestDiffMode(t *testing.T) {
+	r, out := newREPL(t, `{"name":"john"}`)
+
+	if err := r.Eval(":diff"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	out.Reset()
+
+	if err := r.Eval("SET name = uppercase(name)"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := "~ /name: \"john\" -> \"JOHN\"\n"
+	if out.String() != expected {
+		t.Errorf("Expected %q, got %q", expected, out.String())
+	}
+}
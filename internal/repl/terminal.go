@@ -0,0 +1,136 @@
+package repl
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+)
+
+// Control bytes understood by the line editor
+const (
+	keyCtrlC     = 3
+	keyCtrlD     = 4
+	keyBackspace = 8
+	keyTab       = 9
+	keyLineFeed  = 10
+	keyEnter     = 13
+	keyEscape    = 27
+	keyDelete    = 127
+)
+
+// terminal is a minimal line editor for raw-mode terminals with tab completion and history
+type terminal struct {
+	in       *bufio.Reader
+	out      io.Writer
+	complete func(line string) []string
+	history  []string
+	restore  func() error
+}
+
+// ReadLine reads a line, handling editing keys, completion and history navigation
+func (t *terminal) ReadLine(prompt string) (string, error) {
+	var line []byte
+	position := len(t.history)
+
+	redraw := func() {
+		fmt.Fprintf(t.out, "\r\x1b[K%s%s", prompt, line)
+	}
+	redraw()
+
+	for {
+		b, err := t.in.ReadByte()
+		if err != nil {
+			return "", err
+		}
+
+		switch b {
+		case keyEnter, keyLineFeed:
+			fmt.Fprint(t.out, "\r\n")
+			if len(line) > 0 {
+				t.history = append(t.history, string(line))
+			}
+			return string(line), nil
+		case keyCtrlC:
+			// Discard the current line
+			fmt.Fprint(t.out, "^C\r\n")
+			line = line[:0]
+			redraw()
+		case keyCtrlD:
+			if len(line) == 0 {
+				return "", io.EOF
+			}
+		case keyBackspace, keyDelete:
+			if len(line) > 0 {
+				_, size := utf8.DecodeLastRune(line)
+				line = line[:len(line)-size]
+				redraw()
+			}
+		case keyTab:
+			line = t.completeLine(line)
+			redraw()
+		case keyEscape:
+			// Arrow keys arrive as ESC [ A (up) and ESC [ B (down); everything else is ignored
+			if next, _ := t.in.ReadByte(); next != '[' {
+				continue
+			}
+			switch key, _ := t.in.ReadByte(); key {
+			case 'A':
+				if position > 0 {
+					position--
+					line = []byte(t.history[position])
+				}
+			case 'B':
+				if position < len(t.history) {
+					position++
+					line = line[:0]
+					if position < len(t.history) {
+						line = []byte(t.history[position])
+					}
+				}
+			}
+			redraw()
+		default:
+			// Printable bytes, including the parts of multi-byte UTF-8 characters, are echoed as typed
+			if b >= ' ' {
+				line = append(line, b)
+				t.out.Write([]byte{b})
+			}
+		}
+	}
+}
+
+// completeLine completes the last word of the line, listing the candidates when there is more than one
+func (t *terminal) completeLine(line []byte) []byte {
+	candidates := t.complete(string(line))
+	if len(candidates) == 0 {
+		return line
+	}
+
+	start := strings.LastIndexAny(string(line), " (,=") + 1
+	word := string(line[start:])
+	prefix := commonPrefix(candidates)
+
+	if len(candidates) > 1 && prefix == word {
+		fmt.Fprintf(t.out, "\r\n%s\r\n", strings.Join(candidates, "  "))
+		return line
+	}
+	return append(line[:start], prefix...)
+}
+
+// commonPrefix returns the longest prefix shared by all candidates
+func commonPrefix(candidates []string) string {
+	prefix := candidates[0]
+	for _, candidate := range candidates[1:] {
+		for !strings.HasPrefix(candidate, prefix) {
+			prefix = prefix[:len(prefix)-1]
+		}
+	}
+	return prefix
+}
+
+// Close restores the terminal to its original mode
+func (t *terminal) Close() error {
+	return t.restore()
+}
@@ -0,0 +1,47 @@
+//go:build linux
+
+package repl
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+// newTerminal switches the file to raw mode and returns a line editor reading from it.
+// It fails when the file is not a terminal
+func newTerminal(f *os.File, out io.Writer, complete func(line string) []string) (lineReader, error) {
+	fd := f.Fd()
+
+	var original syscall.Termios
+	if err := ioctl(fd, syscall.TCGETS, &original); err != nil {
+		return nil, err
+	}
+
+	// Disable echo, line buffering and signal keys so the editor sees every key press
+	raw := original
+	raw.Iflag &^= syscall.ICRNL | syscall.IXON
+	raw.Lflag &^= syscall.ECHO | syscall.ICANON | syscall.ISIG | syscall.IEXTEN
+	raw.Cc[syscall.VMIN] = 1
+	raw.Cc[syscall.VTIME] = 0
+	if err := ioctl(fd, syscall.TCSETS, &raw); err != nil {
+		return nil, err
+	}
+
+	return &terminal{
+		in:       bufio.NewReader(f),
+		out:      out,
+		complete: complete,
+		restore:  func() error { return ioctl(fd, syscall.TCSETS, &original) },
+	}, nil
+}
+
+// ioctl reads or writes the terminal attributes of a file descriptor
+func ioctl(fd uintptr, request uintptr, termios *syscall.Termios) error {
+	if _, _, errno := syscall.Syscall(syscall.SYS_IOCTL, fd, request, uintptr(unsafe.Pointer(termios))); errno != 0 {
+		return errno
+	}
+	return nil
+}
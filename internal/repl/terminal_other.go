@@ -0,0 +1,14 @@
+//go:build !linux
+
+package repl
+
+import (
+	"errors"
+	"io"
+	"os"
+)
+
+// newTerminal is only supported on Linux; other platforms fall back to plain line reading
+func newTerminal(f *os.File, out io.Writer, complete func(line string) []string) (lineReader, error) {
+	return nil, errors.New("line editing is not supported on this platform")
+}
@@ -0,0 +1,29 @@
+package transformers
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/tidwall/gjson"
+)
+
+// Coalesce struct holds the arguments and JSON data for transformation
+type Coalesce struct {
+	Config
+}
+
+// Transform returns the first argument that resolves to a value other than null. Fields keep their JSON type
+func (t *Coalesce) Transform() (Results, error) {
+	if len(t.Args) < 2 {
+		return nil, fmt.Errorf("coalesce requires at least two arguments")
+	}
+
+	for i := range t.Args {
+		value := t.Value(i)
+		if value.Exists() && value.Type != gjson.Null {
+			return Results{json.RawMessage(value.Raw)}, nil
+		}
+	}
+	return nil, fmt.Errorf("no value found in %s", strings.Join(t.Args, ", "))
+}
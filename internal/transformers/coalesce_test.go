@@ -0,0 +1,57 @@
+package transformers_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/codeis4fun/data-treatment-interpreter/internal/transformers"
+)
+
+func TestCoalesceTransform(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		expected string
+	}{
+		{name: "first field", args: []string{"nickname", "name"}, expected: `"johnny"`},
+		{name: "skips missing field", args: []string{"middleName", "name"}, expected: `"john"`},
+		{name: "skips null field", args: []string{"phone", "age"}, expected: `30`},
+		{name: "keeps objects", args: []string{"middleName", "address"}, expected: `{"city":"Lisbon"}`},
+		{name: "literal fallback", args: []string{"middleName", "phone", "'unknown'"}, expected: `"unknown"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transformer := &transformers.Coalesce{
+				Config: transformers.Config{
+					Args: tt.args,
+					Json: []byte(`{"nickname":"johnny","name":"john","phone":null,"age":30,"address":{"city":"Lisbon"}}`),
+				},
+			}
+
+			results, err := transformer.Transform()
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			actual, _ := json.Marshal(results[0])
+			if string(actual) != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, actual)
+			}
+		})
+	}
+}
+
+func TestCoalesceTransformWithoutValue(t *testing.T) {
+	transformer := &transformers.Coalesce{
+		Config: transformers.Config{
+			Args: []string{"middleName", "phone"},
+			Json: []byte(`{"phone":null}`),
+		},
+	}
+
+	_, err := transformer.Transform()
+	if err == nil {
+		t.Fatalf("Expected error, but got nil")
+	}
+}
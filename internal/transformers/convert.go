@@ -0,0 +1,249 @@
+package transformers
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/tidwall/gjson"
+)
+
+// Conversion modes. Strict conversions only accept the canonical forms of a type and fail on anything
+// else; lenient ones also accept common loose forms (e.g. "1,234.50" or "yes") and return null when a
+// value still cannot be converted
+const (
+	Strict  = "strict"
+	Lenient = "lenient"
+)
+
+// lenient reads the optional conversion mode at index i, strict by default
+func (c Config) lenient(i int) (bool, error) {
+	if i >= len(c.Args) {
+		return false, nil
+	}
+	switch mode := c.Text(i); mode {
+	case Strict:
+		return false, nil
+	case Lenient:
+		return true, nil
+	default:
+		return false, fmt.Errorf("unknown mode '%s' (expected strict or lenient)", mode)
+	}
+}
+
+// convertible returns the value to convert and the conversion mode, failing when the value is missing
+func (c Config) convertible(name string) (gjson.Result, bool, error) {
+	if len(c.Args) < 1 || len(c.Args) > 2 {
+		return gjson.Result{}, false, fmt.Errorf("%s requires one or two arguments", name)
+	}
+	value := c.Value(0)
+	if !value.Exists() {
+		return value, false, fmt.Errorf("argument '%s' not found in JSON", c.Args[0])
+	}
+	lenient, err := c.lenient(1)
+	return value, lenient, err
+}
+
+// failed is the result of a conversion that is not possible: an error in strict mode and null in lenient mode
+func failed(lenient bool, value gjson.Result, kind string) (Results, error) {
+	if lenient {
+		return Results{nil}, nil
+	}
+	return nil, fmt.Errorf("cannot convert %s to %s", value.Raw, kind)
+}
+
+// toDecimal converts a value to an exact decimal. Strict mode accepts numbers and strings holding a
+// JSON number; lenient mode also accepts booleans (1 and 0), a leading + and thousands separators
+func toDecimal(value gjson.Result, lenient bool) (*big.Rat, error) {
+	switch value.Type {
+	case gjson.Number:
+		return parseDecimal(value.Raw)
+	case gjson.String:
+		r, err := parseDecimal(value.Str)
+		if err == errNotNumber && lenient {
+			text := strings.TrimPrefix(strings.TrimSpace(value.Str), "+")
+			return parseDecimal(strings.ReplaceAll(text, ",", ""))
+		}
+		return r, err
+	case gjson.True, gjson.False:
+		if lenient {
+			if value.Bool() {
+				return big.NewRat(1, 1), nil
+			}
+			return new(big.Rat), nil
+		}
+	}
+	return nil, errNotNumber
+}
+
+// ToNumber converts a value to a JSON number
+type ToNumber struct {
+	Config
+}
+
+// Transform returns the input as a number, written without trailing zeros
+func (t *ToNumber) Transform() (Results, error) {
+	value, lenient, err := t.convertible("tonumber")
+	if err != nil {
+		return nil, err
+	}
+	n, err := toDecimal(value, lenient)
+	if err == errOutOfRange {
+		return nil, fmt.Errorf("cannot convert %s to a number: it is out of range", value.Raw)
+	}
+	if err != nil {
+		return failed(lenient, value, "a number")
+	}
+	return Results{decimal(n)}, nil
+}
+
+// ToInt converts a value to a JSON integer
+type ToInt struct {
+	Config
+}
+
+// Transform returns the input as an integer. Strict mode fails on numbers with a fraction, lenient mode
+// truncates them towards zero
+func (t *ToInt) Transform() (Results, error) {
+	value, lenient, err := t.convertible("toint")
+	if err != nil {
+		return nil, err
+	}
+	n, err := toDecimal(value, lenient)
+	if err == errOutOfRange {
+		return nil, fmt.Errorf("cannot convert %s to an integer: it is out of range", value.Raw)
+	}
+	if err != nil {
+		return failed(lenient, value, "an integer")
+	}
+	if !n.IsInt() && !lenient {
+		return nil, fmt.Errorf("cannot convert %s to an integer without losing its fraction", value.Raw)
+	}
+	return Results{json.Number(new(big.Int).Quo(n.Num(), n.Denom()).String())}, nil
+}
+
+// ToBool converts a value to a JSON boolean
+type ToBool struct {
+	Config
+}
+
+// strictBooleans and lenientBooleans are the strings converted to booleans, compared without case
+var (
+	strictBooleans  = map[string]bool{"true": true, "false": false, "1": true, "0": false}
+	lenientBooleans = map[string]bool{"t": true, "f": false, "yes": true, "no": false, "y": true, "n": false, "on": true, "off": false}
+)
+
+// Transform returns the input as a boolean. Strict mode accepts booleans, the numbers 1 and 0 and the
+// strings true, false, 1 and 0; lenient mode also accepts any number (true unless 0) and the strings
+// t, f, yes, no, y, n, on and off
+func (t *ToBool) Transform() (Results, error) {
+	value, lenient, err := t.convertible("tobool")
+	if err != nil {
+		return nil, err
+	}
+	switch value.Type {
+	case gjson.True, gjson.False:
+		return Results{value.Bool()}, nil
+	case gjson.Number:
+		if n, err := parseDecimal(value.Raw); err == nil && (lenient || n.Sign() == 0 || n.Cmp(big.NewRat(1, 1)) == 0) {
+			return Results{n.Sign() != 0}, nil
+		}
+	case gjson.String:
+		text := strings.ToLower(strings.TrimSpace(value.Str))
+		if b, ok := strictBooleans[text]; ok {
+			return Results{b}, nil
+		}
+		if b, ok := lenientBooleans[text]; ok && lenient {
+			return Results{b}, nil
+		}
+	}
+	return failed(lenient, value, "a boolean")
+}
+
+// ToString converts a value to a JSON string
+type ToString struct {
+	Config
+}
+
+// Transform returns the input as a string. Strict mode accepts strings, numbers and booleans; lenient
+// mode also writes arrays and objects as their JSON text
+func (t *ToString) Transform() (Results, error) {
+	value, lenient, err := t.convertible("tostring")
+	if err != nil {
+		return nil, err
+	}
+	switch value.Type {
+	case gjson.String:
+		return Results{value.Str}, nil
+	case gjson.Number, gjson.True, gjson.False:
+		return Results{value.Raw}, nil
+	case gjson.JSON:
+		if lenient {
+			return Results{value.Raw}, nil
+		}
+	}
+	return failed(lenient, value, "a string")
+}
+
+// ToArray wraps a value in an array
+type ToArray struct {
+	Config
+}
+
+// Transform returns arrays unchanged, null as an empty array and any other value as an array holding it
+func (t *ToArray) Transform() (Results, error) {
+	if len(t.Args) != 1 {
+		return nil, fmt.Errorf("toarray requires exactly one argument")
+	}
+	value := t.Value(0)
+	switch {
+	case !value.Exists():
+		return nil, fmt.Errorf("argument '%s' not found in JSON", t.Args[0])
+	case value.IsArray():
+		return Results{json.RawMessage(value.Raw)}, nil
+	case value.Type == gjson.Null:
+		return Results{json.RawMessage("[]")}, nil
+	default:
+		return Results{json.RawMessage("[" + value.Raw + "]")}, nil
+	}
+}
+
+// TypeOf returns the JSON type of a value
+type TypeOf struct {
+	Config
+}
+
+// Transform returns string, number, boolean, null, array or object, or missing when the field does not exist
+func (t *TypeOf) Transform() (Results, error) {
+	if len(t.Args) != 1 {
+		return nil, fmt.Errorf("typeof requires exactly one argument")
+	}
+	value := t.Value(0)
+	switch {
+	case !value.Exists():
+		return Results{"missing"}, nil
+	case value.IsArray():
+		return Results{"array"}, nil
+	case value.IsObject():
+		return Results{"object"}, nil
+	case value.Type == gjson.True || value.Type == gjson.False:
+		return Results{"boolean"}, nil
+	default:
+		return Results{strings.ToLower(value.Type.String())}, nil
+	}
+}
+
+// IsNull tests whether a value is null
+type IsNull struct {
+	Config
+}
+
+// Transform returns true when the field is null or missing
+func (t *IsNull) Transform() (Results, error) {
+	if len(t.Args) != 1 {
+		return nil, fmt.Errorf("isnull requires exactly one argument")
+	}
+	value := t.Value(0)
+	return Results{!value.Exists() || value.Type == gjson.Null}, nil
+}
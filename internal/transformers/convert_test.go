@@ -0,0 +1,88 @@
+package transformers_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/codeis4fun/data-treatment-interpreter/internal/transformers"
+)
+
+func TestConversionTransformers(t *testing.T) {
+	input := []byte(`{"text":"75.50","grouped":"+1,234.5","fraction":-2.75,"integer":"42","yes":"Yes","one":1,"two":2,"flag":true,"null":null,"list":[1,2],"object":{"a":1},"word":"n/a"}`)
+	tests := []struct {
+		name        string
+		transformer transformer
+		expected    any
+	}{
+		{name: "tonumber string", transformer: &transformers.ToNumber{Config: jsonConfig(input, "text")}, expected: json.Number("75.5")},
+		{name: "tonumber lenient separators", transformer: &transformers.ToNumber{Config: jsonConfig(input, "grouped", "'lenient'")}, expected: json.Number("1234.5")},
+		{name: "tonumber lenient boolean", transformer: &transformers.ToNumber{Config: jsonConfig(input, "flag", "'lenient'")}, expected: json.Number("1")},
+		{name: "tonumber lenient failure", transformer: &transformers.ToNumber{Config: jsonConfig(input, "word", "'lenient'")}, expected: nil},
+		{name: "toint string", transformer: &transformers.ToInt{Config: jsonConfig(input, "integer")}, expected: json.Number("42")},
+		{name: "toint lenient truncates", transformer: &transformers.ToInt{Config: jsonConfig(input, "fraction", "'lenient'")}, expected: json.Number("-2")},
+		{name: "tobool number", transformer: &transformers.ToBool{Config: jsonConfig(input, "one")}, expected: true},
+		{name: "tobool lenient number", transformer: &transformers.ToBool{Config: jsonConfig(input, "two", "'lenient'")}, expected: true},
+		{name: "tobool lenient word", transformer: &transformers.ToBool{Config: jsonConfig(input, "yes", "'lenient'")}, expected: true},
+		{name: "tobool lenient failure", transformer: &transformers.ToBool{Config: jsonConfig(input, "word", "'lenient'")}, expected: nil},
+		{name: "tostring number", transformer: &transformers.ToString{Config: jsonConfig(input, "fraction")}, expected: "-2.75"},
+		{name: "tostring boolean", transformer: &transformers.ToString{Config: jsonConfig(input, "flag", "'strict'")}, expected: "true"},
+		{name: "tostring lenient object", transformer: &transformers.ToString{Config: jsonConfig(input, "object", "'lenient'")}, expected: `{"a":1}`},
+		{name: "tostring lenient null", transformer: &transformers.ToString{Config: jsonConfig(input, "null", "'lenient'")}, expected: nil},
+		{name: "toarray scalar", transformer: &transformers.ToArray{Config: jsonConfig(input, "one")}, expected: json.RawMessage("[1]")},
+		{name: "typeof number", transformer: &transformers.TypeOf{Config: jsonConfig(input, "one")}, expected: "number"},
+		{name: "typeof boolean", transformer: &transformers.TypeOf{Config: jsonConfig(input, "flag")}, expected: "boolean"},
+		{name: "typeof null", transformer: &transformers.TypeOf{Config: jsonConfig(input, "null")}, expected: "null"},
+		{name: "typeof array", transformer: &transformers.TypeOf{Config: jsonConfig(input, "list")}, expected: "array"},
+		{name: "typeof missing", transformer: &transformers.TypeOf{Config: jsonConfig(input, "missing")}, expected: "missing"},
+		{name: "isnull missing", transformer: &transformers.IsNull{Config: jsonConfig(input, "missing")}, expected: true},
+		{name: "isnull value", transformer: &transformers.IsNull{Config: jsonConfig(input, "word")}, expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results, err := tt.transformer.Transform()
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if raw, ok := tt.expected.(json.RawMessage); ok {
+				if string(results[0].(json.RawMessage)) != string(raw) {
+					t.Errorf("Expected %s, got %s", raw, results[0])
+				}
+				return
+			}
+			if results[0] != tt.expected {
+				t.Errorf("Expected %v (%T), got %v (%T)", tt.expected, tt.expected, results[0], results[0])
+			}
+		})
+	}
+}
+
+func TestConversionTransformersWithInvalidArguments(t *testing.T) {
+	input := []byte(`{"word":"n/a","fraction":2.5,"two":2,"list":[1],"null":null,"huge":"1e5000"}`)
+	tests := []struct {
+		name        string
+		transformer transformer
+		expected    string
+	}{
+		{name: "tonumber word", transformer: &transformers.ToNumber{Config: jsonConfig(input, "word")}, expected: `cannot convert "n/a" to a number`},
+		{name: "tonumber out of range", transformer: &transformers.ToNumber{Config: jsonConfig(input, "huge", "'lenient'")}, expected: `cannot convert "1e5000" to a number: it is out of range`},
+		{name: "toint fraction", transformer: &transformers.ToInt{Config: jsonConfig(input, "fraction")}, expected: "cannot convert 2.5 to an integer without losing its fraction"},
+		{name: "tobool two", transformer: &transformers.ToBool{Config: jsonConfig(input, "two")}, expected: "cannot convert 2 to a boolean"},
+		{name: "tostring array", transformer: &transformers.ToString{Config: jsonConfig(input, "list")}, expected: "cannot convert [1] to a string"},
+		{name: "tostring null", transformer: &transformers.ToString{Config: jsonConfig(input, "null")}, expected: "cannot convert null to a string"},
+		{name: "missing", transformer: &transformers.ToNumber{Config: jsonConfig(input, "missing", "'lenient'")}, expected: "argument 'missing' not found in JSON"},
+		{name: "unknown mode", transformer: &transformers.ToNumber{Config: jsonConfig(input, "word", "'loose'")}, expected: "unknown mode 'loose' (expected strict or lenient)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.transformer.Transform()
+			if err == nil {
+				t.Fatalf("Expected error, but got nil")
+			}
+			if err.Error() != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, err)
+			}
+		})
+	}
+}
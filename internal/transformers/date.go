@@ -0,0 +1,403 @@
+package transformers
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/tidwall/gjson"
+)
+
+// dateLayouts are tried in order when a date is read without a layout. Dates without a zone are UTC
+var dateLayouts = []string{time.RFC3339Nano, "2006-01-02T15:04:05", "2006-01-02 15:04:05", "2006-01-02"}
+
+// epochMillis is the smallest epoch taken as milliseconds rather than seconds: as seconds it would be in the year 5138
+const epochMillis = 1e11
+
+// strftime maps the strftime directives that have an equivalent in Go layouts
+var strftime = map[byte]string{
+	'Y': "2006", 'y': "06", 'm': "01", 'd': "02", 'e': "_2", 'j': "002",
+	'H': "15", 'I': "03", 'M': "04", 'S': "05", 'f': "000000", 'p': "PM",
+	'b': "Jan", 'B': "January", 'a': "Mon", 'A': "Monday",
+	'z': "-0700", 'Z': "MST", 'F': "2006-01-02", 'T': "15:04:05", '%': "%",
+}
+
+// now returns the current time according to the clock of the engine
+func (c Config) now() time.Time {
+	if c.Now != nil {
+		return c.Now()
+	}
+	return time.Now()
+}
+
+// layout converts a layout as written in a script to a Go layout. Layouts containing '%' use strftime
+// directives (e.g. '%d/%m/%Y'), rfc3339 names RFC 3339 and anything else is a Go layout (e.g. '02/01/2006').
+// unix and unixmilli are returned as is, for epochs
+func layout(name string) (string, error) {
+	switch name {
+	case "", "rfc3339":
+		return time.RFC3339Nano, nil
+	case "unix", "unixmilli":
+		return name, nil
+	}
+	if !strings.Contains(name, "%") {
+		return name, nil
+	}
+
+	var builder strings.Builder
+	for i := 0; i < len(name); i++ {
+		if name[i] != '%' {
+			builder.WriteByte(name[i])
+			continue
+		}
+		if i+1 == len(name) {
+			return "", fmt.Errorf("layout '%s' ends with '%%'", name)
+		}
+		i++
+		directive, ok := strftime[name[i]]
+		if !ok {
+			return "", fmt.Errorf("unsupported directive '%%%c' in layout '%s'", name[i], name)
+		}
+		builder.WriteString(directive)
+	}
+	return builder.String(), nil
+}
+
+// location loads a time zone by IANA name (e.g. 'Europe/Lisbon'), or UTC when the name is empty
+func location(name string) (*time.Location, error) {
+	if name == "" {
+		return time.UTC, nil
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf("unknown time zone '%s'", name)
+	}
+	return loc, nil
+}
+
+// parseDate reads a date from a JSON value. Numbers are Unix epochs, in seconds or, from 1e11 on, in
+// milliseconds, unless the layout says unix or unixmilli. Strings are parsed with the layout (as epochs
+// for unix and unixmilli), or with RFC 3339 and its common variants when there is none, so strings of
+// digits such as '20240715' are never mistaken for epochs. Dates without a zone are in loc
+func parseDate(value gjson.Result, name string, loc *time.Location) (time.Time, error) {
+	text := value.String()
+	if value.Type == gjson.Number || (value.Type == gjson.String && (name == "unix" || name == "unixmilli")) {
+		epoch, err := strconv.ParseInt(text, 10, 64)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid epoch '%s'", text)
+		}
+		switch {
+		case name == "unixmilli" || (name != "unix" && (epoch >= epochMillis || epoch <= -epochMillis)):
+			return time.UnixMilli(epoch).In(loc), nil
+		default:
+			return time.Unix(epoch, 0).In(loc), nil
+		}
+	}
+	if value.Type != gjson.String {
+		return time.Time{}, fmt.Errorf("date must be a string or a number, got %s", value.Raw)
+	}
+
+	layouts := dateLayouts
+	if name != "" {
+		goLayout, err := layout(name)
+		if err != nil {
+			return time.Time{}, err
+		}
+		layouts = []string{goLayout}
+	}
+	for _, goLayout := range layouts {
+		if date, err := time.ParseInLocation(goLayout, text, loc); err == nil {
+			return date, nil
+		}
+	}
+	if name == "" {
+		return time.Time{}, fmt.Errorf("cannot parse date '%s'", text)
+	}
+	return time.Time{}, fmt.Errorf("cannot parse date '%s' with layout '%s'", text, name)
+}
+
+// formatDate formats a date with a layout as written in a script. Epoch layouts return numbers
+func formatDate(date time.Time, name string) (any, error) {
+	goLayout, err := layout(name)
+	if err != nil {
+		return nil, err
+	}
+	switch goLayout {
+	case "unix":
+		return date.Unix(), nil
+	case "unixmilli":
+		return date.UnixMilli(), nil
+	}
+	return date.Format(goLayout), nil
+}
+
+// date reads the date in the field named by the argument at index i, in UTC unless it has a zone
+func (c Config) date(i int) (time.Time, error) {
+	value := c.Field(i)
+	if !value.Exists() {
+		return time.Time{}, fmt.Errorf("argument '%s' not found in JSON", c.Args[i])
+	}
+	return parseDate(value, "", time.UTC)
+}
+
+// ParseDate reads a date and returns it in RFC 3339
+type ParseDate struct {
+	Config
+}
+
+// Transform parses the input with the layout, if any, in the time zone, if any
+func (t *ParseDate) Transform() (Results, error) {
+	if len(t.Args) < 1 || len(t.Args) > 3 {
+		return nil, fmt.Errorf("parsedate requires between one and three arguments")
+	}
+	value := t.Field(0)
+	if !value.Exists() {
+		return nil, fmt.Errorf("argument '%s' not found in JSON", t.Args[0])
+	}
+
+	var name, zone string
+	if len(t.Args) > 1 {
+		name = t.Text(1)
+	}
+	if len(t.Args) > 2 {
+		zone = t.Text(2)
+	}
+	loc, err := location(zone)
+	if err != nil {
+		return nil, err
+	}
+	date, err := parseDate(value, name, loc)
+	if err != nil {
+		return nil, err
+	}
+	return Results{date.Format(time.RFC3339Nano)}, nil
+}
+
+// FormatDate formats a date with a layout, optionally in another time zone
+type FormatDate struct {
+	Config
+}
+
+// Transform formats the input, which is read like parsedate without a layout
+func (t *FormatDate) Transform() (Results, error) {
+	if len(t.Args) < 2 || len(t.Args) > 3 {
+		return nil, fmt.Errorf("formatdate requires two or three arguments")
+	}
+	date, err := t.date(0)
+	if err != nil {
+		return nil, err
+	}
+	if len(t.Args) == 3 {
+		loc, err := location(t.Text(2))
+		if err != nil {
+			return nil, err
+		}
+		date = date.In(loc)
+	}
+	formatted, err := formatDate(date, t.Text(1))
+	if err != nil {
+		return nil, err
+	}
+	return Results{formatted}, nil
+}
+
+// Now returns the current time
+type Now struct {
+	Config
+}
+
+// Transform returns the current time of the engine's clock in RFC 3339, in UTC
+func (t *Now) Transform() (Results, error) {
+	if len(t.Args) != 0 {
+		return nil, fmt.Errorf("now takes no arguments")
+	}
+	return Results{t.now().UTC().Format(time.RFC3339Nano)}, nil
+}
+
+// DateAdd adds a period to a date
+type DateAdd struct {
+	Config
+}
+
+// Transform adds the period to the input and returns the result in RFC 3339, in the zone of the input
+func (t *DateAdd) Transform() (Results, error) {
+	if len(t.Args) != 2 {
+		return nil, fmt.Errorf("dateadd requires exactly two arguments")
+	}
+	date, err := t.date(0)
+	if err != nil {
+		return nil, err
+	}
+	date, err = addPeriod(date, t.Text(1))
+	if err != nil {
+		return nil, err
+	}
+	return Results{date.Format(time.RFC3339Nano)}, nil
+}
+
+// addPeriod adds a period such as '3d', '-1y6mo' or '1h30m' to a date. Years (y), months (mo), weeks (w)
+// and days (d) follow the calendar, so adding months to the 31st lands on the last day of shorter months;
+// hours (h), minutes (m), seconds (s) and milliseconds (ms) are exact
+func addPeriod(date time.Time, period string) (time.Time, error) {
+	text, negative := strings.CutPrefix(period, "-")
+	text = strings.TrimPrefix(text, "+")
+	if text == "" {
+		return time.Time{}, fmt.Errorf("invalid period '%s'", period)
+	}
+
+	sign := 1
+	if negative {
+		sign = -1
+	}
+	for text != "" {
+		digits := strings.IndexFunc(text, func(r rune) bool { return !unicode.IsDigit(r) })
+		if digits <= 0 {
+			return time.Time{}, fmt.Errorf("invalid period '%s'", period)
+		}
+		letters := strings.IndexFunc(text[digits:], unicode.IsDigit)
+		if letters < 0 {
+			letters = len(text) - digits
+		}
+		amount, err := strconv.Atoi(text[:digits])
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid period '%s'", period)
+		}
+		amount *= sign
+
+		switch unit := text[digits : digits+letters]; unit {
+		case "y":
+			date = addMonths(date, 12*amount)
+		case "mo":
+			date = addMonths(date, amount)
+		case "w":
+			date = date.AddDate(0, 0, 7*amount)
+		case "d":
+			date = date.AddDate(0, 0, amount)
+		case "h":
+			date = date.Add(time.Duration(amount) * time.Hour)
+		case "m":
+			date = date.Add(time.Duration(amount) * time.Minute)
+		case "s":
+			date = date.Add(time.Duration(amount) * time.Second)
+		case "ms":
+			date = date.Add(time.Duration(amount) * time.Millisecond)
+		default:
+			return time.Time{}, fmt.Errorf("unknown unit '%s' in period '%s' (expected y, mo, w, d, h, m, s or ms)", unit, period)
+		}
+		text = text[digits+letters:]
+	}
+	return date, nil
+}
+
+// addMonths adds calendar months to a date, clamping the day to the end of the resulting month
+// (time.AddDate would turn January 31st plus one month into March 2nd)
+func addMonths(date time.Time, months int) time.Time {
+	year, month, day := date.Date()
+	hour, minute, second := date.Clock()
+	last := time.Date(year, month+time.Month(months)+1, 0, 0, 0, 0, 0, date.Location()).Day()
+	return time.Date(year, month+time.Month(months), min(day, last), hour, minute, second, date.Nanosecond(), date.Location())
+}
+
+// DateDiff counts the whole units between two dates
+type DateDiff struct {
+	Config
+}
+
+// Transform returns the number of whole units from the first date to the second, negative when the
+// second is earlier. Months and years follow the calendar
+func (t *DateDiff) Transform() (Results, error) {
+	if len(t.Args) != 3 {
+		return nil, fmt.Errorf("datediff requires exactly three arguments")
+	}
+	start, err := t.date(0)
+	if err != nil {
+		return nil, err
+	}
+	end, err := t.date(1)
+	if err != nil {
+		return nil, err
+	}
+
+	elapsed := end.Sub(start)
+	switch unit := strings.TrimSuffix(t.Text(2), "s"); unit {
+	case "millisecond":
+		return Results{elapsed.Milliseconds()}, nil
+	case "second":
+		return Results{int64(elapsed / time.Second)}, nil
+	case "minute":
+		return Results{int64(elapsed / time.Minute)}, nil
+	case "hour":
+		return Results{int64(elapsed / time.Hour)}, nil
+	case "day":
+		return Results{int64(elapsed / (24 * time.Hour))}, nil
+	case "week":
+		return Results{int64(elapsed / (7 * 24 * time.Hour))}, nil
+	case "month":
+		return Results{months(start, end)}, nil
+	case "year":
+		return Results{months(start, end) / 12}, nil
+	default:
+		return nil, fmt.Errorf("unknown unit '%s' (expected milliseconds, seconds, minutes, hours, days, weeks, months or years)", t.Text(2))
+	}
+}
+
+// months counts the whole calendar months from start to end
+func months(start, end time.Time) int64 {
+	if end.Before(start) {
+		return -months(end, start)
+	}
+	count := (end.Year()-start.Year())*12 + int(end.Month()) - int(start.Month())
+	if count > 0 && addMonths(start, count).After(end) {
+		count--
+	}
+	return int64(count)
+}
+
+// Age counts the whole years since a date
+type Age struct {
+	Config
+}
+
+// Transform returns the age in whole years on the given date, or now
+func (t *Age) Transform() (Results, error) {
+	if len(t.Args) < 1 || len(t.Args) > 2 {
+		return nil, fmt.Errorf("age requires one or two arguments")
+	}
+	birth, err := t.date(0)
+	if err != nil {
+		return nil, err
+	}
+	on := t.now()
+	if len(t.Args) == 2 {
+		if on, err = t.date(1); err != nil {
+			return nil, err
+		}
+	}
+	if on.Before(birth) {
+		return nil, fmt.Errorf("date '%s' is in the future", t.Field(0).String())
+	}
+	return Results{months(birth, on) / 12}, nil
+}
+
+// ToTimezone converts a date to another time zone
+type ToTimezone struct {
+	Config
+}
+
+// Transform returns the same instant in RFC 3339 with the offset of the time zone
+func (t *ToTimezone) Transform() (Results, error) {
+	if len(t.Args) != 2 {
+		return nil, fmt.Errorf("totimezone requires exactly two arguments")
+	}
+	date, err := t.date(0)
+	if err != nil {
+		return nil, err
+	}
+	loc, err := location(t.Text(1))
+	if err != nil {
+		return nil, err
+	}
+	return Results{date.In(loc).Format(time.RFC3339Nano)}, nil
+}
@@ -0,0 +1,79 @@
+package transformers_test
+
+import (
+	"testing"
+
+	"github.com/codeis4fun/data-treatment-interpreter/internal/transformers"
+)
+
+func TestDateTransformers(t *testing.T) {
+	json := []byte(`{"iso":"2024-02-29T23:30:00+01:00","day":"2024-01-31","compact":"20240715","epoch":1721032200,"millis":1721032200123,"text":"1721032200","birth":"2000-02-29","local":"15/07/2024 09:30"}`)
+	tests := []struct {
+		name        string
+		transformer transformer
+		expected    any
+	}{
+		{name: "parsedate rfc3339", transformer: &transformers.ParseDate{Config: jsonConfig(json, "iso")}, expected: "2024-02-29T23:30:00+01:00"},
+		{name: "parsedate date only", transformer: &transformers.ParseDate{Config: jsonConfig(json, "day")}, expected: "2024-01-31T00:00:00Z"},
+		{name: "parsedate strftime", transformer: &transformers.ParseDate{Config: jsonConfig(json, "compact", "'%Y%m%d'")}, expected: "2024-07-15T00:00:00Z"},
+		{name: "parsedate go layout", transformer: &transformers.ParseDate{Config: jsonConfig(json, "local", "'02/01/2006 15:04'", "'America/New_York'")}, expected: "2024-07-15T09:30:00-04:00"},
+		{name: "parsedate epoch seconds", transformer: &transformers.ParseDate{Config: jsonConfig(json, "epoch")}, expected: "2024-07-15T08:30:00Z"},
+		{name: "parsedate epoch millis", transformer: &transformers.ParseDate{Config: jsonConfig(json, "millis")}, expected: "2024-07-15T08:30:00.123Z"},
+		{name: "parsedate epoch string", transformer: &transformers.ParseDate{Config: jsonConfig(json, "text", "'unix'")}, expected: "2024-07-15T08:30:00Z"},
+		{name: "formatdate strftime", transformer: &transformers.FormatDate{Config: jsonConfig(json, "iso", "'%d/%m/%y %I:%M %p'", "'UTC'")}, expected: "29/02/24 10:30 PM"},
+		{name: "formatdate unixmilli", transformer: &transformers.FormatDate{Config: jsonConfig(json, "millis", "'unixmilli'")}, expected: int64(1721032200123)},
+		{name: "now", transformer: &transformers.Now{Config: jsonConfig(json)}, expected: "2024-02-28T12:00:00Z"},
+		{name: "dateadd clamps months", transformer: &transformers.DateAdd{Config: jsonConfig(json, "day", "'1mo'")}, expected: "2024-02-29T00:00:00Z"},
+		{name: "dateadd negative", transformer: &transformers.DateAdd{Config: jsonConfig(json, "iso", "'-1y1d12h'")}, expected: "2023-02-27T11:30:00+01:00"},
+		{name: "dateadd keeps zone", transformer: &transformers.DateAdd{Config: jsonConfig(json, "iso", "'30m'")}, expected: "2024-03-01T00:00:00+01:00"},
+		{name: "datediff days", transformer: &transformers.DateDiff{Config: jsonConfig(json, "day", "iso", "'days'")}, expected: int64(29)},
+		{name: "datediff negative hours", transformer: &transformers.DateDiff{Config: jsonConfig(json, "iso", "day", "'hours'")}, expected: int64(-718)},
+		{name: "datediff months from the 31st", transformer: &transformers.DateDiff{Config: jsonConfig(json, "day", "iso", "'months'")}, expected: int64(1)},
+		{name: "datediff years", transformer: &transformers.DateDiff{Config: jsonConfig(json, "birth", "iso", "'years'")}, expected: int64(24)},
+		{name: "age now", transformer: &transformers.Age{Config: jsonConfig(json, "birth")}, expected: int64(23)},
+		{name: "age on date", transformer: &transformers.Age{Config: jsonConfig(json, "birth", "iso")}, expected: int64(24)},
+		{name: "totimezone", transformer: &transformers.ToTimezone{Config: jsonConfig(json, "iso", "'Asia/Kolkata'")}, expected: "2024-03-01T04:00:00+05:30"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results, err := tt.transformer.Transform()
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if results[0] != tt.expected {
+				t.Errorf("Expected %v (%T), got %v (%T)", tt.expected, tt.expected, results[0], results[0])
+			}
+		})
+	}
+}
+
+func TestDateTransformersWithInvalidArguments(t *testing.T) {
+	json := []byte(`{"day":"2024-01-31","future":"2999-01-01","flag":true,"compact":"20240715"}`)
+
+	tests := []struct {
+		name        string
+		transformer transformer
+		expected    string
+	}{
+		{name: "unparseable", transformer: &transformers.ParseDate{Config: transformers.Config{Args: []string{"compact"}, Json: json}}, expected: "cannot parse date '20240715'"},
+		{name: "not a date", transformer: &transformers.ParseDate{Config: transformers.Config{Args: []string{"flag"}, Json: json}}, expected: "date must be a string or a number, got true"},
+		{name: "unknown directive", transformer: &transformers.FormatDate{Config: transformers.Config{Args: []string{"day", "'%d %Q'"}, Json: json}}, expected: "unsupported directive '%Q' in layout '%d %Q'"},
+		{name: "unknown zone", transformer: &transformers.ToTimezone{Config: transformers.Config{Args: []string{"day", "'Lisbon'"}, Json: json}}, expected: "unknown time zone 'Lisbon'"},
+		{name: "invalid period", transformer: &transformers.DateAdd{Config: transformers.Config{Args: []string{"day", "'d3'"}, Json: json}}, expected: "invalid period 'd3'"},
+		{name: "unknown unit", transformer: &transformers.DateDiff{Config: transformers.Config{Args: []string{"day", "day", "'fortnights'"}, Json: json}}, expected: "unknown unit 'fortnights' (expected milliseconds, seconds, minutes, hours, days, weeks, months or years)"},
+		{name: "birth in the future", transformer: &transformers.Age{Config: transformers.Config{Args: []string{"future", "day"}, Json: json}}, expected: "date '2999-01-01' is in the future"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.transformer.Transform()
+			if err == nil {
+				t.Fatalf("Expected error, but got nil")
+			}
+			if err.Error() != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, err)
+			}
+		})
+	}
+}
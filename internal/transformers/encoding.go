@@ -0,0 +1,129 @@
+package transformers
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"strings"
+	"unicode/utf8"
+)
+
+// Base64Encode encodes a value in base64
+type Base64Encode struct {
+	Config
+}
+
+// Transform returns the input in standard base64, or in the URL-safe alphabet without padding when 'url' is given
+func (t *Base64Encode) Transform() (Results, error) {
+	if len(t.Args) < 1 || len(t.Args) > 2 {
+		return nil, fmt.Errorf("base64encode requires one or two arguments")
+	}
+	encoding := base64.StdEncoding
+	if len(t.Args) == 2 {
+		switch variant := t.Text(1); variant {
+		case "std":
+		case "url":
+			encoding = base64.RawURLEncoding
+		default:
+			return nil, fmt.Errorf("unknown base64 variant '%s' (expected std or url)", variant)
+		}
+	}
+	content, err := t.content(0)
+	if err != nil {
+		return nil, err
+	}
+	return Results{encoding.EncodeToString(content)}, nil
+}
+
+// Base64Decode decodes a base64 value
+type Base64Decode struct {
+	Config
+}
+
+// Transform returns the text encoded by the input, which may use the standard or the URL-safe alphabet,
+// with or without padding. The decoded bytes must be UTF-8 text, since JSON strings cannot hold binary data
+func (t *Base64Decode) Transform() (Results, error) {
+	if len(t.Args) != 1 {
+		return nil, fmt.Errorf("base64decode requires exactly one argument")
+	}
+	value, err := t.text(0)
+	if err != nil {
+		return nil, err
+	}
+	normalized := strings.TrimRight(strings.NewReplacer("-", "+", "_", "/").Replace(strings.TrimSpace(value)), "=")
+	decoded, err := base64.RawStdEncoding.DecodeString(normalized)
+	if err != nil {
+		return nil, fmt.Errorf("argument '%s' is not valid base64: %v", t.Args[0], err)
+	}
+	if !utf8.Valid(decoded) {
+		return nil, fmt.Errorf("argument '%s' does not decode to UTF-8 text", t.Args[0])
+	}
+	return Results{string(decoded)}, nil
+}
+
+// HexEncode encodes a value in hexadecimal
+type HexEncode struct {
+	Config
+}
+
+// Transform returns the bytes of the input as lowercase hexadecimal
+func (t *HexEncode) Transform() (Results, error) {
+	if len(t.Args) != 1 {
+		return nil, fmt.Errorf("hexencode requires exactly one argument")
+	}
+	content, err := t.content(0)
+	if err != nil {
+		return nil, err
+	}
+	return Results{hex.EncodeToString(content)}, nil
+}
+
+// URLEncode escapes a value for a URL
+type URLEncode struct {
+	Config
+}
+
+// Transform escapes the input for a query string (spaces become +), or for a path segment (spaces
+// become %20) when 'path' is given
+func (t *URLEncode) Transform() (Results, error) {
+	if len(t.Args) < 1 || len(t.Args) > 2 {
+		return nil, fmt.Errorf("urlencode requires one or two arguments")
+	}
+	escape := url.QueryEscape
+	if len(t.Args) == 2 {
+		switch component := t.Text(1); component {
+		case "query":
+		case "path":
+			escape = url.PathEscape
+		default:
+			return nil, fmt.Errorf("unknown URL component '%s' (expected query or path)", component)
+		}
+	}
+	content, err := t.content(0)
+	if err != nil {
+		return nil, err
+	}
+	return Results{escape(string(content))}, nil
+}
+
+// URLDecode unescapes a value of a URL
+type URLDecode struct {
+	Config
+}
+
+// Transform unescapes the input as a query string, where + is a space
+func (t *URLDecode) Transform() (Results, error) {
+	if len(t.Args) != 1 {
+		return nil, fmt.Errorf("urldecode requires exactly one argument")
+	}
+	value, err := t.text(0)
+	if err != nil {
+		return nil, err
+	}
+	decoded, err := url.QueryUnescape(value)
+	if err != nil {
+		return nil, fmt.Errorf("argument '%s' is not a valid URL-encoded value: %v", t.Args[0], err)
+	}
+	return Results{decoded}, nil
+}
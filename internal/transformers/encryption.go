@@ -0,0 +1,166 @@
+package transformers
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+
+	"github.com/tidwall/gjson"
+)
+
+// envelopePrefix starts the values written by encrypt, followed by the key id, the nonce and the ciphertext
+// separated by colons. The prefix and the key id are authenticated with the ciphertext, so an envelope cannot
+// be moved to another key
+const envelopePrefix = "enc:v1:"
+
+// keyID matches the ids of the keyring, which must not contain the colons separating the parts of an envelope
+var keyID = regexp.MustCompile(`^[A-Za-z0-9._-]+$`)
+
+// Keyring holds the AES keys used by encrypt and decrypt, by id. Keys are never written in scripts: the
+// scripts only name them
+type Keyring struct {
+	keys map[string]cipher.AEAD
+}
+
+// NewKeyring creates a keyring from AES-128, AES-192 or AES-256 keys (16, 24 or 32 bytes) by id
+func NewKeyring(keys map[string][]byte) (*Keyring, error) {
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("keyring has no keys")
+	}
+	keyring := &Keyring{keys: make(map[string]cipher.AEAD, len(keys))}
+	for id, key := range keys {
+		if !keyID.MatchString(id) {
+			return nil, fmt.Errorf("key id '%s' should have only letters, digits, '.', '-' and '_'", id)
+		}
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			return nil, fmt.Errorf("key '%s' must be 16, 24 or 32 bytes long, got %d", id, len(key))
+		}
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			return nil, err
+		}
+		keyring.keys[id] = aead
+	}
+	return keyring, nil
+}
+
+// ParseKeyring reads a keyring from a JSON object mapping key ids to base64 keys, such as
+// {"2026-01":"<32 bytes in base64>"}. Keep every key that encrypted stored data:
+// rotating keys means adding a key and encrypting with it, while values encrypted with older keys still decrypt
+func ParseKeyring(r io.Reader) (*Keyring, error) {
+	var encoded map[string]string
+	if err := json.NewDecoder(r).Decode(&encoded); err != nil {
+		return nil, fmt.Errorf("cannot read keyring: %w", err)
+	}
+	keys := make(map[string][]byte, len(encoded))
+	for id, text := range encoded {
+		key, err := base64.StdEncoding.DecodeString(text)
+		if err != nil {
+			return nil, fmt.Errorf("key '%s' is not valid base64: %v", id, err)
+		}
+		keys[id] = key
+	}
+	return NewKeyring(keys)
+}
+
+// key returns the cipher of a key id
+func (k *Keyring) key(id string) (cipher.AEAD, error) {
+	if k == nil {
+		return nil, fmt.Errorf("no keyring loaded")
+	}
+	aead, ok := k.keys[id]
+	if !ok {
+		return nil, fmt.Errorf("unknown key '%s'", id)
+	}
+	return aead, nil
+}
+
+// Encrypt encrypts a value with a key of the keyring
+type Encrypt struct {
+	Config
+	Keyring *Keyring
+}
+
+// Transform returns the JSON text of the input (see canonical) encrypted with AES-GCM and a random nonce, in
+// an envelope naming the key: enc:v1:<key id>:<nonce>:<ciphertext>, both in unpadded URL-safe base64.
+// Encrypting the same value twice gives different envelopes
+func (t *Encrypt) Transform() (Results, error) {
+	if len(t.Args) != 2 {
+		return nil, fmt.Errorf("encrypt requires exactly two arguments")
+	}
+	value := t.Value(0)
+	if !value.Exists() {
+		return nil, fmt.Errorf("argument '%s' not found in JSON", t.Args[0])
+	}
+	id := t.Text(1)
+	aead, err := t.Keyring.key(id)
+	if err != nil {
+		return nil, err
+	}
+
+	var plaintext bytes.Buffer
+	canonical(&plaintext, value)
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("cannot generate a nonce: %w", err)
+	}
+	header := envelopePrefix + id
+	ciphertext := aead.Seal(nil, nonce, plaintext.Bytes(), []byte(header))
+	return Results{header + ":" + base64.RawURLEncoding.EncodeToString(nonce) + ":" + base64.RawURLEncoding.EncodeToString(ciphertext)}, nil
+}
+
+// Decrypt decrypts a value written by encrypt
+type Decrypt struct {
+	Config
+	Keyring *Keyring
+}
+
+// Transform returns the value in an envelope written by encrypt, with its JSON type. The key is the one named
+// by the envelope, so values encrypted before a key rotation still decrypt; when a key id is given, envelopes
+// naming another key are rejected
+func (t *Decrypt) Transform() (Results, error) {
+	if len(t.Args) < 1 || len(t.Args) > 2 {
+		return nil, fmt.Errorf("decrypt requires one or two arguments")
+	}
+	value := t.Value(0)
+	if !value.Exists() {
+		return nil, fmt.Errorf("argument '%s' not found in JSON", t.Args[0])
+	}
+	if value.Type != gjson.String || !strings.HasPrefix(value.Str, envelopePrefix) {
+		return nil, fmt.Errorf("argument '%s' is not an encrypted value", t.Args[0])
+	}
+	parts := strings.Split(strings.TrimPrefix(value.Str, envelopePrefix), ":")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("argument '%s' is not an encrypted value", t.Args[0])
+	}
+	id := parts[0]
+	if len(t.Args) == 2 && t.Text(1) != id {
+		return nil, fmt.Errorf("argument '%s' is encrypted with key '%s', not '%s'", t.Args[0], id, t.Text(1))
+	}
+	aead, err := t.Keyring.key(id)
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil || len(nonce) != aead.NonceSize() {
+		return nil, fmt.Errorf("argument '%s' is not an encrypted value", t.Args[0])
+	}
+	ciphertext, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("argument '%s' is not an encrypted value", t.Args[0])
+	}
+
+	plaintext, err := aead.Open(nil, nonce, ciphertext, []byte(envelopePrefix+id))
+	if err != nil || !json.Valid(plaintext) {
+		return nil, fmt.Errorf("cannot decrypt argument '%s' with key '%s': wrong key or altered value", t.Args[0], id)
+	}
+	return Results{json.RawMessage(plaintext)}, nil
+}
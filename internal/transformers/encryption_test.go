@@ -0,0 +1,209 @@
+package transformers_test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/codeis4fun/data-treatment-interpreter/internal/transformers"
+)
+
+// testKeys are made-up keys for the tests, never to be used for real data
+var testKeys = map[string][]byte{
+	"2025-01": []byte("0123456789abcdef0123456789abcdef"),
+	"2026-01": []byte("fedcba9876543210fedcba9876543210"),
+}
+
+func newKeyring(t *testing.T, ids ...string) *transformers.Keyring {
+	t.Helper()
+	keys := map[string][]byte{}
+	for _, id := range ids {
+		keys[id] = testKeys[id]
+	}
+	keyring, err := transformers.NewKeyring(keys)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	return keyring
+}
+
+func encrypt(t *testing.T, keyring *transformers.Keyring, json, id string) string {
+	t.Helper()
+	results, err := (&transformers.Encrypt{Config: transformers.Config{Args: []string{"value", "'" + id + "'"}, Json: []byte(json)}, Keyring: keyring}).Transform()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	return results[0].(string)
+}
+
+func decrypt(keyring *transformers.Keyring, envelope string, args ...string) (transformers.Results, error) {
+	document, _ := json.Marshal(map[string]string{"value": envelope})
+	return (&transformers.Decrypt{Config: transformers.Config{Args: append([]string{"value"}, args...), Json: document}, Keyring: keyring}).Transform()
+}
+
+func TestEncryptAndDecrypt(t *testing.T) {
+	keyring := newKeyring(t, "2026-01")
+
+	tests := []struct {
+		json     string
+		expected string
+	}{
+		{json: `{"value":"123-45-6789"}`, expected: `"123-45-6789"`},
+		{json: `{"value":42.5}`, expected: `42.5`},
+		{json: `{"value":true}`, expected: `true`},
+		{json: `{"value":null}`, expected: `null`},
+		{json: `{"value":{"street":"Main St","city":"Springfield"}}`, expected: `{"city":"Springfield","street":"Main St"}`},
+		{json: `{"value":["a", 1]}`, expected: `["a",1]`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expected, func(t *testing.T) {
+			envelope := encrypt(t, keyring, tt.json, "2026-01")
+			if !strings.HasPrefix(envelope, "enc:v1:2026-01:") {
+				t.Errorf("Expected an envelope naming key 2026-01, got %s", envelope)
+			}
+			results, err := decrypt(keyring, envelope)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if string(results[0].(json.RawMessage)) != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, results[0])
+			}
+		})
+	}
+}
+
+func TestEncryptUsesRandomNonces(t *testing.T) {
+	keyring := newKeyring(t, "2026-01")
+	first := encrypt(t, keyring, `{"value":"secret"}`, "2026-01")
+	second := encrypt(t, keyring, `{"value":"secret"}`, "2026-01")
+	if first == second {
+		t.Errorf("Expected different envelopes for the same value, got %s twice", first)
+	}
+}
+
+func TestDecryptAfterKeyRotation(t *testing.T) {
+	// Data encrypted before the rotation, with the only key of the keyring
+	old := encrypt(t, newKeyring(t, "2025-01"), `{"value":"123-45-6789"}`, "2025-01")
+
+	// After the rotation the keyring has both keys: new data uses the new key and old data still decrypts
+	rotated := newKeyring(t, "2025-01", "2026-01")
+	current := encrypt(t, rotated, `{"value":"123-45-6789"}`, "2026-01")
+	for _, envelope := range []string{old, current} {
+		results, err := decrypt(rotated, envelope)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if string(results[0].(json.RawMessage)) != `"123-45-6789"` {
+			t.Errorf("Expected %s, got %s", `"123-45-6789"`, results[0])
+		}
+	}
+
+	// Re-encrypting old data moves it to the new key
+	plain, err := decrypt(rotated, old)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	reencrypted := encrypt(t, rotated, `{"value":`+string(plain[0].(json.RawMessage))+`}`, "2026-01")
+
+	// Once the old key is retired, only the data it encrypted stops decrypting
+	retired := newKeyring(t, "2026-01")
+	if _, err := decrypt(retired, reencrypted); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	_, err = decrypt(retired, old)
+	if err == nil || err.Error() != "unknown key '2025-01'" {
+		t.Errorf("Expected unknown key '2025-01', got %v", err)
+	}
+}
+
+func TestDecryptWithInvalidEnvelopes(t *testing.T) {
+	keyring := newKeyring(t, "2025-01", "2026-01")
+	envelope := encrypt(t, keyring, `{"value":"secret"}`, "2025-01")
+	parts := strings.Split(envelope, ":")
+
+	tests := []struct {
+		name     string
+		envelope string
+		args     []string
+		expected string
+	}{
+		{name: "expected key", envelope: envelope, args: []string{"'2026-01'"}, expected: "argument 'value' is encrypted with key '2025-01', not '2026-01'"},
+		{name: "plain text", envelope: "secret", expected: "argument 'value' is not an encrypted value"},
+		{name: "missing part", envelope: strings.Join(parts[:4], ":"), expected: "argument 'value' is not an encrypted value"},
+		{name: "bad nonce", envelope: strings.Join([]string{parts[0], parts[1], parts[2], "AAAA", parts[4]}, ":"), expected: "argument 'value' is not an encrypted value"},
+		{name: "moved to another key", envelope: strings.Join([]string{parts[0], parts[1], "2026-01", parts[3], parts[4]}, ":"), expected: "cannot decrypt argument 'value' with key '2026-01': wrong key or altered value"},
+		{name: "altered ciphertext", envelope: envelope[:len(envelope)-2] + "AA", expected: "cannot decrypt argument 'value' with key '2025-01': wrong key or altered value"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := decrypt(keyring, tt.envelope, tt.args...)
+			if err == nil {
+				t.Fatalf("Expected error, but got nil")
+			}
+			if err.Error() != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, err)
+			}
+		})
+	}
+}
+
+func TestEncryptWithInvalidArguments(t *testing.T) {
+	config := transformers.Config{Args: []string{"value", "'2024-01'"}, Json: []byte(`{"value":"secret"}`)}
+	tests := []struct {
+		name     string
+		keyring  *transformers.Keyring
+		expected string
+	}{
+		{name: "unknown key", keyring: newKeyring(t, "2026-01"), expected: "unknown key '2024-01'"},
+		{name: "no keyring", keyring: nil, expected: "no keyring loaded"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := (&transformers.Encrypt{Config: config, Keyring: tt.keyring}).Transform()
+			if err == nil {
+				t.Fatalf("Expected error, but got nil")
+			}
+			if err.Error() != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, err)
+			}
+		})
+	}
+}
+
+func TestParseKeyring(t *testing.T) {
+	keyring, err := transformers.ParseKeyring(strings.NewReader(`{"2026-01":"ZmVkY2JhOTg3NjU0MzIxMGZlZGNiYTk4NzY1NDMyMTA="}`))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	// The parsed key is the same as the test key, so it decrypts what the test key encrypted
+	envelope := encrypt(t, newKeyring(t, "2026-01"), `{"value":"secret"}`, "2026-01")
+	if _, err := decrypt(keyring, envelope); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		keyring  string
+		expected string
+	}{
+		{name: "empty", keyring: `{}`, expected: "keyring has no keys"},
+		{name: "not base64", keyring: `{"k1":"not base64!"}`, expected: "key 'k1' is not valid base64: illegal base64 data at input byte 3"},
+		{name: "short key", keyring: `{"k1":"c2hvcnQ="}`, expected: "key 'k1' must be 16, 24 or 32 bytes long, got 5"},
+		{name: "colon in id", keyring: `{"k:1":"MDEyMzQ1Njc4OWFiY2RlZg=="}`, expected: "key id 'k:1' should have only letters, digits, '.', '-' and '_'"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := transformers.ParseKeyring(strings.NewReader(tt.keyring))
+			if err == nil {
+				t.Fatalf("Expected error, but got nil")
+			}
+			if err.Error() != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,164 @@
+package transformers
+
+import (
+	"cmp"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// lms holds the Box-Cox power (L), median (M) and coefficient of variation (S) of a measurement at an age
+type lms struct {
+	age     float64 // In months
+	l, m, s float64
+}
+
+// GrowthReference is a growth chart in the LMS format, such as the CDC BMI-for-age chart for ages 2 to 20
+// (bmiagerev.csv). The tables are published by the CDC and the WHO and are not bundled with the interpreter
+type GrowthReference struct {
+	rows [2][]lms // By sex: male, then female
+}
+
+// ParseGrowthReference reads a growth chart from CSV with a header naming the columns Sex (1 for male and 2
+// for female), Agemos (the age in months), L, M and S, as in the files published by the CDC. Other columns
+// are ignored, and the header may be repeated
+func ParseGrowthReference(r io.Reader) (*GrowthReference, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("cannot read growth reference header: %w", err)
+	}
+	columns := map[string]int{}
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	var indexes [5]int
+	for i, name := range []string{"sex", "agemos", "l", "m", "s"} {
+		index, ok := columns[name]
+		if !ok {
+			return nil, fmt.Errorf("growth reference has no '%s' column", name)
+		}
+		indexes[i] = index
+	}
+
+	reference := &GrowthReference{}
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("cannot read growth reference: %w", err)
+		}
+		if strings.EqualFold(strings.TrimSpace(record[0]), strings.TrimSpace(header[0])) {
+			continue // Repeated header
+		}
+		var values [5]float64
+		for i, index := range indexes {
+			if index >= len(record) {
+				return nil, fmt.Errorf("growth reference line %d has %d columns", line, len(record))
+			}
+			if values[i], err = strconv.ParseFloat(strings.TrimSpace(record[index]), 64); err != nil {
+				return nil, fmt.Errorf("growth reference line %d: '%s' is not a number", line, record[index])
+			}
+		}
+		if values[0] != 1 && values[0] != 2 {
+			return nil, fmt.Errorf("growth reference line %d: sex must be 1 or 2, got %g", line, values[0])
+		}
+		sex := int(values[0]) - 1
+		reference.rows[sex] = append(reference.rows[sex], lms{age: values[1], l: values[2], m: values[3], s: values[4]})
+	}
+
+	for sex := range reference.rows {
+		if len(reference.rows[sex]) == 0 {
+			return nil, fmt.Errorf("growth reference has no rows for sex %d", sex+1)
+		}
+		slices.SortFunc(reference.rows[sex], func(a, b lms) int { return cmp.Compare(a.age, b.age) })
+	}
+	return reference, nil
+}
+
+// at returns the LMS parameters at an age, interpolated linearly between the ages of the chart
+func (g *GrowthReference) at(age float64, female bool) (lms, error) {
+	rows := g.rows[0]
+	if female {
+		rows = g.rows[1]
+	}
+	first, last := rows[0], rows[len(rows)-1]
+	if age < first.age || age > last.age {
+		return lms{}, fmt.Errorf("age of %g months is outside the growth reference (%g to %g months)", age, first.age, last.age)
+	}
+
+	i, _ := slices.BinarySearchFunc(rows, age, func(row lms, age float64) int { return cmp.Compare(row.age, age) })
+	if i < len(rows) && rows[i].age == age {
+		return rows[i], nil
+	}
+	below, above := rows[i-1], rows[i]
+	f := (age - below.age) / (above.age - below.age)
+	return lms{
+		age: age,
+		l:   below.l + f*(above.l-below.l),
+		m:   below.m + f*(above.m-below.m),
+		s:   below.s + f*(above.s-below.s),
+	}, nil
+}
+
+// zscore returns the z-score of a measurement: ((x/M)^L - 1) / (L*S), or ln(x/M) / S when L is 0
+func (p lms) zscore(x float64) float64 {
+	if p.l == 0 {
+		return math.Log(x/p.m) / p.s
+	}
+	return (math.Pow(x/p.m, p.l) - 1) / (p.l * p.s)
+}
+
+// BMIPercentile places the BMI of a child on a growth chart
+type BMIPercentile struct {
+	Config
+	Reference *GrowthReference
+}
+
+// Transform returns the z-score of the BMI, rounded to two decimal places, and its percentile, rounded to
+// one. The age is in months unless 'years' is given
+func (t *BMIPercentile) Transform() (Results, error) {
+	if len(t.Args) < 3 || len(t.Args) > 4 {
+		return nil, fmt.Errorf("bmipercentile requires three or four arguments")
+	}
+	if t.Reference == nil {
+		return nil, fmt.Errorf("no growth reference loaded")
+	}
+	bmi, err := t.amount(0)
+	if err != nil {
+		return nil, err
+	}
+	age, err := t.amount(1)
+	if err != nil {
+		return nil, err
+	}
+	if len(t.Args) == 4 {
+		switch unit := t.Text(3); unit {
+		case "months":
+		case "years":
+			age *= 12
+		default:
+			return nil, fmt.Errorf("unknown age unit '%s' (expected months or years)", unit)
+		}
+	}
+	female, err := t.sex(2)
+	if err != nil {
+		return nil, err
+	}
+
+	params, err := t.Reference.at(age, female)
+	if err != nil {
+		return nil, err
+	}
+	z := params.zscore(bmi)
+	percentile := 50 * math.Erfc(-z/math.Sqrt2)
+	return Results{roundTo(z, 2), roundTo(percentile, 1)}, nil
+}
@@ -0,0 +1,105 @@
+package transformers_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/codeis4fun/data-treatment-interpreter/internal/transformers"
+)
+
+// syntheticChart is a made-up growth chart in the CDC layout, with round parameters that make the
+// expected z-scores easy to check by hand. It is not clinical data
+const syntheticChart = `Sex,Agemos,L,M,S,P50
+1,24,1,16,0.1,16
+1,36,1,17,0.1,17
+2,24,0,15,0.1,15
+2,36,0,16,0.1,16
+`
+
+func TestBMIPercentile(t *testing.T) {
+	reference, err := transformers.ParseGrowthReference(strings.NewReader(syntheticChart))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	json := []byte(`{"median":16,"high":18.7,"low":15,"months":30,"years":"2","boy":"male","girl":"female"}`)
+	tests := []struct {
+		name       string
+		args       []string
+		zscore     float64
+		percentile float64
+	}{
+		{name: "median at a chart age", args: []string{"median", "'24'", "boy"}, zscore: 0, percentile: 50},
+		{name: "interpolated age", args: []string{"high", "months", "boy"}, zscore: 1.33, percentile: 90.9},
+		{name: "age in years", args: []string{"low", "years", "girl", "'years'"}, zscore: 0, percentile: 50},
+		{name: "box-cox power of zero", args: []string{"median", "'24'", "girl"}, zscore: 0.65, percentile: 74.1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results, err := (&transformers.BMIPercentile{Config: jsonConfig(json, tt.args...), Reference: reference}).Transform()
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if results[0] != tt.zscore || results[1] != tt.percentile {
+				t.Errorf("Expected %v and %v, got %v and %v", tt.zscore, tt.percentile, results[0], results[1])
+			}
+		})
+	}
+}
+
+func TestBMIPercentileWithInvalidArguments(t *testing.T) {
+	reference, err := transformers.ParseGrowthReference(strings.NewReader(syntheticChart))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	json := []byte(`{"bmi":16,"age":240,"sex":"male"}`)
+
+	tests := []struct {
+		name      string
+		reference *transformers.GrowthReference
+		args      []string
+		expected  string
+	}{
+		{name: "no reference", args: []string{"bmi", "age", "sex"}, expected: "no growth reference loaded"},
+		{name: "age outside the chart", reference: reference, args: []string{"bmi", "age", "sex"}, expected: "age of 240 months is outside the growth reference (24 to 36 months)"},
+		{name: "unknown age unit", reference: reference, args: []string{"bmi", "age", "sex", "'weeks'"}, expected: "unknown age unit 'weeks' (expected months or years)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transformer := &transformers.BMIPercentile{Config: transformers.Config{Args: tt.args, Json: json}, Reference: tt.reference}
+			_, err := transformer.Transform()
+			if err == nil {
+				t.Fatalf("Expected error, but got nil")
+			}
+			if err.Error() != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, err)
+			}
+		})
+	}
+}
+
+func TestParseGrowthReferenceWithInvalidChart(t *testing.T) {
+	tests := []struct {
+		name     string
+		chart    string
+		expected string
+	}{
+		{name: "missing column", chart: "Sex,Agemos,L,M\n1,24,1,16\n", expected: "growth reference has no 's' column"},
+		{name: "not a number", chart: "Sex,Agemos,L,M,S\n1,24,1,sixteen,0.1\n", expected: "growth reference line 2: 'sixteen' is not a number"},
+		{name: "unknown sex", chart: "Sex,Agemos,L,M,S\n3,24,1,16,0.1\n", expected: "growth reference line 2: sex must be 1 or 2, got 3"},
+		{name: "one sex only", chart: "Sex,Agemos,L,M,S\n1,24,1,16,0.1\n", expected: "growth reference has no rows for sex 2"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := transformers.ParseGrowthReference(strings.NewReader(tt.chart))
+			if err == nil {
+				t.Fatalf("Expected error, but got nil")
+			}
+			if err.Error() != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, err)
+			}
+		})
+	}
+}
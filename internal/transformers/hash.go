@@ -0,0 +1,169 @@
+package transformers
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"hash"
+	"hash/crc32"
+	"slices"
+	"strings"
+
+	"github.com/tidwall/gjson"
+)
+
+// hashes are the hash functions of the hash transformers and hmac, by name. MD5 and SHA-1 are broken
+// for security purposes and are only meant for checksums and compatibility with existing identifiers
+var hashes = map[string]func() hash.Hash{
+	"md5":    md5.New,
+	"sha1":   sha1.New,
+	"sha256": sha256.New,
+	"sha512": sha512.New,
+}
+
+// content returns the bytes of the argument at index i that are hashed or encoded: the text of strings,
+// the canonical JSON of objects and arrays (see canonical) and the JSON text of other values
+func (c Config) content(i int) ([]byte, error) {
+	value := c.Value(i)
+	switch {
+	case !value.Exists():
+		return nil, fmt.Errorf("argument '%s' not found in JSON", c.Args[i])
+	case value.Type == gjson.String:
+		return []byte(value.Str), nil
+	case value.IsObject() || value.IsArray():
+		var buf bytes.Buffer
+		canonical(&buf, value)
+		return buf.Bytes(), nil
+	default:
+		return []byte(value.Raw), nil
+	}
+}
+
+// canonical writes a JSON value without whitespace and with the keys of its objects sorted, so equal
+// documents hash the same whatever their formatting and key order. Numbers are written as they are
+// (1.0 and 1 differ) and strings are escaped only where JSON requires it
+func canonical(buf *bytes.Buffer, value gjson.Result) {
+	switch {
+	case value.IsObject():
+		type member struct {
+			key   string
+			value gjson.Result
+		}
+		var members []member
+		value.ForEach(func(key, value gjson.Result) bool {
+			members = append(members, member{key: key.Str, value: value})
+			return true
+		})
+		slices.SortStableFunc(members, func(a, b member) int { return strings.Compare(a.key, b.key) })
+		buf.WriteByte('{')
+		for i, m := range members {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			canonicalString(buf, m.key)
+			buf.WriteByte(':')
+			canonical(buf, m.value)
+		}
+		buf.WriteByte('}')
+	case value.IsArray():
+		buf.WriteByte('[')
+		for i, element := range value.Array() {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			canonical(buf, element)
+		}
+		buf.WriteByte(']')
+	case value.Type == gjson.String:
+		canonicalString(buf, value.Str)
+	default:
+		buf.WriteString(value.Raw)
+	}
+}
+
+// canonicalString writes a JSON string without escaping HTML characters, as json.Marshal does
+func canonicalString(buf *bytes.Buffer, s string) {
+	encoder := json.NewEncoder(buf)
+	encoder.SetEscapeHTML(false)
+	encoder.Encode(s)
+	buf.Truncate(buf.Len() - 1) // Encode ends with a newline
+}
+
+// Hash returns the digest of a value
+type Hash struct {
+	Config
+	Algorithm string // md5, sha1, sha256 or sha512
+}
+
+// Transform returns the digest of the input as lowercase hexadecimal
+func (t *Hash) Transform() (Results, error) {
+	if len(t.Args) != 1 {
+		return nil, fmt.Errorf("%s requires exactly one argument", t.Algorithm)
+	}
+	newHash, ok := hashes[t.Algorithm]
+	if !ok {
+		return nil, fmt.Errorf("unknown hash algorithm '%s'", t.Algorithm)
+	}
+	content, err := t.content(0)
+	if err != nil {
+		return nil, err
+	}
+	h := newHash()
+	h.Write(content)
+	return Results{hex.EncodeToString(h.Sum(nil))}, nil
+}
+
+// HMAC returns the keyed digest of a value
+type HMAC struct {
+	Config
+}
+
+// Transform returns the HMAC of the input with the key, as lowercase hexadecimal. The hash function is
+// SHA-256 unless another one is given
+func (t *HMAC) Transform() (Results, error) {
+	if len(t.Args) < 2 || len(t.Args) > 3 {
+		return nil, fmt.Errorf("hmac requires two or three arguments")
+	}
+	algorithm := "sha256"
+	if len(t.Args) == 3 {
+		algorithm = strings.ToLower(t.Text(2))
+	}
+	newHash, ok := hashes[algorithm]
+	if !ok {
+		return nil, fmt.Errorf("unknown hash algorithm '%s' (expected md5, sha1, sha256 or sha512)", algorithm)
+	}
+	content, err := t.content(0)
+	if err != nil {
+		return nil, err
+	}
+	key := t.Text(1)
+	if key == "" {
+		return nil, fmt.Errorf("hmac key must not be empty")
+	}
+	mac := hmac.New(newHash, []byte(key))
+	mac.Write(content)
+	return Results{hex.EncodeToString(mac.Sum(nil))}, nil
+}
+
+// CRC32 returns the CRC-32 checksum of a value
+type CRC32 struct {
+	Config
+}
+
+// Transform returns the IEEE CRC-32 checksum of the input as eight lowercase hexadecimal digits
+func (t *CRC32) Transform() (Results, error) {
+	if len(t.Args) != 1 {
+		return nil, fmt.Errorf("crc32 requires exactly one argument")
+	}
+	content, err := t.content(0)
+	if err != nil {
+		return nil, err
+	}
+	return Results{fmt.Sprintf("%08x", crc32.ChecksumIEEE(content))}, nil
+}
@@ -0,0 +1,95 @@
+package transformers_test
+
+import (
+	"testing"
+
+	"github.com/codeis4fun/data-treatment-interpreter/internal/transformers"
+)
+
+func TestHashTransformers(t *testing.T) {
+	json := []byte(`{"email":"john.doe@example.com","id":12345,"address":{"zip":"1100","city":"Lisbon"},"shuffled":{ "city" : "Lisbon", "zip" : "1100" },"html":{"q":"a<b"},"text":"hello world"}`)
+	tests := []struct {
+		name        string
+		transformer transformer
+		expected    string
+	}{
+		{name: "md5", transformer: &transformers.Hash{Config: jsonConfig(json, "email"), Algorithm: "md5"}, expected: "8eb1b522f60d11fa897de1dc6351b7e8"},
+		{name: "sha1", transformer: &transformers.Hash{Config: jsonConfig(json, "email"), Algorithm: "sha1"}, expected: "73ec53c4ba1747d485ae2a0d7bfafa6cda80a5a9"},
+		{name: "sha256", transformer: &transformers.Hash{Config: jsonConfig(json, "email"), Algorithm: "sha256"}, expected: "836f82db99121b3481011f16b49dfa5fbc714a0d1b1b9f784a1ebbbf5b39577f"},
+		{name: "sha256 of canonical object", transformer: &transformers.Hash{Config: jsonConfig(json, "address"), Algorithm: "sha256"}, expected: "401b9af9af4ff4785f0c8366c7ef7a5517abf64d42eacdbb38924eb3bace43a4"},
+		{name: "sha256 ignores key order and spaces", transformer: &transformers.Hash{Config: jsonConfig(json, "shuffled"), Algorithm: "sha256"}, expected: "401b9af9af4ff4785f0c8366c7ef7a5517abf64d42eacdbb38924eb3bace43a4"},
+		{name: "hmac", transformer: &transformers.HMAC{Config: jsonConfig(json, "id", "'s3cret'")}, expected: "672b04fffe37d7340773aec34f538dce4d75a078cf87db130e54d794ed677d4a"},
+		{name: "crc32", transformer: &transformers.CRC32{Config: jsonConfig(json, "text")}, expected: "0d4a1185"},
+		{name: "hexencode keeps html characters", transformer: &transformers.HexEncode{Config: jsonConfig(json, "html")}, expected: "7b2271223a22613c62227d"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results, err := tt.transformer.Transform()
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if results[0] != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, results[0])
+			}
+		})
+	}
+}
+
+func TestEncodingTransformers(t *testing.T) {
+	json := []byte(`{"token":"john:s3cret?","encoded":"am9objpzM2NyZXQ_","padded":"am9objpzM2NyZXQ/","search":"fish & chips","query":"fish+%26+chips","list":[1,2]}`)
+	tests := []struct {
+		name        string
+		transformer transformer
+		expected    string
+	}{
+		{name: "base64encode", transformer: &transformers.Base64Encode{Config: jsonConfig(json, "token")}, expected: "am9objpzM2NyZXQ/"},
+		{name: "base64encode url", transformer: &transformers.Base64Encode{Config: jsonConfig(json, "token", "'url'")}, expected: "am9objpzM2NyZXQ_"},
+		{name: "base64encode array", transformer: &transformers.Base64Encode{Config: jsonConfig(json, "list")}, expected: "WzEsMl0="},
+		{name: "base64decode url", transformer: &transformers.Base64Decode{Config: jsonConfig(json, "encoded")}, expected: "john:s3cret?"},
+		{name: "base64decode standard", transformer: &transformers.Base64Decode{Config: jsonConfig(json, "padded")}, expected: "john:s3cret?"},
+		{name: "urlencode", transformer: &transformers.URLEncode{Config: jsonConfig(json, "search")}, expected: "fish+%26+chips"},
+		{name: "urlencode path", transformer: &transformers.URLEncode{Config: jsonConfig(json, "search", "'path'")}, expected: "fish%20&%20chips"},
+		{name: "urldecode", transformer: &transformers.URLDecode{Config: jsonConfig(json, "query")}, expected: "fish & chips"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results, err := tt.transformer.Transform()
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if results[0] != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, results[0])
+			}
+		})
+	}
+}
+
+func TestHashAndEncodingTransformersWithInvalidArguments(t *testing.T) {
+	json := []byte(`{"id":12345,"binary":"/w==","broken":"%zz"}`)
+	tests := []struct {
+		name        string
+		transformer transformer
+		expected    string
+	}{
+		{name: "missing field", transformer: &transformers.Hash{Config: jsonConfig(json, "email"), Algorithm: "sha256"}, expected: "argument 'email' not found in JSON"},
+		{name: "unknown hmac algorithm", transformer: &transformers.HMAC{Config: jsonConfig(json, "id", "'key'", "'sha3'")}, expected: "unknown hash algorithm 'sha3' (expected md5, sha1, sha256 or sha512)"},
+		{name: "empty hmac key", transformer: &transformers.HMAC{Config: jsonConfig(json, "id", "''")}, expected: "hmac key must not be empty"},
+		{name: "binary base64", transformer: &transformers.Base64Decode{Config: jsonConfig(json, "binary")}, expected: "argument 'binary' does not decode to UTF-8 text"},
+		{name: "unknown base64 variant", transformer: &transformers.Base64Encode{Config: jsonConfig(json, "id", "'mime'")}, expected: "unknown base64 variant 'mime' (expected std or url)"},
+		{name: "invalid escape", transformer: &transformers.URLDecode{Config: jsonConfig(json, "broken")}, expected: `argument 'broken' is not a valid URL-encoded value: invalid URL escape "%zz"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.transformer.Transform()
+			if err == nil {
+				t.Fatalf("Expected error, but got nil")
+			}
+			if err.Error() != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, err)
+			}
+		})
+	}
+}
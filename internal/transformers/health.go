@@ -3,28 +3,43 @@ package transformers
 import (
 	"fmt"
 	"math"
+	"math/big"
+	"strings"
 
 	"github.com/tidwall/gjson"
 )
 
+// BMI categories, from the lowest to the highest
+const (
+	Underweight = "underweight"
+	Normal      = "normal"
+	Overweight  = "overweight"
+	Obese       = "obese"
+)
+
+// defaultCutoffs are the lowest BMIs of the normal, overweight and obese categories set by the WHO
+const defaultCutoffs = "18.5,25,30"
+
 type BMI struct {
 	Config
+	Category bool // Also return the category of the BMI
 }
 
-// Transform calculates the Body Mass Index (BMI) based on the weight and height
-// Transform calculates BMI and checks if it's in a healthy range
+// Transform calculates the Body Mass Index (BMI) from the weight and height, in kilograms and metres
+// unless other units are given, rounded to one decimal place unless other digits are given. It also
+// returns whether the BMI is in the normal range and, for bmicategory, its category
 func (t *BMI) Transform() (Results, error) {
-	// BMI requires two arguments: weight and height
-	if len(t.Config.Args) != 2 {
-		return nil, fmt.Errorf("bmi requires exactly two arguments")
+	most := 5
+	if t.Category {
+		most = 6
+	}
+	if len(t.Args) < 2 || len(t.Args) > most {
+		return nil, fmt.Errorf("bmi requires between two and %d arguments", most)
 	}
-
-	weightArg := t.Config.Args[0]
-	heightArg := t.Config.Args[1]
 
 	// Get weight and height from the JSON
-	weightVar := gjson.GetBytes(t.Config.Json, weightArg)
-	heightVar := gjson.GetBytes(t.Config.Json, heightArg)
+	weightVar := t.Field(0)
+	heightVar := t.Field(1)
 
 	if !weightVar.Exists() || !heightVar.Exists() {
 		return nil, fmt.Errorf("weight or height not found in JSON")
@@ -35,16 +50,317 @@ func (t *BMI) Transform() (Results, error) {
 		return nil, fmt.Errorf("weight and height must be numbers")
 	}
 
-	// Parse weight and height as float64
-	weight := weightVar.Float()
-	height := heightVar.Float()
+	weight, err := t.measure(0, 2, "kg")
+	if err != nil {
+		return nil, err
+	}
+	height, err := t.measure(1, 3, "m")
+	if err != nil {
+		return nil, err
+	}
+	if height.Sign() <= 0 {
+		return nil, fmt.Errorf("height must be greater than 0, got %s", heightVar.Raw)
+	}
+	digits, err := t.digits(4, 1)
+	if err != nil {
+		return nil, err
+	}
+	cutoffs := defaultCutoffs
+	if len(t.Args) == 6 {
+		cutoffs = t.Text(5)
+	}
+	bounds, err := parseCutoffs(cutoffs)
+	if err != nil {
+		return nil, err
+	}
+
+	// Calculate BMI with the formula: weight (kg) / height (m)^2, and classify it once rounded
+	bmi := new(big.Rat).Quo(weight, new(big.Rat).Mul(height, height))
+	if bmi, err = round(bmi, digits, HalfUp); err != nil {
+		return nil, err
+	}
+	category := classify(bmi, bounds)
+	value, _ := bmi.Float64()
+
+	if t.Category {
+		return Results{value, category == Normal, category}, nil
+	}
+	return Results{value, category == Normal}, nil
+}
+
+// measure reads the number at index i in a base unit (e.g. kilograms), converting it from the unit at
+// index unit when the script gives one
+func (c Config) measure(i, unit int, base string) (*big.Rat, error) {
+	n, err := c.number(i)
+	if err != nil {
+		return nil, err
+	}
+	if unit >= len(c.Args) {
+		return n, nil
+	}
+	return convertUnit(n, c.Text(unit), base)
+}
+
+// parseCutoffs parses the lowest BMIs of the normal, overweight and obese categories, such as "18.5,23,27.5"
+func parseCutoffs(text string) ([3]*big.Rat, error) {
+	var bounds [3]*big.Rat
+	parts := strings.Split(text, ",")
+	if len(parts) != len(bounds) {
+		return bounds, fmt.Errorf("cutoffs must be three increasing numbers (normal, overweight, obese), got '%s'", text)
+	}
+	for i, part := range parts {
+		bound, err := parseDecimal(part)
+		if err != nil || (i > 0 && bound.Cmp(bounds[i-1]) <= 0) {
+			return bounds, fmt.Errorf("cutoffs must be three increasing numbers (normal, overweight, obese), got '%s'", text)
+		}
+		bounds[i] = bound
+	}
+	return bounds, nil
+}
+
+// classify returns the category of a BMI given the lowest BMIs of the normal, overweight and obese categories
+func classify(bmi *big.Rat, bounds [3]*big.Rat) string {
+	switch {
+	case bmi.Cmp(bounds[0]) < 0:
+		return Underweight
+	case bmi.Cmp(bounds[1]) < 0:
+		return Normal
+	case bmi.Cmp(bounds[2]) < 0:
+		return Overweight
+	default:
+		return Obese
+	}
+}
+
+// quantity reads the number at index i like measure, as a float for formulas with powers and roots.
+// Quantities that are not positive are rejected, since no formula below is defined for them
+func (c Config) quantity(i, unit int, base string) (float64, error) {
+	n, err := c.measure(i, unit, base)
+	if err != nil {
+		return 0, err
+	}
+	return c.positive(i, n)
+}
+
+// amount reads the number at index i, which has no unit to convert (e.g. an age in years), like quantity
+func (c Config) amount(i int) (float64, error) {
+	n, err := c.number(i)
+	if err != nil {
+		return 0, err
+	}
+	return c.positive(i, n)
+}
+
+// positive returns the number read from the argument at index i as a float, failing when it is not positive
+func (c Config) positive(i int, n *big.Rat) (float64, error) {
+	if n.Sign() <= 0 {
+		return 0, fmt.Errorf("%s must be greater than 0, got %s", c.Args[i], c.Value(i).Raw)
+	}
+	f, _ := n.Float64()
+	return f, nil
+}
+
+// sex reads the sex at index i, male or female (m or f, in any case), and reports whether it is female
+func (c Config) sex(i int) (bool, error) {
+	value := c.Value(i)
+	if !value.Exists() {
+		return false, fmt.Errorf("argument '%s' not found in JSON", c.Args[i])
+	}
+	switch strings.ToLower(strings.TrimSpace(value.String())) {
+	case "male", "m":
+		return false, nil
+	case "female", "f":
+		return true, nil
+	}
+	return false, fmt.Errorf("sex must be male or female, got %s", value.Raw)
+}
+
+// roundTo rounds a float half away from zero to a number of decimal places
+func roundTo(x float64, digits int) float64 {
+	scale := math.Pow(10, float64(digits))
+	return math.Round(x*scale) / scale
+}
 
-	// Calculate BMI with the formula: weight (kg) / height (m)^2 with height in cm and weight in kg with 2 decimal places
-	bmi := math.Round((weight/(height*height))*10) / 10
+// BSA calculates the body surface area
+type BSA struct {
+	Config
+}
 
-	// Determine if the BMI is healthy (for simplicity, we'll assume healthy BMI is between 18.5 and 24.9)
-	isHealthy := bmi >= 18.5 && bmi <= 24.9
+// Transform returns the body surface area in square metres, rounded to two decimal places, with the
+// Mosteller and DuBois formulas. The weight and height are in kilograms and centimetres unless other
+// units are given
+func (t *BSA) Transform() (Results, error) {
+	if len(t.Args) < 2 || len(t.Args) > 4 {
+		return nil, fmt.Errorf("bsa requires between two and four arguments")
+	}
+	weight, err := t.quantity(0, 2, "kg")
+	if err != nil {
+		return nil, err
+	}
+	height, err := t.quantity(1, 3, "cm")
+	if err != nil {
+		return nil, err
+	}
 
-	// Return both BMI and whether it's healthy
-	return Results{bmi, isHealthy}, nil
+	mosteller := math.Sqrt(weight * height / 3600)
+	dubois := 0.007184 * math.Pow(weight, 0.425) * math.Pow(height, 0.725)
+	return Results{roundTo(mosteller, 2), roundTo(dubois, 2)}, nil
+}
+
+// BMR calculates the basal metabolic rate
+type BMR struct {
+	Config
+}
+
+// Transform returns the basal metabolic rate in kcal per day, rounded to units, with the Mifflin-St Jeor
+// and the revised Harris-Benedict (Roza and Shizgal) equations. The weight and height are in kilograms
+// and centimetres unless other units are given, and the age is in years
+func (t *BMR) Transform() (Results, error) {
+	if len(t.Args) < 4 || len(t.Args) > 6 {
+		return nil, fmt.Errorf("bmr requires between four and six arguments")
+	}
+	weight, err := t.quantity(0, 4, "kg")
+	if err != nil {
+		return nil, err
+	}
+	height, err := t.quantity(1, 5, "cm")
+	if err != nil {
+		return nil, err
+	}
+	age, err := t.amount(2)
+	if err != nil {
+		return nil, err
+	}
+	female, err := t.sex(3)
+	if err != nil {
+		return nil, err
+	}
+
+	mifflin := 10*weight + 6.25*height - 5*age + 5
+	harrisBenedict := 88.362 + 13.397*weight + 4.799*height - 5.677*age
+	if female {
+		mifflin = 10*weight + 6.25*height - 5*age - 161
+		harrisBenedict = 447.593 + 9.247*weight + 3.098*height - 4.330*age
+	}
+	return Results{roundTo(mifflin, 0), roundTo(harrisBenedict, 0)}, nil
+}
+
+// IdealWeight calculates the ideal body weight
+type IdealWeight struct {
+	Config
+}
+
+// Transform returns the ideal body weight in kilograms, rounded to one decimal place, with the Devine and
+// Robinson formulas. The height is in centimetres unless another unit is given; the formulas are meant for
+// adults of at least 5 feet (152.4 cm)
+func (t *IdealWeight) Transform() (Results, error) {
+	if len(t.Args) < 2 || len(t.Args) > 3 {
+		return nil, fmt.Errorf("ibw requires two or three arguments")
+	}
+	height, err := t.quantity(0, 2, "cm")
+	if err != nil {
+		return nil, err
+	}
+	female, err := t.sex(1)
+	if err != nil {
+		return nil, err
+	}
+
+	inches := height/2.54 - 60
+	devine, robinson := 50+2.3*inches, 52+1.9*inches
+	if female {
+		devine, robinson = 45.5+2.3*inches, 49+1.7*inches
+	}
+	return Results{roundTo(devine, 1), roundTo(robinson, 1)}, nil
+}
+
+// defaultWaistRisk is the waist-to-height ratio from which health risks increase
+const defaultWaistRisk = 0.5
+
+// WaistToHeight calculates the waist-to-height ratio
+type WaistToHeight struct {
+	Config
+}
+
+// Transform returns the waist-to-height ratio, rounded to two decimal places, and whether it is 0.5 or
+// more. The waist and height are both in centimetres unless other units are given
+func (t *WaistToHeight) Transform() (Results, error) {
+	if len(t.Args) < 2 || len(t.Args) > 4 {
+		return nil, fmt.Errorf("whtr requires between two and four arguments")
+	}
+	waist, err := t.quantity(0, 2, "cm")
+	if err != nil {
+		return nil, err
+	}
+	height, err := t.quantity(1, 3, "cm")
+	if err != nil {
+		return nil, err
+	}
+
+	ratio := roundTo(waist/height, 2)
+	return Results{ratio, ratio >= defaultWaistRisk}, nil
+}
+
+// creatinineMicromoles is the number of µmol/L in 1 mg/dL of creatinine
+const creatinineMicromoles = 88.42
+
+// EGFR estimates the glomerular filtration rate
+type EGFR struct {
+	Config
+}
+
+// Transform returns the estimated glomerular filtration rate in mL/min/1.73m², rounded to units, with the
+// race-free CKD-EPI 2021 creatinine equation, and its KDIGO category (G1 to G5). The serum creatinine is
+// in mg/dL unless 'umol/l' is given, and the age is in years
+func (t *EGFR) Transform() (Results, error) {
+	if len(t.Args) < 3 || len(t.Args) > 4 {
+		return nil, fmt.Errorf("egfr requires three or four arguments")
+	}
+	creatinine, err := t.amount(0)
+	if err != nil {
+		return nil, err
+	}
+	if len(t.Args) == 4 {
+		switch unit := t.Text(3); strings.ToLower(unit) {
+		case "mg/dl":
+		case "umol/l", "µmol/l":
+			creatinine /= creatinineMicromoles
+		default:
+			return nil, fmt.Errorf("unknown creatinine unit '%s' (expected mg/dl or umol/l)", unit)
+		}
+	}
+	age, err := t.amount(1)
+	if err != nil {
+		return nil, err
+	}
+	female, err := t.sex(2)
+	if err != nil {
+		return nil, err
+	}
+
+	kappa, alpha, factor := 0.9, -0.302, 1.0
+	if female {
+		kappa, alpha, factor = 0.7, -0.241, 1.012
+	}
+	ratio := creatinine / kappa
+	egfr := roundTo(142*math.Pow(math.Min(ratio, 1), alpha)*math.Pow(math.Max(ratio, 1), -1.2)*math.Pow(0.9938, age)*factor, 0)
+	return Results{egfr, kidneyStage(egfr)}, nil
+}
+
+// kidneyStage returns the KDIGO category of a glomerular filtration rate
+func kidneyStage(egfr float64) string {
+	switch {
+	case egfr >= 90:
+		return "G1"
+	case egfr >= 60:
+		return "G2"
+	case egfr >= 45:
+		return "G3a"
+	case egfr >= 30:
+		return "G3b"
+	case egfr >= 15:
+		return "G4"
+	default:
+		return "G5"
+	}
 }
@@ -37,3 +37,124 @@ func TestBMITransformWithNonExistentField(t *testing.T) {
 		t.Fatalf("Expected error, but got nil")
 	}
 }
+
+func TestBMIWithUnitsAndCategory(t *testing.T) {
+	json := []byte(`{"pounds":165,"inches":69,"kilos":50,"centimetres":180,"heavy":110,"border":80.838,"metres":1.8,"zero":0}`)
+	tests := []struct {
+		name        string
+		transformer transformer
+		expected    transformers.Results
+	}{
+		{name: "imperial units", transformer: &transformers.BMI{Config: jsonConfig(json, "pounds", "inches", "'lb'", "'in'")}, expected: transformers.Results{24.4, true}},
+		{name: "two digits", transformer: &transformers.BMI{Config: jsonConfig(json, "pounds", "inches", "'lb'", "'in'", "'2'")}, expected: transformers.Results{24.37, true}},
+		{name: "healthy under 25", transformer: &transformers.BMI{Config: jsonConfig(json, "border", "metres", "'kg'", "'m'", "'2'"), Category: true}, expected: transformers.Results{24.95, true, "normal"}},
+		{name: "underweight", transformer: &transformers.BMI{Config: jsonConfig(json, "kilos", "centimetres", "'kg'", "'cm'"), Category: true}, expected: transformers.Results{15.4, false, "underweight"}},
+		{name: "obese", transformer: &transformers.BMI{Config: jsonConfig(json, "heavy", "centimetres", "'kg'", "'cm'"), Category: true}, expected: transformers.Results{34.0, false, "obese"}},
+		{name: "custom cutoffs", transformer: &transformers.BMI{Config: jsonConfig(json, "pounds", "inches", "'lb'", "'in'", "'1'", "'18.5,23,27.5'"), Category: true}, expected: transformers.Results{24.4, false, "overweight"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results, err := tt.transformer.Transform()
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if len(results) != len(tt.expected) {
+				t.Fatalf("Expected %d results, got %d", len(tt.expected), len(results))
+			}
+			for i := range results {
+				if results[i] != tt.expected[i] {
+					t.Errorf("Expected %v, got %v", tt.expected[i], results[i])
+				}
+			}
+		})
+	}
+}
+
+func TestBMIWithInvalidArguments(t *testing.T) {
+	json := []byte(`{"weight":75,"height":1.75,"zero":0}`)
+	tests := []struct {
+		name        string
+		transformer transformer
+		expected    string
+	}{
+		{name: "zero height", transformer: &transformers.BMI{Config: jsonConfig(json, "weight", "zero")}, expected: "height must be greater than 0, got 0"},
+		{name: "length as weight", transformer: &transformers.BMI{Config: jsonConfig(json, "weight", "height", "'cm'")}, expected: "cannot convert cm (length) to kg (mass)"},
+		{name: "unknown unit", transformer: &transformers.BMI{Config: jsonConfig(json, "weight", "height", "'kg'", "'cubit'")}, expected: "unknown unit 'cubit'"},
+		{name: "invalid cutoffs", transformer: &transformers.BMI{Config: jsonConfig(json, "weight", "height", "'kg'", "'m'", "'1'", "'low,23,27.5'"), Category: true}, expected: "cutoffs must be three increasing numbers (normal, overweight, obese), got 'low,23,27.5'"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.transformer.Transform()
+			if err == nil {
+				t.Fatalf("Expected error, but got nil")
+			}
+			if err.Error() != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, err)
+			}
+		})
+	}
+}
+
+func TestClinicalCalculators(t *testing.T) {
+	json := []byte(`{"weight":70,"height":175,"pounds":154,"inches":69,"age":40,"older":60,"male":"male","female":"F","waist":90,"creatinine":1.1,"micromoles":97}`)
+	tests := []struct {
+		name        string
+		transformer transformer
+		expected    transformers.Results
+	}{
+		{name: "bsa", transformer: &transformers.BSA{Config: jsonConfig(json, "weight", "height")}, expected: transformers.Results{1.84, 1.85}},
+		{name: "bsa imperial", transformer: &transformers.BSA{Config: jsonConfig(json, "pounds", "inches", "'lb'", "'in'")}, expected: transformers.Results{1.84, 1.85}},
+		{name: "bmr male", transformer: &transformers.BMR{Config: jsonConfig(json, "weight", "height", "age", "male")}, expected: transformers.Results{1599.0, 1639.0}},
+		{name: "bmr female", transformer: &transformers.BMR{Config: jsonConfig(json, "weight", "height", "age", "female")}, expected: transformers.Results{1433.0, 1464.0}},
+		{name: "ibw male", transformer: &transformers.IdealWeight{Config: jsonConfig(json, "height", "male")}, expected: transformers.Results{70.5, 68.9}},
+		{name: "ibw female inches", transformer: &transformers.IdealWeight{Config: jsonConfig(json, "inches", "female", "'in'")}, expected: transformers.Results{66.2, 64.3}},
+		{name: "whtr", transformer: &transformers.WaistToHeight{Config: jsonConfig(json, "waist", "height")}, expected: transformers.Results{0.51, true}},
+		{name: "egfr male", transformer: &transformers.EGFR{Config: jsonConfig(json, "creatinine", "older", "male")}, expected: transformers.Results{77.0, "G2"}},
+		{name: "egfr female micromoles", transformer: &transformers.EGFR{Config: jsonConfig(json, "micromoles", "older", "female", "'umol/l'")}, expected: transformers.Results{58.0, "G3a"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results, err := tt.transformer.Transform()
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if len(results) != len(tt.expected) {
+				t.Fatalf("Expected %d results, got %d", len(tt.expected), len(results))
+			}
+			for i := range results {
+				if results[i] != tt.expected[i] {
+					t.Errorf("Expected %v, got %v", tt.expected[i], results[i])
+				}
+			}
+		})
+	}
+}
+
+func TestClinicalCalculatorsWithInvalidArguments(t *testing.T) {
+	json := []byte(`{"weight":70,"height":175,"age":40,"sex":"other","zero":0}`)
+	tests := []struct {
+		name        string
+		transformer transformer
+		expected    string
+	}{
+		{name: "unknown sex", transformer: &transformers.BMR{Config: jsonConfig(json, "weight", "height", "age", "sex")}, expected: `sex must be male or female, got "other"`},
+		{name: "zero weight", transformer: &transformers.BSA{Config: jsonConfig(json, "zero", "height")}, expected: "zero must be greater than 0, got 0"},
+		{name: "missing sex", transformer: &transformers.IdealWeight{Config: jsonConfig(json, "height", "gender")}, expected: "argument 'gender' not found in JSON"},
+		{name: "unknown creatinine unit", transformer: &transformers.EGFR{Config: jsonConfig(json, "weight", "age", "'male'", "'mmol/l'")}, expected: "unknown creatinine unit 'mmol/l' (expected mg/dl or umol/l)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.transformer.Transform()
+			if err == nil {
+				t.Fatalf("Expected error, but got nil")
+			}
+			if err.Error() != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,17 @@
+package transformers_test
+
+import (
+	"time"
+
+	"github.com/codeis4fun/data-treatment-interpreter/internal/transformers"
+)
+
+// testClock is the clock of the transformers under test, fixed so results do not depend on the day the tests run
+func testClock() time.Time {
+	return time.Date(2024, 2, 28, 12, 0, 0, 0, time.UTC)
+}
+
+// jsonConfig returns the configuration of a transformer reading a JSON document
+func jsonConfig(json []byte, args ...string) transformers.Config {
+	return transformers.Config{Args: args, Json: json, Now: testClock}
+}
@@ -0,0 +1,375 @@
+package transformers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/tidwall/gjson"
+)
+
+// divisionDigits is the number of decimal places kept by divisions whose result has no finite decimal
+// representation (e.g. 1/3), unless the script asks for another precision
+const divisionDigits = 10
+
+// maxExponent bounds the exponent of the numbers read as exact decimals
+const maxExponent = 1000
+
+// maxDigits bounds the decimal places of a rounding, which are otherwise only limited by memory
+const maxDigits = 100
+
+// RoundingMode decides how a number is rounded to a number of decimal places
+type RoundingMode string
+
+const (
+	HalfUp   RoundingMode = "half-up"   // Halves are rounded away from zero (2.5 -> 3, -2.5 -> -3)
+	HalfEven RoundingMode = "half-even" // Halves are rounded to the even neighbour (2.5 -> 2, 3.5 -> 4), as banks do
+	Floor    RoundingMode = "floor"     // Towards negative infinity
+	Ceil     RoundingMode = "ceil"      // Towards positive infinity
+	Truncate RoundingMode = "truncate"  // Towards zero
+)
+
+// errNotNumber and errOutOfRange are returned by parseDecimal
+var (
+	errNotNumber  = errors.New("not a number")
+	errOutOfRange = errors.New("out of range")
+)
+
+// number reads the argument at index i as an exact decimal. JSON numbers and strings holding a number
+// (e.g. "12.50") are accepted; anything else is an error
+func (c Config) number(i int) (*big.Rat, error) {
+	value := c.Value(i)
+	if !value.Exists() {
+		return nil, fmt.Errorf("argument '%s' not found in JSON", c.Args[i])
+	}
+
+	text := value.Raw
+	if value.Type == gjson.String {
+		text = value.Str
+	}
+	if value.Type != gjson.Number && value.Type != gjson.String {
+		return nil, fmt.Errorf("argument '%s' is not a number: %s", c.Args[i], value.Raw)
+	}
+	r, err := parseDecimal(text)
+	if err != nil {
+		return nil, fmt.Errorf("argument '%s' is %s: %s", c.Args[i], err, value.Raw)
+	}
+	return r, nil
+}
+
+// ParseDecimal parses the text of a literal number like the transformers reading numbers, so scripts can be
+// checked before they run
+func ParseDecimal(text string) (*big.Rat, error) {
+	return parseDecimal(text)
+}
+
+// parseDecimal parses the text of a JSON number, surrounded or not by spaces, as an exact decimal
+func parseDecimal(text string) (*big.Rat, error) {
+	text = strings.TrimSpace(text)
+	// Only JSON numbers are accepted, so fractions such as 1/3 and special values such as NaN are rejected
+	if parsed := gjson.Parse(text); parsed.Type != gjson.Number || parsed.Raw != text {
+		return nil, errNotNumber
+	}
+	// Exact decimals hold every digit, so exponents such as 1e1000000000 would exhaust memory
+	if _, exponent, ok := strings.Cut(strings.ToLower(text), "e"); ok {
+		if n, err := strconv.Atoi(exponent); err != nil || n > maxExponent || n < -maxExponent {
+			return nil, errOutOfRange
+		}
+	}
+	r, ok := new(big.Rat).SetString(text)
+	if !ok {
+		return nil, errNotNumber
+	}
+	return r, nil
+}
+
+// numbers reads every argument from index i on as an exact decimal
+func (c Config) numbers(from int) ([]*big.Rat, error) {
+	var numbers []*big.Rat
+	for i := from; i < len(c.Args); i++ {
+		n, err := c.number(i)
+		if err != nil {
+			return nil, err
+		}
+		numbers = append(numbers, n)
+	}
+	return numbers, nil
+}
+
+// digits reads the optional number of decimal places at index i
+func (c Config) digits(i int, fallback int) (int, error) {
+	if i >= len(c.Args) {
+		return fallback, nil
+	}
+	digits, err := c.integer(i, "digits")
+	if err != nil {
+		return 0, err
+	}
+	if digits < -maxDigits || digits > maxDigits {
+		return 0, fmt.Errorf("digits must be between %d and %d, got %d", -maxDigits, maxDigits, digits)
+	}
+	return digits, nil
+}
+
+// round rounds a number to a number of decimal places, which may be negative to round to tens, hundreds, ...
+func round(r *big.Rat, digits int, mode RoundingMode) (*big.Rat, error) {
+	scale := new(big.Rat).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(abs(digits))), nil))
+	if digits < 0 {
+		scale.Inv(scale)
+	}
+	scaled := new(big.Rat).Mul(r, scale)
+
+	// Truncate towards zero, then move away from zero according to the mode and the remainder
+	quotient, remainder := new(big.Int).QuoRem(scaled.Num(), scaled.Denom(), new(big.Int))
+	if remainder.Sign() != 0 {
+		sign := scaled.Sign()
+		twice := new(big.Int).Mul(new(big.Int).Abs(remainder), big.NewInt(2))
+		half := twice.Cmp(scaled.Denom())
+		away := false
+		switch mode {
+		case HalfUp:
+			away = half >= 0
+		case HalfEven:
+			away = half > 0 || (half == 0 && quotient.Bit(0) == 1)
+		case Floor:
+			away = sign < 0
+		case Ceil:
+			away = sign > 0
+		case Truncate:
+		default:
+			return nil, fmt.Errorf("unknown rounding mode '%s' (expected half-up, half-even, floor, ceil or truncate)", mode)
+		}
+		if away {
+			quotient.Add(quotient, big.NewInt(int64(sign)))
+		}
+	}
+	return new(big.Rat).Quo(new(big.Rat).SetInt(quotient), scale), nil
+}
+
+// decimal formats an exact number as a JSON number, without trailing zeros. Numbers with no finite
+// decimal representation are rounded half-up to divisionDigits decimal places
+func decimal(r *big.Rat) json.Number {
+	digits, exact := decimalPlaces(r)
+	if !exact {
+		digits = divisionDigits
+	}
+	text := r.FloatString(digits)
+	if strings.Contains(text, ".") {
+		text = strings.TrimRight(strings.TrimRight(text, "0"), ".")
+	}
+	if text == "-0" {
+		text = "0"
+	}
+	return json.Number(text)
+}
+
+// decimalPlaces returns the number of decimal places needed to write a number exactly, and whether it
+// can be written exactly at all: only when its denominator has no prime factors other than 2 and 5
+func decimalPlaces(r *big.Rat) (int, bool) {
+	denominator := new(big.Int).Set(r.Denom())
+	places := 0
+	for _, factor := range []int64{2, 5} {
+		count := 0
+		f := big.NewInt(factor)
+		for m := new(big.Int); ; count++ {
+			q, rem := new(big.Int).QuoRem(denominator, f, m)
+			if rem.Sign() != 0 {
+				break
+			}
+			denominator = q
+		}
+		places = max(places, count)
+	}
+	return places, denominator.Cmp(big.NewInt(1)) == 0
+}
+
+// abs returns the absolute value of an integer
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+// Round rounds a number to a number of decimal places
+type Round struct {
+	Config
+	Mode RoundingMode // Used when the script gives no mode
+}
+
+// Transform rounds the input to the given decimal places (0 by default) with the given rounding mode
+func (t *Round) Transform() (Results, error) {
+	if len(t.Args) < 1 || len(t.Args) > 3 {
+		return nil, fmt.Errorf("round requires between one and three arguments")
+	}
+	n, err := t.number(0)
+	if err != nil {
+		return nil, err
+	}
+	digits, err := t.digits(1, 0)
+	if err != nil {
+		return nil, err
+	}
+	mode := t.Mode
+	if len(t.Args) == 3 {
+		mode = RoundingMode(t.Text(2))
+	}
+	rounded, err := round(n, digits, mode)
+	if err != nil {
+		return nil, err
+	}
+	return Results{decimal(rounded)}, nil
+}
+
+// Abs returns the absolute value of a number
+type Abs struct {
+	Config
+}
+
+// Transform returns the input without its sign
+func (t *Abs) Transform() (Results, error) {
+	if len(t.Args) != 1 {
+		return nil, fmt.Errorf("abs requires exactly one argument")
+	}
+	n, err := t.number(0)
+	if err != nil {
+		return nil, err
+	}
+	return Results{decimal(n.Abs(n))}, nil
+}
+
+// Extreme returns the smallest or the largest of its arguments
+type Extreme struct {
+	Config
+	Max bool // Return the largest instead of the smallest
+}
+
+// Transform compares every argument as a number
+func (t *Extreme) Transform() (Results, error) {
+	if len(t.Args) < 1 {
+		return nil, fmt.Errorf("min and max require at least one argument")
+	}
+	numbers, err := t.numbers(0)
+	if err != nil {
+		return nil, err
+	}
+	extreme := numbers[0]
+	for _, n := range numbers[1:] {
+		if cmp := n.Cmp(extreme); (t.Max && cmp > 0) || (!t.Max && cmp < 0) {
+			extreme = n
+		}
+	}
+	return Results{decimal(extreme)}, nil
+}
+
+// Clamp restricts a number to a range
+type Clamp struct {
+	Config
+}
+
+// Transform returns the lower bound for smaller inputs, the upper bound for larger ones and the input otherwise
+func (t *Clamp) Transform() (Results, error) {
+	if len(t.Args) != 3 {
+		return nil, fmt.Errorf("clamp requires exactly three arguments")
+	}
+	numbers, err := t.numbers(0)
+	if err != nil {
+		return nil, err
+	}
+	n, lower, upper := numbers[0], numbers[1], numbers[2]
+	if lower.Cmp(upper) > 0 {
+		return nil, fmt.Errorf("lower bound %s is greater than upper bound %s", decimal(lower), decimal(upper))
+	}
+	switch {
+	case n.Cmp(lower) < 0:
+		n = lower
+	case n.Cmp(upper) > 0:
+		n = upper
+	}
+	return Results{decimal(n)}, nil
+}
+
+// Percent returns the percentage a part is of a total
+type Percent struct {
+	Config
+}
+
+// Transform returns part / total * 100, rounded half-up to the given decimal places (2 by default)
+func (t *Percent) Transform() (Results, error) {
+	if len(t.Args) < 2 || len(t.Args) > 3 {
+		return nil, fmt.Errorf("percent requires two or three arguments")
+	}
+	part, err := t.number(0)
+	if err != nil {
+		return nil, err
+	}
+	total, err := t.number(1)
+	if err != nil {
+		return nil, err
+	}
+	if total.Sign() == 0 {
+		return nil, fmt.Errorf("division by zero: '%s' is 0", t.Args[1])
+	}
+	digits, err := t.digits(2, 2)
+	if err != nil {
+		return nil, err
+	}
+
+	ratio := new(big.Rat).Quo(part, total)
+	rounded, err := round(ratio.Mul(ratio, big.NewRat(100, 1)), digits, HalfUp)
+	if err != nil {
+		return nil, err
+	}
+	return Results{decimal(rounded)}, nil
+}
+
+// Operator is an exact arithmetic operation applied from left to right
+type Operator string
+
+const (
+	Add      Operator = "add"
+	Subtract Operator = "subtract"
+	Multiply Operator = "multiply"
+	Divide   Operator = "divide"
+)
+
+// Arithmetic computes an exact result from two or more numbers, free of the rounding errors of
+// floating point (0.1 + 0.2 is 0.3), which makes it suitable for money
+type Arithmetic struct {
+	Config
+	Operator Operator
+}
+
+// Transform applies the operator to the arguments from left to right
+func (t *Arithmetic) Transform() (Results, error) {
+	if len(t.Args) < 2 {
+		return nil, fmt.Errorf("%s requires at least two arguments", t.Operator)
+	}
+	numbers, err := t.numbers(0)
+	if err != nil {
+		return nil, err
+	}
+
+	result := new(big.Rat).Set(numbers[0])
+	for i, n := range numbers[1:] {
+		switch t.Operator {
+		case Add:
+			result.Add(result, n)
+		case Subtract:
+			result.Sub(result, n)
+		case Multiply:
+			result.Mul(result, n)
+		case Divide:
+			if n.Sign() == 0 {
+				return nil, fmt.Errorf("division by zero: '%s' is 0", t.Args[i+1])
+			}
+			result.Quo(result, n)
+		default:
+			return nil, fmt.Errorf("unknown operator '%s'", t.Operator)
+		}
+	}
+	return Results{decimal(result)}, nil
+}
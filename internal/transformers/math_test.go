@@ -0,0 +1,81 @@
+package transformers_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/codeis4fun/data-treatment-interpreter/internal/transformers"
+)
+
+func TestMathTransformers(t *testing.T) {
+	input := []byte(`{"price":"19.995","tenth":0.1,"fifth":"0.2","half":2.5,"negative":-2.5,"big":1e3,"one":1,"three":3,"spaced":" 7.10 "}`)
+	tests := []struct {
+		name        string
+		transformer transformer
+		expected    json.Number
+	}{
+		{name: "round half-up string", transformer: &transformers.Round{Config: jsonConfig(input, "price", "'2'"), Mode: transformers.HalfUp}, expected: "20"},
+		{name: "round half-up negative", transformer: &transformers.Round{Config: jsonConfig(input, "negative"), Mode: transformers.HalfUp}, expected: "-3"},
+		{name: "round half-even", transformer: &transformers.Round{Config: jsonConfig(input, "half", "'0'", "'half-even'"), Mode: transformers.HalfUp}, expected: "2"},
+		{name: "round truncate", transformer: &transformers.Round{Config: jsonConfig(input, "price", "'1'", "'truncate'"), Mode: transformers.HalfUp}, expected: "19.9"},
+		{name: "round negative digits", transformer: &transformers.Round{Config: jsonConfig(input, "big", "'-3'"), Mode: transformers.HalfUp}, expected: "1000"},
+		{name: "floor negative", transformer: &transformers.Round{Config: jsonConfig(input, "negative"), Mode: transformers.Floor}, expected: "-3"},
+		{name: "ceil negative", transformer: &transformers.Round{Config: jsonConfig(input, "negative"), Mode: transformers.Ceil}, expected: "-2"},
+		{name: "abs", transformer: &transformers.Abs{Config: jsonConfig(input, "negative")}, expected: "2.5"},
+		{name: "min", transformer: &transformers.Extreme{Config: jsonConfig(input, "half", "fifth", "'-1'")}, expected: "-1"},
+		{name: "max trims spaces", transformer: &transformers.Extreme{Config: jsonConfig(input, "spaced", "half"), Max: true}, expected: "7.1"},
+		{name: "clamp below", transformer: &transformers.Clamp{Config: jsonConfig(input, "negative", "'0'", "'10'")}, expected: "0"},
+		{name: "clamp inside", transformer: &transformers.Clamp{Config: jsonConfig(input, "half", "'0'", "'10'")}, expected: "2.5"},
+		{name: "percent", transformer: &transformers.Percent{Config: jsonConfig(input, "one", "three")}, expected: "33.33"},
+		{name: "percent digits", transformer: &transformers.Percent{Config: jsonConfig(input, "one", "three", "'0'")}, expected: "33"},
+		{name: "add is exact", transformer: &transformers.Arithmetic{Config: jsonConfig(input, "tenth", "fifth"), Operator: transformers.Add}, expected: "0.3"},
+		{name: "subtract", transformer: &transformers.Arithmetic{Config: jsonConfig(input, "fifth", "tenth", "tenth"), Operator: transformers.Subtract}, expected: "0"},
+		{name: "multiply", transformer: &transformers.Arithmetic{Config: jsonConfig(input, "price", "three"), Operator: transformers.Multiply}, expected: "59.985"},
+		{name: "divide exact", transformer: &transformers.Arithmetic{Config: jsonConfig(input, "one", "'8'"), Operator: transformers.Divide}, expected: "0.125"},
+		{name: "divide repeating", transformer: &transformers.Arithmetic{Config: jsonConfig(input, "one", "three"), Operator: transformers.Divide}, expected: "0.3333333333"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results, err := tt.transformer.Transform()
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if results[0] != tt.expected {
+				t.Errorf("Expected %v (%T), got %v (%T)", tt.expected, tt.expected, results[0], results[0])
+			}
+		})
+	}
+}
+
+func TestMathTransformersWithInvalidArguments(t *testing.T) {
+	input := []byte(`{"text":"n/a","flag":true,"zero":0,"one":1,"huge":"1e1000000"}`)
+	tests := []struct {
+		name        string
+		transformer transformer
+		expected    string
+	}{
+		{name: "not a number", transformer: &transformers.Abs{Config: jsonConfig(input, "text")}, expected: `argument 'text' is not a number: "n/a"`},
+		{name: "boolean", transformer: &transformers.Abs{Config: jsonConfig(input, "flag")}, expected: "argument 'flag' is not a number: true"},
+		{name: "missing", transformer: &transformers.Abs{Config: jsonConfig(input, "price")}, expected: "argument 'price' not found in JSON"},
+		{name: "out of range", transformer: &transformers.Abs{Config: jsonConfig(input, "huge")}, expected: `argument 'huge' is out of range: "1e1000000"`},
+		{name: "unknown mode", transformer: &transformers.Round{Config: jsonConfig(input, "'1.5'", "'0'", "'up'")}, expected: "unknown rounding mode 'up' (expected half-up, half-even, floor, ceil or truncate)"},
+		{name: "digits not an integer", transformer: &transformers.Round{Config: jsonConfig(input, "one", "'two'"), Mode: transformers.HalfUp}, expected: "digits must be an integer, got 'two'"},
+		{name: "too many digits", transformer: &transformers.Round{Config: jsonConfig(input, "one", "'1000'"), Mode: transformers.HalfUp}, expected: "digits must be between -100 and 100, got 1000"},
+		{name: "inverted bounds", transformer: &transformers.Clamp{Config: jsonConfig(input, "one", "'5'", "'1'")}, expected: "lower bound 5 is greater than upper bound 1"},
+		{name: "percent of zero", transformer: &transformers.Percent{Config: jsonConfig(input, "one", "zero")}, expected: "division by zero: 'zero' is 0"},
+		{name: "divide by zero", transformer: &transformers.Arithmetic{Config: jsonConfig(input, "one", "one", "zero"), Operator: transformers.Divide}, expected: "division by zero: 'zero' is 0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.transformer.Transform()
+			if err == nil {
+				t.Fatalf("Expected error, but got nil")
+			}
+			if err.Error() != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, err)
+			}
+		})
+	}
+}
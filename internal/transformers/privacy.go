@@ -0,0 +1,256 @@
+package transformers
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Redacted replaces the values removed by redact and the REDACT statement
+const Redacted = "[REDACTED]"
+
+// maskRune is the character masked characters are replaced with unless the script gives another one
+const maskRune = '*'
+
+// pseudonymBytes is the length of the pseudonyms, which are 128 bits written in hexadecimal
+const pseudonymBytes = 16
+
+// key reads the secret at index i used by the keyed transformers, usually a $parameter
+func (c Config) key(i int, name string) ([]byte, error) {
+	key := c.Text(i)
+	if key == "" {
+		return nil, fmt.Errorf("%s key must not be empty", name)
+	}
+	return []byte(key), nil
+}
+
+// Redact replaces a value
+type Redact struct {
+	Config
+}
+
+// Transform returns [REDACTED], or the given replacement, whatever the input
+func (t *Redact) Transform() (Results, error) {
+	if len(t.Args) < 1 || len(t.Args) > 2 {
+		return nil, fmt.Errorf("redact requires one or two arguments")
+	}
+	if !t.Value(0).Exists() {
+		return nil, fmt.Errorf("argument '%s' not found in JSON", t.Args[0])
+	}
+	if len(t.Args) == 2 {
+		return Results{t.Text(1)}, nil
+	}
+	return Results{Redacted}, nil
+}
+
+// Mask hides all but the last characters of a value
+type Mask struct {
+	Config
+}
+
+// Transform replaces every character but the last ones (4 by default) with *, or the given character
+func (t *Mask) Transform() (Results, error) {
+	if len(t.Args) < 1 || len(t.Args) > 3 {
+		return nil, fmt.Errorf("mask requires between one and three arguments")
+	}
+	value, err := t.text(0)
+	if err != nil {
+		return nil, err
+	}
+	keep := 4
+	if len(t.Args) >= 2 {
+		if keep, err = t.integer(1, "keepLast"); err != nil {
+			return nil, err
+		}
+		if keep < 0 {
+			return nil, fmt.Errorf("keepLast must not be negative, got %d", keep)
+		}
+	}
+	mask, err := t.maskCharacter(2)
+	if err != nil {
+		return nil, err
+	}
+
+	runes := []rune(value)
+	for i := range len(runes) - min(keep, len(runes)) {
+		runes[i] = mask
+	}
+	return Results{string(runes)}, nil
+}
+
+// maskCharacter reads the optional mask character at index i
+func (c Config) maskCharacter(i int) (rune, error) {
+	if i >= len(c.Args) {
+		return maskRune, nil
+	}
+	text := c.Text(i)
+	if utf8.RuneCountInString(text) != 1 {
+		return 0, fmt.Errorf("mask must be a single character, got '%s'", text)
+	}
+	r, _ := utf8.DecodeRuneInString(text)
+	return r, nil
+}
+
+// MaskEmail hides the local part of an email address
+type MaskEmail struct {
+	Config
+}
+
+// Transform keeps the first character of the local part and the domain, and replaces the rest with *
+// (john.doe@example.com becomes j*******@example.com)
+func (t *MaskEmail) Transform() (Results, error) {
+	if len(t.Args) != 1 {
+		return nil, fmt.Errorf("maskemail requires exactly one argument")
+	}
+	value, err := t.text(0)
+	if err != nil {
+		return nil, err
+	}
+	at := strings.LastIndex(value, "@")
+	if at <= 0 || at == len(value)-1 {
+		return nil, fmt.Errorf("value '%s' is not an email address", value)
+	}
+	local := []rune(value[:at])
+	return Results{string(local[0]) + strings.Repeat(string(maskRune), len(local)-1) + value[at:]}, nil
+}
+
+// MaskPhone hides the digits of a phone number
+type MaskPhone struct {
+	Config
+}
+
+// Transform replaces every digit but the last ones (4 by default) with *, keeping the formatting
+// (+1 (555) 123-4567 becomes +* (***) ***-4567)
+func (t *MaskPhone) Transform() (Results, error) {
+	if len(t.Args) < 1 || len(t.Args) > 2 {
+		return nil, fmt.Errorf("maskphone requires one or two arguments")
+	}
+	value, err := t.text(0)
+	if err != nil {
+		return nil, err
+	}
+	keep := 4
+	if len(t.Args) == 2 {
+		if keep, err = t.integer(1, "keepLast"); err != nil {
+			return nil, err
+		}
+		if keep < 0 {
+			return nil, fmt.Errorf("keepLast must not be negative, got %d", keep)
+		}
+	}
+
+	runes := []rune(value)
+	digits := 0
+	for _, r := range runes {
+		if unicode.IsDigit(r) {
+			digits++
+		}
+	}
+	if digits == 0 {
+		return nil, fmt.Errorf("value '%s' is not a phone number", value)
+	}
+	for i, r := range runes {
+		if unicode.IsDigit(r) {
+			if digits > keep {
+				runes[i] = maskRune
+			}
+			digits--
+		}
+	}
+	return Results{string(runes)}, nil
+}
+
+// Pseudonymize replaces a value with a keyed token
+type Pseudonymize struct {
+	Config
+}
+
+// Transform returns the HMAC-SHA256 of the input with the key, truncated to 128 bits and written in
+// hexadecimal after the optional prefix. The same input and key always give the same token, so
+// pseudonymized records can still be joined, while the input cannot be recovered without the key
+func (t *Pseudonymize) Transform() (Results, error) {
+	if len(t.Args) < 2 || len(t.Args) > 3 {
+		return nil, fmt.Errorf("pseudonymize requires two or three arguments")
+	}
+	content, err := t.content(0)
+	if err != nil {
+		return nil, err
+	}
+	key, err := t.key(1, "pseudonymize")
+	if err != nil {
+		return nil, err
+	}
+	prefix := ""
+	if len(t.Args) == 3 {
+		prefix = t.Text(2)
+	}
+	mac := hmac.New(sha256.New, key)
+	mac.Write(content)
+	return Results{prefix + hex.EncodeToString(mac.Sum(nil)[:pseudonymBytes])}, nil
+}
+
+// FormatPreservingMask replaces the letters and digits of a value with keyed substitutes
+type FormatPreservingMask struct {
+	Config
+}
+
+// Transform replaces every digit with a digit and every ASCII letter with a letter of the same case,
+// chosen from the HMAC-SHA256 of the whole input with the key, and keeps the other characters. The
+// result has the format of the input (e.g. 4111-1111-1111-1111 stays a 16-digit card number) and is the
+// same for the same input and key. It is a one-way mask, not format-preserving encryption: it cannot be
+// reversed, and different inputs may give the same output
+func (t *FormatPreservingMask) Transform() (Results, error) {
+	if len(t.Args) != 2 {
+		return nil, fmt.Errorf("fpmask requires exactly two arguments")
+	}
+	value, err := t.text(0)
+	if err != nil {
+		return nil, err
+	}
+	key, err := t.key(1, "fpmask")
+	if err != nil {
+		return nil, err
+	}
+
+	stream := keystream{key: key, input: []byte(value)}
+	runes := []rune(value)
+	for i, r := range runes {
+		switch {
+		case '0' <= r && r <= '9':
+			runes[i] = '0' + stream.next(10)
+		case 'a' <= r && r <= 'z':
+			runes[i] = 'a' + stream.next(26)
+		case 'A' <= r && r <= 'Z':
+			runes[i] = 'A' + stream.next(26)
+		}
+	}
+	return Results{string(runes)}, nil
+}
+
+// keystream is a sequence of keyed pseudo-random numbers derived from an input: the HMAC-SHA256 of the
+// input and a block counter, read two bytes at a time
+type keystream struct {
+	key     []byte
+	input   []byte
+	block   []byte
+	counter uint64
+}
+
+// next returns a number below n, which is small enough for the bias of the modulo to be negligible
+func (s *keystream) next(n int) rune {
+	if len(s.block) < 2 {
+		mac := hmac.New(sha256.New, s.key)
+		mac.Write(s.input)
+		mac.Write(binary.BigEndian.AppendUint64(nil, s.counter))
+		s.block = mac.Sum(nil)
+		s.counter++
+	}
+	v := binary.BigEndian.Uint16(s.block)
+	s.block = s.block[2:]
+	return rune(int(v) % n)
+}
@@ -0,0 +1,85 @@
+package transformers_test
+
+import (
+	"testing"
+
+	"github.com/codeis4fun/data-treatment-interpreter/internal/transformers"
+)
+
+func TestPrivacyTransformers(t *testing.T) {
+	json := []byte(`{"card":"4111-1111-1111-1111","short":"abc","name":"José","email":"john.doe@example.com","phone":"+1 (555) 123-4567","id":12345,"ssn":null}`)
+	tests := []struct {
+		name        string
+		transformer transformer
+		expected    string
+	}{
+		{name: "redact", transformer: &transformers.Redact{Config: jsonConfig(json, "email")}, expected: "[REDACTED]"},
+		{name: "redact null", transformer: &transformers.Redact{Config: jsonConfig(json, "ssn")}, expected: "[REDACTED]"},
+		{name: "redact with replacement", transformer: &transformers.Redact{Config: jsonConfig(json, "email", "'n/a'")}, expected: "n/a"},
+		{name: "mask", transformer: &transformers.Mask{Config: jsonConfig(json, "card")}, expected: "***************1111"},
+		{name: "mask shorter than kept", transformer: &transformers.Mask{Config: jsonConfig(json, "short")}, expected: "abc"},
+		{name: "mask characters", transformer: &transformers.Mask{Config: jsonConfig(json, "name", "'1'", "'•'")}, expected: "•••é"},
+		{name: "mask everything", transformer: &transformers.Mask{Config: jsonConfig(json, "id", "'0'")}, expected: "*****"},
+		{name: "maskemail", transformer: &transformers.MaskEmail{Config: jsonConfig(json, "email")}, expected: "j*******@example.com"},
+		{name: "maskphone", transformer: &transformers.MaskPhone{Config: jsonConfig(json, "phone")}, expected: "+* (***) ***-4567"},
+		{name: "maskphone keeps two", transformer: &transformers.MaskPhone{Config: jsonConfig(json, "phone", "'2'")}, expected: "+* (***) ***-**67"},
+		{name: "pseudonymize", transformer: &transformers.Pseudonymize{Config: jsonConfig(json, "email", "'s3cret'")}, expected: "73a27700e829713f632a46c336997a37"},
+		{name: "pseudonymize with prefix", transformer: &transformers.Pseudonymize{Config: jsonConfig(json, "email", "'s3cret'", "'usr_'")}, expected: "usr_73a27700e829713f632a46c336997a37"},
+		{name: "fpmask", transformer: &transformers.FormatPreservingMask{Config: jsonConfig(json, "card", "'s3cret'")}, expected: "1967-1128-7280-0047"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results, err := tt.transformer.Transform()
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if results[0] != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, results[0])
+			}
+		})
+	}
+}
+
+func TestPseudonymizeDependsOnTheKey(t *testing.T) {
+	json := []byte(`{"email":"john.doe@example.com"}`)
+	first, err := (&transformers.Pseudonymize{Config: transformers.Config{Args: []string{"email", "'one'"}, Json: json}}).Transform()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	second, err := (&transformers.Pseudonymize{Config: transformers.Config{Args: []string{"email", "'two'"}, Json: json}}).Transform()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if first[0] == second[0] {
+		t.Errorf("Expected different tokens for different keys, got %s twice", first[0])
+	}
+}
+
+func TestPrivacyTransformersWithInvalidArguments(t *testing.T) {
+	json := []byte(`{"email":"@example.com","phone":"n/a","card":"4111"}`)
+	tests := []struct {
+		name        string
+		transformer transformer
+		expected    string
+	}{
+		{name: "redact missing", transformer: &transformers.Redact{Config: jsonConfig(json, "ssn")}, expected: "argument 'ssn' not found in JSON"},
+		{name: "negative keepLast", transformer: &transformers.Mask{Config: jsonConfig(json, "card", "'-1'")}, expected: "keepLast must not be negative, got -1"},
+		{name: "long mask", transformer: &transformers.Mask{Config: jsonConfig(json, "card", "'2'", "'**'")}, expected: "mask must be a single character, got '**'"},
+		{name: "email without local part", transformer: &transformers.MaskEmail{Config: jsonConfig(json, "email")}, expected: "value '@example.com' is not an email address"},
+		{name: "phone without digits", transformer: &transformers.MaskPhone{Config: jsonConfig(json, "phone")}, expected: "value 'n/a' is not a phone number"},
+		{name: "empty fpmask key", transformer: &transformers.FormatPreservingMask{Config: jsonConfig(json, "card", "''")}, expected: "fpmask key must not be empty"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.transformer.Transform()
+			if err == nil {
+				t.Fatalf("Expected error, but got nil")
+			}
+			if err.Error() != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, err)
+			}
+		})
+	}
+}
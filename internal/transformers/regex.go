@@ -0,0 +1,153 @@
+package transformers
+
+import (
+	"fmt"
+	"regexp"
+	"sync"
+)
+
+// maxDynamicPatterns bounds the number of patterns a script compiles at run time (e.g. from $parameters)
+// and keeps for later records
+const maxDynamicPatterns = 64
+
+// Pattern compiles a regular expression. Patterns are RE2 expressions, which match in linear time
+func Pattern(source string) (*regexp.Regexp, error) {
+	re, err := regexp.Compile(source)
+	if err != nil {
+		return nil, fmt.Errorf("invalid regular expression '%s': %w", source, err)
+	}
+	return re, nil
+}
+
+// Patterns holds the compiled regular expressions of a script, so each pattern is compiled once rather than
+// once per record. The literal patterns of the script are added when it is compiled; other patterns are
+// compiled on first use and kept up to a bound, past which they are compiled on every use
+type Patterns struct {
+	literal map[string]*regexp.Regexp // Added before the script runs, read-only afterwards
+
+	mu      sync.Mutex
+	dynamic map[string]*regexp.Regexp
+}
+
+// NewPatterns creates an empty set of patterns
+func NewPatterns() *Patterns {
+	return &Patterns{literal: map[string]*regexp.Regexp{}, dynamic: map[string]*regexp.Regexp{}}
+}
+
+// Add compiles a literal pattern of the script. It must not be called once the script runs
+func (p *Patterns) Add(source string) error {
+	if _, ok := p.literal[source]; ok {
+		return nil
+	}
+	re, err := Pattern(source)
+	if err != nil {
+		return err
+	}
+	p.literal[source] = re
+	return nil
+}
+
+// Get returns the compiled pattern, compiling patterns that were not added on first use
+func (p *Patterns) Get(source string) (*regexp.Regexp, error) {
+	if p == nil {
+		return Pattern(source)
+	}
+	if re, ok := p.literal[source]; ok {
+		return re, nil
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if re, ok := p.dynamic[source]; ok {
+		return re, nil
+	}
+	re, err := Pattern(source)
+	if err != nil {
+		return nil, err
+	}
+	if len(p.dynamic) < maxDynamicPatterns {
+		p.dynamic[source] = re
+	}
+	return re, nil
+}
+
+// pattern returns the compiled pattern of the literal argument at index i
+func (c Config) pattern(i int) (*regexp.Regexp, error) {
+	return c.Patterns.Get(c.Text(i))
+}
+
+// Match tells whether the value of a field matches a regular expression
+type Match struct {
+	Config
+}
+
+// Transform returns whether the pattern matches anywhere in the input; anchor it with ^ and $ to match the whole value
+func (t *Match) Transform() (Results, error) {
+	if len(t.Args) != 2 {
+		return nil, fmt.Errorf("match requires exactly two arguments")
+	}
+	value, err := t.text(0)
+	if err != nil {
+		return nil, err
+	}
+	re, err := t.pattern(1)
+	if err != nil {
+		return nil, err
+	}
+	return Results{re.MatchString(value)}, nil
+}
+
+// Extract returns the capture groups of the first match of a regular expression in the value of a field
+type Extract struct {
+	Config
+}
+
+// Transform returns one result per capture group, numbered or named, in the order they appear in the
+// pattern, or the whole match when the pattern has no groups. Groups that did not take part in the match are empty
+func (t *Extract) Transform() (Results, error) {
+	if len(t.Args) != 2 {
+		return nil, fmt.Errorf("extract requires exactly two arguments")
+	}
+	value, err := t.text(0)
+	if err != nil {
+		return nil, err
+	}
+	re, err := t.pattern(1)
+	if err != nil {
+		return nil, err
+	}
+
+	match := re.FindStringSubmatch(value)
+	if match == nil {
+		return nil, fmt.Errorf("value '%s' does not match '%s'", value, re)
+	}
+	if len(match) > 1 {
+		match = match[1:]
+	}
+	results := make(Results, len(match))
+	for i, group := range match {
+		results[i] = group
+	}
+	return results, nil
+}
+
+// RegexReplace replaces the matches of a regular expression in the value of a field
+type RegexReplace struct {
+	Config
+}
+
+// Transform replaces every match with the replacement, in which $1 or ${name} stand for capture groups
+func (t *RegexReplace) Transform() (Results, error) {
+	if len(t.Args) != 3 {
+		return nil, fmt.Errorf("regexreplace requires exactly three arguments")
+	}
+	value, err := t.text(0)
+	if err != nil {
+		return nil, err
+	}
+	re, err := t.pattern(1)
+	if err != nil {
+		return nil, err
+	}
+	return Results{re.ReplaceAllString(value, t.Text(2))}, nil
+}
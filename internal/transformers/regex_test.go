@@ -0,0 +1,91 @@
+package transformers_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/codeis4fun/data-treatment-interpreter/internal/transformers"
+)
+
+func TestRegexTransformers(t *testing.T) {
+	json := []byte(`{"place":"New York/USA","phone":"+1 (555) 010-999","date":"2024-03-15"}`)
+
+	tests := []struct {
+		name        string
+		transformer transformer
+		expected    transformers.Results
+	}{
+		{name: "match", transformer: &transformers.Match{Config: transformers.Config{Args: []string{"phone", `'^\+\d'`}, Json: json}}, expected: transformers.Results{true}},
+		{name: "no match", transformer: &transformers.Match{Config: transformers.Config{Args: []string{"place", `'^\d+$'`}, Json: json}}, expected: transformers.Results{false}},
+		{name: "extract numbered groups", transformer: &transformers.Extract{Config: transformers.Config{Args: []string{"place", "'(.+)/(.+)'"}, Json: json}}, expected: transformers.Results{"New York", "USA"}},
+		{name: "extract named groups", transformer: &transformers.Extract{Config: transformers.Config{Args: []string{"date", `'(?P<year>\d+)-(?P<month>\d+)-(\d+)'`}, Json: json}}, expected: transformers.Results{"2024", "03", "15"}},
+		{name: "extract optional group", transformer: &transformers.Extract{Config: transformers.Config{Args: []string{"place", "'(York)(x)?'"}, Json: json}}, expected: transformers.Results{"York", ""}},
+		{name: "extract whole match", transformer: &transformers.Extract{Config: transformers.Config{Args: []string{"phone", `'\d{3}'`}, Json: json}}, expected: transformers.Results{"555"}},
+		{name: "regexreplace", transformer: &transformers.RegexReplace{Config: transformers.Config{Args: []string{"phone", `'\D'`, "''"}, Json: json}}, expected: transformers.Results{"1555010999"}},
+		{name: "regexreplace with groups", transformer: &transformers.RegexReplace{Config: transformers.Config{Args: []string{"date", `'(\d+)-(\d+)-(\d+)'`, "'$3/$2/$1'"}, Json: json}}, expected: transformers.Results{"15/03/2024"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results, err := tt.transformer.Transform()
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(results, tt.expected) {
+				t.Errorf("Expected %v, got %v", tt.expected, results)
+			}
+		})
+	}
+}
+
+func TestExtractWithoutMatch(t *testing.T) {
+	transformer := &transformers.Extract{
+		Config: transformers.Config{
+			Args: []string{"place", "'(.+)/(.+)'"},
+			Json: []byte(`{"place":"Lisbon"}`),
+		},
+	}
+
+	_, err := transformer.Transform()
+	if err == nil {
+		t.Fatalf("Expected error, but got nil")
+	}
+	expected := "value 'Lisbon' does not match '(.+)/(.+)'"
+	if err.Error() != expected {
+		t.Errorf("Expected %s, got %s", expected, err)
+	}
+}
+
+func TestPatterns(t *testing.T) {
+	patterns := transformers.NewPatterns()
+	if err := patterns.Add(`^\d+$`); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	digits, err := patterns.Get(`^\d+$`)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if again, _ := patterns.Get(`^\d+$`); again != digits {
+		t.Errorf("Expected the compiled pattern to be reused")
+	}
+
+	// Patterns that were not added are compiled on first use and kept
+	first, _ := patterns.Get(`^[a-z]+$`)
+	second, _ := patterns.Get(`^[a-z]+$`)
+	if first == nil || first != second {
+		t.Errorf("Expected the pattern compiled at run time to be reused")
+	}
+
+	// Every set of patterns is compiled separately
+	other, _ := transformers.NewPatterns().Get(`^\d+$`)
+	if other == nil || other == digits {
+		t.Errorf("Expected another set of patterns to compile its own pattern")
+	}
+
+	if err := patterns.Add(`(`); err == nil {
+		t.Errorf("Expected error, but got nil")
+	}
+	if _, err := patterns.Get(`(`); err == nil {
+		t.Errorf("Expected error, but got nil")
+	}
+}
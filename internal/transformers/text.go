@@ -3,10 +3,13 @@ package transformers
 import (
 	"fmt"
 	"strings"
-
-	"github.com/tidwall/gjson"
+	"unicode"
+	"unicode/utf8"
 )
 
+// maxRepeatLength bounds the strings built by repeat, which would otherwise allocate before any engine limit is checked
+const maxRepeatLength = 1 << 20
+
 // Uppercase struct holds the arguments and JSON data for transformation
 type Uppercase struct {
 	Config
@@ -18,11 +21,10 @@ func (t *Uppercase) Transform() (Results, error) {
 		return nil, fmt.Errorf("uppercase requires exactly one argument")
 	}
 
-	argument := t.Args[0]
 	// Fetch the argument value from the JSON
-	value := gjson.GetBytes(t.Json, argument)
+	value := t.Field(0)
 	if !value.Exists() {
-		return nil, fmt.Errorf("argument '%s' not found in JSON", argument)
+		return nil, fmt.Errorf("argument '%s' not found in JSON", t.Args[0])
 	}
 
 	// Convert the value to uppercase
@@ -42,11 +44,11 @@ func (t *Concatenate) Transform() (Results, error) {
 
 	// Fetch the argument values from the JSON
 	var values []string
-	separator := strings.Trim(t.Args[0], "'")
-	for _, arg := range t.Args[1:] {
-		value := gjson.GetBytes(t.Json, arg)
+	separator := t.Text(0)
+	for i := 1; i < len(t.Args); i++ {
+		value := t.Field(i)
 		if !value.Exists() {
-			return nil, fmt.Errorf("argument '%s' not found in JSON", arg)
+			return nil, fmt.Errorf("argument '%s' not found in JSON", t.Args[i])
 		}
 		values = append(values, value.String())
 	}
@@ -65,16 +67,14 @@ func (t *Split) Transform() (Results, error) {
 		return nil, fmt.Errorf("split requires exactly two arguments")
 	}
 
-	field := t.Args[0]
-	separator := t.Args[1]
 	// Fetch the argument value from the JSON
-	value := gjson.GetBytes(t.Json, field)
+	value := t.Field(0)
 	if !value.Exists() {
-		return nil, fmt.Errorf("argument '%s' not found in JSON", field)
+		return nil, fmt.Errorf("argument '%s' not found in JSON", t.Args[0])
 	}
 
 	// Split the value
-	splitValue := strings.Split(value.String(), strings.Trim(separator, "'"))
+	splitValue := strings.Split(value.String(), t.Text(1))
 	results := Results{}
 	for _, v := range splitValue {
 		results = append(results, v)
@@ -82,3 +82,277 @@ func (t *Split) Transform() (Results, error) {
 	return results, nil
 
 }
+
+// Lowercase converts the value of a field to lowercase
+type Lowercase struct {
+	Config
+}
+
+// Transform converts the input to lowercase
+func (t *Lowercase) Transform() (Results, error) {
+	if len(t.Args) != 1 {
+		return nil, fmt.Errorf("lowercase requires exactly one argument")
+	}
+	value, err := t.text(0)
+	if err != nil {
+		return nil, err
+	}
+	return Results{strings.ToLower(value)}, nil
+}
+
+// Titlecase capitalises the first letter of every word and lowercases the others
+type Titlecase struct {
+	Config
+}
+
+// Transform converts the input to title case. Words start after any character other than a letter,
+// a digit or an apostrophe, so "jean-luc o'neil" becomes "Jean-Luc O'neil"
+func (t *Titlecase) Transform() (Results, error) {
+	if len(t.Args) != 1 {
+		return nil, fmt.Errorf("titlecase requires exactly one argument")
+	}
+	value, err := t.text(0)
+	if err != nil {
+		return nil, err
+	}
+
+	var builder strings.Builder
+	inWord := false
+	for _, r := range value {
+		if inWord {
+			builder.WriteRune(unicode.ToLower(r))
+		} else {
+			builder.WriteRune(unicode.ToTitle(r))
+		}
+		inWord = unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.IsMark(r) || r == '\'' || r == '’'
+	}
+	return Results{builder.String()}, nil
+}
+
+// Trim removes leading and trailing whitespace, or the given characters, from the value of a field
+type Trim struct {
+	Config
+	Name        string // trim, ltrim or rtrim
+	Left, Right bool   // Sides to trim
+}
+
+// Transform trims the input
+func (t *Trim) Transform() (Results, error) {
+	if len(t.Args) < 1 || len(t.Args) > 2 {
+		return nil, fmt.Errorf("%s requires one or two arguments", t.Name)
+	}
+	value, err := t.text(0)
+	if err != nil {
+		return nil, err
+	}
+
+	trimmed := func(r rune) bool { return unicode.IsSpace(r) }
+	if len(t.Args) == 2 {
+		cutset := t.Text(1)
+		trimmed = func(r rune) bool { return strings.ContainsRune(cutset, r) }
+	}
+	if t.Left {
+		value = strings.TrimLeftFunc(value, trimmed)
+	}
+	if t.Right {
+		value = strings.TrimRightFunc(value, trimmed)
+	}
+	return Results{value}, nil
+}
+
+// Replace replaces every occurrence of a text in the value of a field
+type Replace struct {
+	Config
+}
+
+// Transform replaces the old text with the new one
+func (t *Replace) Transform() (Results, error) {
+	if len(t.Args) != 3 {
+		return nil, fmt.Errorf("replace requires exactly three arguments")
+	}
+	value, err := t.text(0)
+	if err != nil {
+		return nil, err
+	}
+	return Results{strings.ReplaceAll(value, t.Text(1), t.Text(2))}, nil
+}
+
+// Substring extracts part of the value of a field, counting characters rather than bytes
+type Substring struct {
+	Config
+}
+
+// Transform returns the characters from start (negative counts from the end), up to length characters
+// when given. Positions past the end of the value are clamped, so the result may be shorter or empty
+func (t *Substring) Transform() (Results, error) {
+	if len(t.Args) < 2 || len(t.Args) > 3 {
+		return nil, fmt.Errorf("substring requires two or three arguments")
+	}
+	value, err := t.text(0)
+	if err != nil {
+		return nil, err
+	}
+	start, err := t.integer(1, "start")
+	if err != nil {
+		return nil, err
+	}
+
+	runes := []rune(value)
+	if start < 0 {
+		start = max(len(runes)+start, 0)
+	}
+	start = min(start, len(runes))
+	end := len(runes)
+	if len(t.Args) == 3 {
+		length, err := t.integer(2, "length")
+		if err != nil {
+			return nil, err
+		}
+		if length < 0 {
+			return nil, fmt.Errorf("length must not be negative, got %d", length)
+		}
+		end = min(start+length, end)
+	}
+	return Results{string(runes[start:end])}, nil
+}
+
+// Pad pads the value of a field to a width in characters
+type Pad struct {
+	Config
+	Name string // padleft or padright
+	Left bool   // Pad on the left instead of the right
+}
+
+// Transform pads the input with spaces, or with the given padding, until it is width characters long.
+// Longer values are returned unchanged
+func (t *Pad) Transform() (Results, error) {
+	if len(t.Args) < 2 || len(t.Args) > 3 {
+		return nil, fmt.Errorf("%s requires two or three arguments", t.Name)
+	}
+	value, err := t.text(0)
+	if err != nil {
+		return nil, err
+	}
+	width, err := t.integer(1, "width")
+	if err != nil {
+		return nil, err
+	}
+	if width > maxRepeatLength {
+		return nil, fmt.Errorf("width must not exceed %d, got %d", maxRepeatLength, width)
+	}
+	padding := " "
+	if len(t.Args) == 3 {
+		padding = t.Text(2)
+	}
+	if padding == "" {
+		return nil, fmt.Errorf("padding must not be empty")
+	}
+
+	missing := width - utf8.RuneCountInString(value)
+	if missing <= 0 {
+		return Results{value}, nil
+	}
+	fill := []rune(strings.Repeat(padding, missing/utf8.RuneCountInString(padding)+1))[:missing]
+	if t.Left {
+		return Results{string(fill) + value}, nil
+	}
+	return Results{value + string(fill)}, nil
+}
+
+// Length counts the characters (Unicode code points) of the value of a field
+type Length struct {
+	Config
+}
+
+// Transform returns the number of characters of the input
+func (t *Length) Transform() (Results, error) {
+	if len(t.Args) != 1 {
+		return nil, fmt.Errorf("length requires exactly one argument")
+	}
+	value, err := t.text(0)
+	if err != nil {
+		return nil, err
+	}
+	return Results{utf8.RuneCountInString(value)}, nil
+}
+
+// Contains tests the value of a field against a text
+type Contains struct {
+	Config
+	Name string                      // contains, startswith or endswith
+	Test func(s, substr string) bool // strings.Contains, strings.HasPrefix or strings.HasSuffix
+}
+
+// Transform returns whether the test holds for the input and the text
+func (t *Contains) Transform() (Results, error) {
+	if len(t.Args) != 2 {
+		return nil, fmt.Errorf("%s requires exactly two arguments", t.Name)
+	}
+	value, err := t.text(0)
+	if err != nil {
+		return nil, err
+	}
+	return Results{t.Test(value, t.Text(1))}, nil
+}
+
+// Repeat repeats the value of a field
+type Repeat struct {
+	Config
+}
+
+// Transform returns the input repeated count times
+func (t *Repeat) Transform() (Results, error) {
+	if len(t.Args) != 2 {
+		return nil, fmt.Errorf("repeat requires exactly two arguments")
+	}
+	value, err := t.text(0)
+	if err != nil {
+		return nil, err
+	}
+	count, err := t.integer(1, "count")
+	if err != nil {
+		return nil, err
+	}
+	if count < 0 {
+		return nil, fmt.Errorf("count must not be negative, got %d", count)
+	}
+	if count > 0 && len(value) > maxRepeatLength/count {
+		return nil, fmt.Errorf("repeat would produce more than %d bytes", maxRepeatLength)
+	}
+	return Results{strings.Repeat(value, count)}, nil
+}
+
+// Reverse reverses the characters of the value of a field
+type Reverse struct {
+	Config
+}
+
+// Transform reverses the input, keeping combining marks (e.g. the accent of a decomposed "é") after the letter they modify
+func (t *Reverse) Transform() (Results, error) {
+	if len(t.Args) != 1 {
+		return nil, fmt.Errorf("reverse requires exactly one argument")
+	}
+	value, err := t.text(0)
+	if err != nil {
+		return nil, err
+	}
+
+	// Split the input into characters made of a base rune and its combining marks, then emit them backwards
+	var characters []string
+	for i := 0; i < len(value); {
+		r, width := utf8.DecodeRuneInString(value[i:])
+		if unicode.IsMark(r) && len(characters) > 0 {
+			characters[len(characters)-1] += value[i : i+width]
+		} else {
+			characters = append(characters, value[i:i+width])
+		}
+		i += width
+	}
+
+	var builder strings.Builder
+	builder.Grow(len(value))
+	for i := len(characters) - 1; i >= 0; i-- {
+		builder.WriteString(characters[i])
+	}
+	return Results{builder.String()}, nil
+}
@@ -1,6 +1,7 @@
 package transformers_test
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/codeis4fun/data-treatment-interpreter/internal/transformers"
@@ -104,3 +105,87 @@ func TestSplitWithNonExistentField(t *testing.T) {
 		t.Fatalf("Expected error, but got nil")
 	}
 }
+
+// transformer is the contract shared by the transformers of this package
+type transformer interface {
+	Transform() (transformers.Results, error)
+}
+
+// stringInput is the document read by the string transformer tests
+const stringInput = `{"name":"  jOÃO da silva ","city":"são paulo","code":"42","word":"añejo","decomposed":"cafe\u0301","empty":""}`
+
+// stringConfig returns the configuration of a transformer reading stringInput
+func stringConfig(args ...string) transformers.Config {
+	return transformers.Config{Args: args, Json: []byte(stringInput)}
+}
+
+func TestStringTransformers(t *testing.T) {
+	tests := []struct {
+		name        string
+		transformer transformer
+		expected    any
+	}{
+		{name: "lowercase", transformer: &transformers.Lowercase{Config: stringConfig("name")}, expected: "  joão da silva "},
+		{name: "titlecase", transformer: &transformers.Titlecase{Config: stringConfig("name")}, expected: "  João Da Silva "},
+		{name: "trim", transformer: &transformers.Trim{Config: stringConfig("name"), Left: true, Right: true}, expected: "jOÃO da silva"},
+		{name: "ltrim", transformer: &transformers.Trim{Config: stringConfig("name"), Left: true}, expected: "jOÃO da silva "},
+		{name: "rtrim with characters", transformer: &transformers.Trim{Config: stringConfig("city", "'lou '"), Right: true}, expected: "são pa"},
+		{name: "replace", transformer: &transformers.Replace{Config: stringConfig("city", "' '", "'_'")}, expected: "são_paulo"},
+		{name: "substring", transformer: &transformers.Substring{Config: stringConfig("city", "'1'", "'2'")}, expected: "ão"},
+		{name: "substring from the end", transformer: &transformers.Substring{Config: stringConfig("city", "'-5'")}, expected: "paulo"},
+		{name: "substring past the end", transformer: &transformers.Substring{Config: stringConfig("code", "'5'", "'3'")}, expected: ""},
+		{name: "padleft", transformer: &transformers.Pad{Config: stringConfig("code", "'6'", "'ab'"), Left: true}, expected: "abab42"},
+		{name: "padright", transformer: &transformers.Pad{Config: stringConfig("word", "'7'")}, expected: "añejo  "},
+		{name: "pad shorter width", transformer: &transformers.Pad{Config: stringConfig("word", "'2'")}, expected: "añejo"},
+		{name: "length", transformer: &transformers.Length{Config: stringConfig("city")}, expected: 9},
+		{name: "startswith", transformer: &transformers.Contains{Config: stringConfig("city", "'são'"), Test: strings.HasPrefix}, expected: true},
+		{name: "endswith", transformer: &transformers.Contains{Config: stringConfig("city", "'são'"), Test: strings.HasSuffix}, expected: false},
+		{name: "contains", transformer: &transformers.Contains{Config: stringConfig("city", "'o p'"), Test: strings.Contains}, expected: true},
+		{name: "repeat", transformer: &transformers.Repeat{Config: stringConfig("code", "'3'")}, expected: "424242"},
+		{name: "repeat zero times", transformer: &transformers.Repeat{Config: stringConfig("code", "'0'")}, expected: ""},
+		{name: "reverse", transformer: &transformers.Reverse{Config: stringConfig("word")}, expected: "ojeña"},
+		{name: "reverse keeps combining marks", transformer: &transformers.Reverse{Config: stringConfig("decomposed")}, expected: "e\u0301fac"},
+		{name: "reverse empty", transformer: &transformers.Reverse{Config: stringConfig("empty")}, expected: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results, err := tt.transformer.Transform()
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if results[0] != tt.expected {
+				t.Errorf("Expected %v, got %v", tt.expected, results[0])
+			}
+		})
+	}
+}
+
+func TestStringTransformersWithInvalidArguments(t *testing.T) {
+	tests := []struct {
+		name        string
+		transformer transformer
+		expected    string
+	}{
+		{name: "missing field", transformer: &transformers.Lowercase{Config: transformers.Config{Args: []string{"missing"}, Json: []byte(`{}`)}}, expected: "argument 'missing' not found in JSON"},
+		{name: "start not a number", transformer: &transformers.Substring{Config: transformers.Config{Args: []string{"name", "'x'"}, Json: []byte(`{"name":"john"}`)}}, expected: "start must be an integer, got 'x'"},
+		{name: "negative length", transformer: &transformers.Substring{Config: transformers.Config{Args: []string{"name", "'0'", "'-1'"}, Json: []byte(`{"name":"john"}`)}}, expected: "length must not be negative, got -1"},
+		{name: "empty padding", transformer: &transformers.Pad{Config: transformers.Config{Args: []string{"name", "'9'", "''"}, Json: []byte(`{"name":"john"}`)}}, expected: "padding must not be empty"},
+		{name: "padleft arity", transformer: &transformers.Pad{Config: transformers.Config{Args: []string{"name"}, Json: []byte(`{"name":"john"}`)}, Name: "padleft", Left: true}, expected: "padleft requires two or three arguments"},
+		{name: "startswith arity", transformer: &transformers.Contains{Config: transformers.Config{Args: []string{"name"}, Json: []byte(`{"name":"john"}`)}, Name: "startswith", Test: strings.HasPrefix}, expected: "startswith requires exactly two arguments"},
+		{name: "rtrim arity", transformer: &transformers.Trim{Config: transformers.Config{Args: []string{}, Json: []byte(`{"name":"john"}`)}, Name: "rtrim", Right: true}, expected: "rtrim requires one or two arguments"},
+		{name: "huge repeat", transformer: &transformers.Repeat{Config: transformers.Config{Args: []string{"name", "'1000000'"}, Json: []byte(`{"name":"john"}`)}}, expected: "repeat would produce more than 1048576 bytes"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.transformer.Transform()
+			if err == nil {
+				t.Fatalf("Expected error, but got nil")
+			}
+			if err.Error() != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, err)
+			}
+		})
+	}
+}
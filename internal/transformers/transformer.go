@@ -1,8 +1,81 @@
 package transformers
 
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/tidwall/gjson"
+)
+
 type Results []any
 
 type Config struct {
-	Args []string
-	Json []byte
+	Args     []string
+	Json     []byte
+	Values   []gjson.Result   // Values of the arguments, resolved by the engine before the transformer runs
+	Now      func() time.Time // Clock of the engine, time.Now when nil
+	Patterns *Patterns        // Compiled regular expressions of the script, nil to compile them on every use
+}
+
+// Value returns the value of the argument at index i: quoted literals resolve to strings and other
+// arguments to the value of the field they name, which does not exist when the field is missing
+func (c Config) Value(i int) gjson.Result {
+	if len(c.Values) == len(c.Args) {
+		return c.Values[i]
+	}
+	return c.resolve(i)
+}
+
+// Text returns the text of a literal argument, such as a separator. Arguments resolved by the engine
+// (e.g. $parameters) are converted to text
+func (c Config) Text(i int) string {
+	if len(c.Values) == len(c.Args) {
+		return c.Values[i].String()
+	}
+	return strings.Trim(c.Args[i], "'")
+}
+
+// Field returns the value of the field named by the argument at index i. Literals name no field, so their value does not exist
+func (c Config) Field(i int) gjson.Result {
+	if strings.HasPrefix(c.Args[i], "'") {
+		return gjson.Result{}
+	}
+	return c.Value(i)
+}
+
+// text returns the value of the field named by the argument at index i as text, failing when the field is missing
+func (c Config) text(i int) (string, error) {
+	value := c.Field(i)
+	if !value.Exists() {
+		return "", fmt.Errorf("argument '%s' not found in JSON", c.Args[i])
+	}
+	return value.String(), nil
+}
+
+// integer returns the literal argument at index i as an integer
+func (c Config) integer(i int, name string) (int, error) {
+	n, err := strconv.Atoi(strings.TrimSpace(c.Text(i)))
+	if err != nil {
+		return 0, fmt.Errorf("%s must be an integer, got '%s'", name, c.Text(i))
+	}
+	return n, nil
+}
+
+// Literal returns the value of a quoted literal argument, which is always a string
+func Literal(arg string) gjson.Result {
+	literal := strings.Trim(arg, "'")
+	raw, _ := json.Marshal(literal)
+	return gjson.Result{Type: gjson.String, Str: literal, Raw: string(raw)}
+}
+
+// resolve looks up the value of the argument at index i
+func (c Config) resolve(i int) gjson.Result {
+	arg := c.Args[i]
+	if strings.HasPrefix(arg, "'") {
+		return Literal(arg)
+	}
+	return gjson.GetBytes(c.Json, arg)
 }
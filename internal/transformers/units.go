@@ -0,0 +1,118 @@
+package transformers
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// unit is a unit of measurement. A value v is (v + offset) * factor in the base unit of its quantity
+// (kilograms, metres, kelvins or litres); only temperatures have an offset
+type unit struct {
+	quantity string
+	factor   *big.Rat
+	offset   *big.Rat
+}
+
+// newUnit returns a unit from the decimal text of its factor and offset
+func newUnit(quantity, factor, offset string) unit {
+	f, _ := new(big.Rat).SetString(factor)
+	o, _ := new(big.Rat).SetString(offset)
+	return unit{quantity: quantity, factor: f, offset: o}
+}
+
+// units are the supported units, by lowercase symbol. Imperial and US customary units use their exact
+// international definitions (e.g. 1 lb = 0.45359237 kg)
+var units = map[string]unit{
+	"mg": newUnit("mass", "0.000001", "0"),
+	"g":  newUnit("mass", "0.001", "0"),
+	"kg": newUnit("mass", "1", "0"),
+	"t":  newUnit("mass", "1000", "0"),
+	"oz": newUnit("mass", "0.028349523125", "0"),
+	"lb": newUnit("mass", "0.45359237", "0"),
+	"st": newUnit("mass", "6.35029318", "0"),
+
+	"mm": newUnit("length", "0.001", "0"),
+	"cm": newUnit("length", "0.01", "0"),
+	"m":  newUnit("length", "1", "0"),
+	"km": newUnit("length", "1000", "0"),
+	"in": newUnit("length", "0.0254", "0"),
+	"ft": newUnit("length", "0.3048", "0"),
+	"yd": newUnit("length", "0.9144", "0"),
+	"mi": newUnit("length", "1609.344", "0"),
+
+	"c": newUnit("temperature", "1", "273.15"),
+	"f": newUnit("temperature", "5/9", "459.67"),
+	"k": newUnit("temperature", "1", "0"),
+
+	"ml":   newUnit("volume", "0.001", "0"),
+	"cl":   newUnit("volume", "0.01", "0"),
+	"dl":   newUnit("volume", "0.1", "0"),
+	"l":    newUnit("volume", "1", "0"),
+	"tsp":  newUnit("volume", "0.00492892159375", "0"),
+	"tbsp": newUnit("volume", "0.01478676478125", "0"),
+	"floz": newUnit("volume", "0.0295735295625", "0"),
+	"cup":  newUnit("volume", "0.2365882365", "0"),
+	"pt":   newUnit("volume", "0.473176473", "0"),
+	"qt":   newUnit("volume", "0.946352946", "0"),
+	"gal":  newUnit("volume", "3.785411784", "0"),
+}
+
+// lookupUnit returns the unit with the given symbol, in any case
+func lookupUnit(symbol string) (unit, error) {
+	u, ok := units[strings.ToLower(strings.TrimSpace(symbol))]
+	if !ok {
+		return unit{}, fmt.Errorf("unknown unit '%s'", symbol)
+	}
+	return u, nil
+}
+
+// convertUnit converts a value between two units of the same quantity
+func convertUnit(value *big.Rat, from, to string) (*big.Rat, error) {
+	source, err := lookupUnit(from)
+	if err != nil {
+		return nil, err
+	}
+	target, err := lookupUnit(to)
+	if err != nil {
+		return nil, err
+	}
+	if source.quantity != target.quantity {
+		return nil, fmt.Errorf("cannot convert %s (%s) to %s (%s)", from, source.quantity, to, target.quantity)
+	}
+	base := new(big.Rat).Add(value, source.offset)
+	base.Mul(base, source.factor)
+	result := base.Quo(base, target.factor)
+	return result.Sub(result, target.offset), nil
+}
+
+// Convert converts a number between units of mass, length, temperature or volume
+type Convert struct {
+	Config
+}
+
+// Transform returns the input expressed in the target unit, rounded half-up to the given decimal places
+// when they are given
+func (t *Convert) Transform() (Results, error) {
+	if len(t.Args) < 3 || len(t.Args) > 4 {
+		return nil, fmt.Errorf("convert requires three or four arguments")
+	}
+	n, err := t.number(0)
+	if err != nil {
+		return nil, err
+	}
+	converted, err := convertUnit(n, t.Text(1), t.Text(2))
+	if err != nil {
+		return nil, err
+	}
+	if len(t.Args) == 4 {
+		digits, err := t.digits(3, 0)
+		if err != nil {
+			return nil, err
+		}
+		if converted, err = round(converted, digits, HalfUp); err != nil {
+			return nil, err
+		}
+	}
+	return Results{decimal(converted)}, nil
+}
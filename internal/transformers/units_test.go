@@ -0,0 +1,63 @@
+package transformers_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/codeis4fun/data-treatment-interpreter/internal/transformers"
+)
+
+func TestConvert(t *testing.T) {
+	input := []byte(`{"weight":154,"height":"69","fever":98.6,"freezing":0,"milk":1,"distance":26.2}`)
+	tests := []struct {
+		name     string
+		config   transformers.Config
+		expected json.Number
+	}{
+		{name: "pounds to kilograms", config: jsonConfig(input, "weight", "'lb'", "'kg'"), expected: "69.85322498"},
+		{name: "inches to centimetres", config: jsonConfig(input, "height", "'in'", "'cm'"), expected: "175.26"},
+		{name: "fahrenheit to celsius", config: jsonConfig(input, "fever", "'F'", "'C'", "'1'"), expected: "37"},
+		{name: "celsius to kelvin", config: jsonConfig(input, "freezing", "'c'", "'k'"), expected: "273.15"},
+		{name: "celsius to fahrenheit", config: jsonConfig(input, "freezing", "'c'", "'f'"), expected: "32"},
+		{name: "gallons to litres", config: jsonConfig(input, "milk", "'gal'", "'l'"), expected: "3.785411784"},
+		{name: "miles to kilometres", config: jsonConfig(input, "distance", "'mi'", "'km'", "'3'"), expected: "42.165"},
+		{name: "kilograms to stones", config: jsonConfig(input, "weight", "'kg'", "'st'"), expected: "24.2508488403"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results, err := (&transformers.Convert{Config: tt.config}).Transform()
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if results[0] != tt.expected {
+				t.Errorf("Expected %s, got %v", tt.expected, results[0])
+			}
+		})
+	}
+}
+
+func TestConvertWithInvalidUnits(t *testing.T) {
+	input := []byte(`{"weight":154}`)
+
+	tests := []struct {
+		name     string
+		args     []string
+		expected string
+	}{
+		{name: "unknown unit", args: []string{"weight", "'lb'", "'slug'"}, expected: "unknown unit 'slug'"},
+		{name: "different quantities", args: []string{"weight", "'lb'", "'l'"}, expected: "cannot convert lb (mass) to l (volume)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := (&transformers.Convert{Config: transformers.Config{Args: tt.args, Json: input}}).Transform()
+			if err == nil {
+				t.Fatalf("Expected error, but got nil")
+			}
+			if err.Error() != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, err)
+			}
+		})
+	}
+}